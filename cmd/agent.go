@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/shiquda/lai/internal/agent"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run many monitors in a single process",
+	Long: `The agent runs every declared monitor as part of one long-running process
+instead of one daemon per monitor. The monitors share the AI client, the
+notifiers with their rate limits, dedup and outbox, and the Telegram bot.
+
+The agent reads the same monitors file as 'lai up' and is controlled through
+a local socket (~/.lai/agent.sock) by the other agent subcommands.`,
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the agent with the declared monitors",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("file")
+		background, _ := cmd.Flags().GetBool("daemon")
+
+		// Resolve the monitors file here so a background agent reads the same one
+		configPath, _, err := loadDeclaredMonitors(path)
+		if err != nil {
+			logger.Fatalf("Failed to load monitors: %v", err)
+		}
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		if background && os.Getenv("LAI_DAEMON_MODE") != "1" {
+			if err := startAgentProcess(manager, configPath); err != nil {
+				logger.Fatalf("Failed to start agent: %v", err)
+			}
+			return
+		}
+
+		if err := runAgent(manager, configPath); err != nil {
+			logger.Fatalf("Agent failed: %v", err)
+		}
+	},
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the monitors running in the agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var states []agent.MonitorState
+		if err := callAgent(control.Request{Command: control.CommandStatus}, &states); err != nil {
+			logger.Fatalf("%v", err)
+		}
+		printAgentStatus(states)
+	},
+}
+
+var agentReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Apply changes to the monitors file to the running agent",
+	Long:  "Re-read the monitors file: start new monitors, restart changed or stopped ones and stop monitors no longer declared.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var result agent.ReloadResult
+		if err := callAgent(control.Request{Command: control.CommandReload}, &result); err != nil {
+			logger.Fatalf("Reload failed: %v", err)
+		}
+		printAgentStatus(result.Monitors)
+		if result.Error != "" {
+			logger.Fatalf("Some monitors could not be started: %s", result.Error)
+		}
+		logger.UserSuccess("Agent reloaded")
+	},
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop [monitor]",
+	Short: "Stop the agent, or one of its monitors",
+	Long:  "Stop the agent and all its monitors. With a monitor name, only that monitor is stopped until the next reload.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			if err := callAgent(control.Request{Command: control.CommandStop, Monitor: args[0]}, nil); err != nil {
+				logger.Fatalf("Failed to stop %s: %v", args[0], err)
+			}
+			logger.UserSuccessf("Monitor %s stopped\n", args[0])
+			return
+		}
+
+		if err := callAgent(control.Request{Command: control.CommandShutdown}, nil); err != nil {
+			logger.Fatalf("Failed to stop agent: %v", err)
+		}
+		logger.UserSuccess("Agent is shutting down")
+	},
+}
+
+func init() {
+	agentStartCmd.Flags().StringP("file", "f", "", "Monitors file (default ./lai.yaml, then ~/.lai/config.yaml)")
+	agentStartCmd.Flags().BoolP("daemon", "d", false, "Run the agent in the background")
+	agentCmd.AddCommand(agentStartCmd, agentStatusCmd, agentReloadCmd, agentStopCmd)
+	rootCmd.AddCommand(agentCmd)
+}
+
+// runAgent runs the agent in the foreground until it is interrupted or asked
+// to shut down through its control socket
+func runAgent(manager *daemon.Manager, configPath string) error {
+	load := func() ([]agent.Monitor, error) {
+		_, monitors, err := loadDeclaredMonitors(configPath)
+		if err != nil {
+			return nil, err
+		}
+		declared := desiredMonitors(monitors, configPath)
+		hosted := make([]agent.Monitor, 0, len(declared))
+		for _, monitor := range declared {
+			hosted = append(hosted, agent.Monitor{Name: monitor.Name, Spec: monitor.Spec})
+		}
+		return hosted, nil
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	useOutbox()
+	a := agent.New(agent.BuildConfig)
+	server, err := control.Listen(manager.AgentSocketPath(), a.Handler(load, cancel))
+	if err != nil {
+		return fmt.Errorf("%w (is another agent running?)", err)
+	}
+	go server.Serve()
+
+	if err := daemon.CreatePidFile(manager.AgentPidPath()); err != nil {
+		logger.Warnf("Failed to create PID file: %v", err)
+	}
+	defer daemon.RemovePidFile(manager.AgentPidPath())
+
+	monitors, err := load()
+	if err != nil {
+		server.Close()
+		return err
+	}
+	if err := a.Apply(monitors); err != nil {
+		logger.Errorf("Some monitors could not be started: %v", err)
+	}
+	logger.UserInfof("Agent running %d of %d monitor(s) from %s (control socket %s)\n", len(a.Status()), len(monitors), configPath, server.Path())
+
+	<-ctx.Done()
+	logger.UserInfo("Agent shutting down...")
+	server.Close()
+	a.Shutdown()
+	logger.UserInfo("Agent stopped")
+	return nil
+}
+
+// startAgentProcess starts the agent in the background, logging to
+// ~/.lai/agent.log
+func startAgentProcess(manager *daemon.Manager, configPath string) error {
+	logPath := manager.AgentLogPath()
+	logFileHandle, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFileHandle.Close()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	args := []string{execPath, "agent", "start", "--file", configPath}
+
+	p := platform.New()
+	process, err := p.Process.StartDaemonProcess(execPath, args, logFileHandle, append(os.Environ(), "LAI_DAEMON_MODE=1"))
+	if err != nil {
+		return fmt.Errorf("failed to start agent process: %w", err)
+	}
+
+	logger.UserSuccessf("Started agent (PID: %d)\n", process.Pid)
+	logger.UserInfof("Log file: %s\n", logPath)
+	logger.UserInfo("Use 'lai agent status' to see its monitors")
+	return nil
+}
+
+// callAgent sends a request to the agent's control socket
+func callAgent(req control.Request, result interface{}) error {
+	manager, err := daemon.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create daemon manager: %w", err)
+	}
+
+	if err := control.Call(manager.AgentSocketPath(), req, result); err != nil {
+		if errors.Is(err, control.ErrUnavailable) {
+			return fmt.Errorf("agent is not running (start it with 'lai agent start')")
+		}
+		return err
+	}
+	return nil
+}
+
+// printAgentStatus prints the monitors of the agent as a table
+func printAgentStatus(states []agent.MonitorState) {
+	if len(states) == 0 {
+		logger.UserInfo("No monitors running in the agent")
+		return
+	}
+
+	logger.UserInfof("%-20s %-6s %-8s %-8s %-8s %-10s %s\n", "MONITOR", "TYPE", "STATE", "LINES", "ALERTS", "UPTIME", "SOURCE")
+	logger.UserInfof("%-20s %-6s %-8s %-8s %-8s %-10s %s\n", "-------", "----", "-----", "-----", "------", "------", "------")
+	for _, state := range states {
+		uptime := "-"
+		if state.State == agent.StateRunning {
+			uptime = time.Since(state.StartedAt).Round(time.Second).String()
+		}
+		status := state.State
+		if state.Paused && state.State == agent.StateRunning {
+			status = "paused"
+		}
+		logger.UserInfof("%-20s %-6s %-8s %-8d %-8d %-10s %s\n",
+			state.Name, state.Type, status, state.Counters.Lines, state.Counters.Alerts, uptime, state.Source)
+		if state.Error != "" {
+			logger.UserInfof("%-20s error: %s\n", "", state.Error)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
 	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/shiquda/lai/internal/platform"
@@ -24,6 +27,85 @@ type CommandOptions struct {
 	FinalSummaryOnly *bool
 	EnabledNotifiers []string
 	DaemonMode       bool
+
+	ExpectActivityWithin *time.Duration
+	RestartPolicy        string
+	RestartDelay         *time.Duration
+	Include              []string
+	Exclude              []string
+	SummarizeTemplate    string
+
+	// MonitorType and SourceArgs record the subcommand and its positional
+	// arguments so a daemon can be resumed with the same source
+	MonitorType string
+	SourceArgs  []string
+}
+
+// MonitorOverrides converts the parsed options into per-monitor config overrides
+func (o *CommandOptions) MonitorOverrides() collector.MonitorOverrides {
+	return collector.MonitorOverrides{
+		Name:                 o.ProcessName,
+		LineThreshold:        o.LineThreshold,
+		CheckInterval:        o.CheckInterval,
+		ChatID:               o.ChatID,
+		WorkingDir:           o.WorkingDir,
+		FinalSummary:         o.FinalSummary,
+		ErrorOnlyMode:        o.ErrorOnlyMode,
+		FinalSummaryOnly:     o.FinalSummaryOnly,
+		ExpectActivityWithin: o.ExpectActivityWithin,
+		Notifiers:            o.EnabledNotifiers,
+		Restart:              o.RestartPolicy,
+		RestartDelay:         o.RestartDelay,
+		Include:              o.Include,
+		Exclude:              o.Exclude,
+		SummarizeTemplate:    o.SummarizeTemplate,
+	}
+}
+
+// LaunchSpec returns the launch spec saved with a daemon process. Relative
+// paths are made absolute, and exec monitors keep the directory they were
+// started from, so resuming works from anywhere.
+func (o *CommandOptions) LaunchSpec(source collector.MonitorSource) *daemon.LaunchSpec {
+	spec := &daemon.LaunchSpec{
+		Type:   o.MonitorType,
+		Source: strings.TrimPrefix(source.GetIdentifier(), "COMMAND_SOURCE:"),
+		Args:   append([]string(nil), o.SourceArgs...),
+		Options: daemon.LaunchOptions{
+			Name:                 o.ProcessName,
+			LineThreshold:        o.LineThreshold,
+			CheckInterval:        o.CheckInterval,
+			ChatID:               o.ChatID,
+			WorkingDir:           o.WorkingDir,
+			FinalSummary:         o.FinalSummary,
+			ErrorOnlyMode:        o.ErrorOnlyMode,
+			FinalSummaryOnly:     o.FinalSummaryOnly,
+			Notifiers:            o.EnabledNotifiers,
+			ExpectActivityWithin: o.ExpectActivityWithin,
+			Restart:              o.RestartPolicy,
+			RestartDelay:         o.RestartDelay,
+			Include:              o.Include,
+			Exclude:              o.Exclude,
+			Template:             o.SummarizeTemplate,
+		},
+	}
+
+	switch spec.Type {
+	case daemon.MonitorTypeFile:
+		if len(spec.Args) == 1 {
+			if path, err := filepath.Abs(spec.Args[0]); err == nil {
+				spec.Args[0] = path
+				spec.Source = path
+			}
+		}
+	case daemon.MonitorTypeExec:
+		if spec.Options.WorkingDir == "" {
+			spec.Options.WorkingDir, _ = os.Getwd()
+		} else if dir, err := filepath.Abs(spec.Options.WorkingDir); err == nil {
+			spec.Options.WorkingDir = dir
+		}
+	}
+
+	return spec
 }
 
 // CommandRunner defines command execution interface
@@ -55,6 +137,15 @@ func (r *BaseCommandRunner) ParseCommonArgs(cmd *cobra.Command) (*CommandOptions
 		}
 	}
 
+	silenceStr, _ := cmd.Flags().GetString("expect-activity-within")
+	if silenceStr != "" {
+		duration, err := time.ParseDuration(silenceStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expect-activity-within format: %v", err)
+		}
+		options.ExpectActivityWithin = &duration
+	}
+
 	chatID, _ := cmd.Flags().GetString("chat-id")
 	if cmd.Flags().Changed("chat-id") {
 		options.ChatID = &chatID
@@ -94,35 +185,65 @@ func (r *BaseCommandRunner) ParseCommonArgs(cmd *cobra.Command) (*CommandOptions
 	options.EnabledNotifiers, _ = cmd.Flags().GetStringSlice("notifiers")
 	options.DaemonMode, _ = cmd.Flags().GetBool("daemon")
 
+	options.Include, _ = cmd.Flags().GetStringArray("include")
+	options.Exclude, _ = cmd.Flags().GetStringArray("exclude")
+	options.SummarizeTemplate, _ = cmd.Flags().GetString("template")
+
+	options.RestartPolicy, _ = cmd.Flags().GetString("restart")
+	if err := daemon.ValidateRestartPolicy(options.RestartPolicy); err != nil {
+		return nil, err
+	}
+
+	// Only lai exec has --restart-delay
+	if restartDelay, err := cmd.Flags().GetString("restart-delay"); err == nil && restartDelay != "" {
+		duration, err := time.ParseDuration(restartDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid restart-delay format: %v", err)
+		}
+		if duration < collector.MinRestartDelay {
+			return nil, fmt.Errorf("restart-delay must be at least %s", collector.MinRestartDelay)
+		}
+		options.RestartDelay = &duration
+	}
+
 	return options, nil
 }
 
 // Run executes monitoring
 func (r *BaseCommandRunner) Run(options *CommandOptions, source collector.MonitorSource) error {
-	cfg, err := collector.BuildMonitorConfig(
-		source,
-		options.LineThreshold,
-		options.CheckInterval,
-		options.ChatID,
-		options.WorkingDir,
-		options.FinalSummary,
-		options.ErrorOnlyMode,
-		options.FinalSummaryOnly,
-	)
+	monitor, err := r.newMonitor(options, source)
 	if err != nil {
-		return fmt.Errorf("failed to build config: %w", err)
+		return err
 	}
+	return monitor.Start()
+}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+// newMonitor builds and validates the configuration and creates the monitor
+func (r *BaseCommandRunner) newMonitor(options *CommandOptions, source collector.MonitorSource) (*collector.UnifiedMonitor, error) {
+	cfg, err := r.buildConfig(options, source)
+	if err != nil {
+		return nil, err
 	}
 
+	useOutbox()
 	monitor, err := collector.NewUnifiedMonitor(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create unified monitor: %w", err)
+		return nil, fmt.Errorf("failed to create unified monitor: %w", err)
 	}
+	return monitor, nil
+}
 
-	return monitor.Start()
+// buildConfig builds and validates the monitor configuration
+func (r *BaseCommandRunner) buildConfig(options *CommandOptions, source collector.MonitorSource) (*collector.MonitorConfig, error) {
+	cfg, err := collector.BuildMonitorConfig(source, options.MonitorOverrides())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return cfg, nil
 }
 
 // RunDaemon runs daemon process
@@ -138,13 +259,15 @@ func (r *BaseCommandRunner) RunDaemon(options *CommandOptions, source collector.
 	if os.Getenv("LAI_DAEMON_MODE") != "1" {
 		processID := r.generateProcessID(manager, options.ProcessName, sourceIdentifier)
 		daemonLogPath := manager.GetProcessLogPath(processID)
-		return r.startDaemonProcess(manager, processID, sourceIdentifier, daemonLogPath)
+		return r.startDaemonProcess(manager, processID, options.LaunchSpec(source), sourceIdentifier, daemonLogPath)
 	}
 
-	// Child process - run as daemon
-	// In child process, processID is passed via environment or command line
-	// For now, we'll derive it from the process name since we're in daemon mode
-	processID := r.generateProcessIDForChild(manager, options.ProcessName, sourceIdentifier)
+	// Child process - run as daemon. The parent passes the process ID through
+	// the environment; derive it for daemons started by older versions.
+	processID := os.Getenv("LAI_PROCESS_ID")
+	if processID == "" {
+		processID = r.generateProcessIDForChild(manager, options.ProcessName, sourceIdentifier)
+	}
 	return r.runAsDaemon(manager, processID, options, source)
 }
 
@@ -169,7 +292,7 @@ func (r *BaseCommandRunner) generateProcessIDForChild(manager *daemon.Manager, p
 }
 
 // startDaemonProcess starts daemon process
-func (r *BaseCommandRunner) startDaemonProcess(manager *daemon.Manager, processID, sourceIdentifier, daemonLogPath string) error {
+func (r *BaseCommandRunner) startDaemonProcess(manager *daemon.Manager, processID string, launch *daemon.LaunchSpec, sourceIdentifier, daemonLogPath string) error {
 	os.Setenv("LAI_DAEMON_MODE", "1")
 
 	logFileHandle, err := os.OpenFile(daemonLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
@@ -187,7 +310,7 @@ func (r *BaseCommandRunner) startDaemonProcess(manager *daemon.Manager, processI
 	args := append([]string{cmd}, os.Args[1:]...)
 
 	p := platform.New()
-	process, err := p.Process.StartDaemonProcess(cmd, args, logFileHandle, append(os.Environ(), "LAI_DAEMON_MODE=1"))
+	process, err := p.Process.StartDaemonProcess(cmd, args, logFileHandle, append(os.Environ(), "LAI_DAEMON_MODE=1", "LAI_PROCESS_ID="+processID))
 	if err != nil {
 		return fmt.Errorf("failed to start daemon process: %w", err)
 	}
@@ -199,6 +322,7 @@ func (r *BaseCommandRunner) startDaemonProcess(manager *daemon.Manager, processI
 		LogFile:   sourceIdentifier,
 		StartTime: time.Now(),
 		Status:    "running",
+		Launch:    launch,
 	}
 
 	if err := manager.SaveProcessInfo(processInfo); err != nil {
@@ -215,30 +339,68 @@ func (r *BaseCommandRunner) startDaemonProcess(manager *daemon.Manager, processI
 }
 
 // runAsDaemon runs as daemon process
-func (r *BaseCommandRunner) runAsDaemon(manager *daemon.Manager, processID string, options *CommandOptions, source collector.MonitorSource) error {
-	// Set cleanup function
+func (r *BaseCommandRunner) runAsDaemon(manager *daemon.Manager, processID string, options *CommandOptions, source collector.MonitorSource) (err error) {
+	// Set cleanup function, recording how the monitor exited for the supervisor
 	defer func() {
-		if info, err := manager.LoadProcessInfo(processID); err == nil {
+		// A panic is a failure too; record it before passing it on
+		recovered := recover()
+
+		// Skip the record once a replacement has taken it over, e.g. after lai reload
+		if info, loadErr := manager.LoadProcessInfo(processID); loadErr == nil && info.PID == os.Getpid() {
 			info.Status = "stopped"
+			info.Exit = daemon.ExitClean
+			if err != nil || recovered != nil {
+				info.Exit = daemon.ExitFailed
+			}
 			if saveErr := manager.SaveProcessInfo(info); saveErr != nil {
 				logger.Errorf("Failed to update process status: %v", saveErr)
 			}
 		}
+
+		if recovered != nil {
+			panic(recovered)
+		}
 	}()
 
-	// Update child process information
+	// Update child process information, keeping the supervisor's restart count
+	// and the launch spec
 	processInfo := &daemon.ProcessInfo{
 		ID:        processID,
 		PID:       os.Getpid(),
 		LogFile:   source.GetIdentifier(),
 		StartTime: time.Now(),
 		Status:    "running",
+		Launch:    options.LaunchSpec(source),
+	}
+	if existing, loadErr := manager.LoadProcessInfo(processID); loadErr == nil {
+		processInfo.Restarts = existing.Restarts
+		// Keep the spec of whoever launched this process, e.g. lai up
+		if existing.Launch != nil {
+			processInfo.Launch = existing.Launch
+		}
 	}
 	if err := manager.SaveProcessInfo(processInfo); err != nil {
 		logger.Errorf("Failed to save process info in child: %v", err)
 	}
 
-	return r.Run(options, source)
+	monitor, err := r.newMonitor(options, source)
+	if err != nil {
+		return err
+	}
+
+	// Serve the control API; lai stop falls back to signals without it
+	reload := func() (*collector.MonitorConfig, error) { return r.buildConfig(options, source) }
+	server, listenErr := control.Listen(manager.ControlSocketPath(processID), func(req control.Request) (interface{}, error) {
+		return monitor.HandleControl(req, reload)
+	})
+	if listenErr != nil {
+		logger.Warnf("Control API disabled: %v", listenErr)
+	} else {
+		go server.Serve()
+		defer server.Close()
+	}
+
+	return monitor.Start()
 }
 
 // AddCommonFlags adds common parameters to command
@@ -253,5 +415,10 @@ func AddCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool("no-final-summary", false, "Disable final summary on program exit")
 	cmd.Flags().BoolP("error-only", "E", false, "Only send notifications for errors and exceptions")
 	cmd.Flags().BoolP("final-summary-only", "F", false, "Only send notifications for final summary")
-	cmd.Flags().StringSlice("notifiers", []string{}, "Enable specific notifiers (comma-separated: telegram,email)")
+	cmd.Flags().StringSlice("notifiers", []string{}, "Send only to these providers (comma-separated names from notifications.providers, e.g. telegram,email)")
+	cmd.Flags().String("expect-activity-within", "", "Alert if the source produces no output within this duration (e.g., 10m) (overrides global config)")
+	cmd.Flags().StringArray("include", nil, "Only summarize lines matching this regular expression (repeatable)")
+	cmd.Flags().StringArray("exclude", nil, "Drop lines matching this regular expression before summarizing (repeatable)")
+	cmd.Flags().String("template", "", "Summarize prompt template for this monitor (overrides prompt_templates.summarize_template)")
+	cmd.Flags().String("restart", "", "Restart policy: no, always or on-failure (lai exec relaunches the command; daemons are also restarted by 'lai supervisor')")
 }
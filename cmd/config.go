@@ -339,6 +339,18 @@ func setFieldByPath(obj interface{}, path, value string) error {
 							return fmt.Errorf("invalid int64 value: %s", value)
 						}
 					}
+				case reflect.Slice:
+					// Handle string lists given as comma-separated values
+					if field.Type().Elem().Kind() != reflect.String {
+						return fmt.Errorf("unsupported slice type: %s", field.Type())
+					}
+					var items []string
+					for _, item := range strings.Split(value, ",") {
+						if item = strings.TrimSpace(item); item != "" {
+							items = append(items, item)
+						}
+					}
+					field.Set(reflect.ValueOf(items))
 				default:
 					return fmt.Errorf("unsupported field type: %s", field.Kind())
 				}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// monitorCommands are the control API commands a single monitor accepts
+var monitorCommands = []string{
+	control.CommandStatus,
+	control.CommandCounters,
+	control.CommandPause,
+	control.CommandResume,
+	control.CommandFlush,
+	control.CommandReload,
+	control.CommandLastSummary,
+	control.CommandStop,
+}
+
+var controlCmd = &cobra.Command{
+	Use:   "control <process-id> <command>",
+	Short: "Send a command to a running monitor's control API",
+	Long: `Send a command to the control socket of a daemon, or of a monitor running
+in the agent, and print the JSON result.
+
+Commands:
+  status        State and counters of the monitor
+  counters      Lines, batches, summaries, alerts and notifications so far
+  pause         Keep collecting lines but stop summarizing them (see lai pause)
+  resume        Summarize new lines again, sending the maintenance digest
+  flush         Summarize the lines collected since the last summary now
+  reload        Re-read notifiers, AI settings, language and prompt templates
+  last-summary  The latest summary or alert
+  stop          Stop the monitor`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return monitorCommands, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		processID, command := args[0], args[1]
+		if !isMonitorCommand(command) {
+			logger.Fatalf("Unknown command %q (valid: %s)", command, strings.Join(monitorCommands, ", "))
+		}
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		var result json.RawMessage
+		if err := callMonitor(manager, processID, control.Request{Command: command}, &result); err != nil {
+			logger.Fatalf("%v", err)
+		}
+		if len(result) == 0 {
+			logger.UserSuccessf("%s: %s done\n", processID, command)
+			return
+		}
+
+		formatted, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			logger.Fatalf("Failed to format result: %v", err)
+		}
+		logger.UserInfo(string(formatted))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(controlCmd)
+}
+
+// isMonitorCommand reports whether a monitor accepts a control command
+func isMonitorCommand(command string) bool {
+	for _, known := range monitorCommands {
+		if command == known {
+			return true
+		}
+	}
+	return false
+}
+
+// callMonitor sends a request to the control socket of a daemon. Without a
+// daemon of that ID the request goes to the agent monitor of that name.
+func callMonitor(manager *daemon.Manager, processID string, req control.Request, result interface{}) error {
+	if manager.ProcessExists(processID) {
+		err := control.Call(manager.ControlSocketPath(processID), req, result)
+		if errors.Is(err, control.ErrUnavailable) {
+			return fmt.Errorf("process %s does not serve the control API (is it running?)", processID)
+		}
+		return err
+	}
+
+	req.Monitor = processID
+	err := control.Call(manager.AgentSocketPath(), req, result)
+	if errors.Is(err, control.ErrUnavailable) {
+		return fmt.Errorf("process not found: %s", processID)
+	}
+	return err
+}
+
+// daemonSnapshot asks a running daemon for its state, returning nil when it
+// does not answer, e.g. because it was started by an older version
+func daemonSnapshot(manager *daemon.Manager, processID string) *collector.MonitorSnapshot {
+	var snapshot collector.MonitorSnapshot
+	if err := control.Call(manager.ControlSocketPath(processID), control.Request{Command: control.CommandStatus}, &snapshot); err != nil {
+		return nil
+	}
+	return &snapshot
+}
+
+// formatCounters describes the counters of a monitor, e.g.
+// "lines=120 summaries=3 alerts=1 notified=4"
+func formatCounters(counters collector.Counters) string {
+	text := fmt.Sprintf("lines=%d summaries=%d alerts=%d notified=%d",
+		counters.Lines, counters.Summaries, counters.Alerts, counters.Notifications)
+	if counters.FailedNotifications > 0 {
+		text += fmt.Sprintf(" failed=%d", counters.FailedNotifications)
+	}
+	if counters.PausedLines > 0 {
+		text += fmt.Sprintf(" paused=%d", counters.PausedLines)
+	}
+	return text
+}
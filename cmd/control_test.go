@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+func TestCallMonitorRoutesToDaemonOrAgent(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := daemon.NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	if err := callMonitor(manager, "api", control.Request{Command: control.CommandStatus}, nil); err == nil || !strings.Contains(err.Error(), "process not found") {
+		t.Errorf("Expected process not found without daemon or agent, got %v", err)
+	}
+
+	if err := manager.SaveProcessInfo(&daemon.ProcessInfo{ID: "api", PID: 1, Status: "running"}); err != nil {
+		t.Fatalf("Failed to save process: %v", err)
+	}
+	if err := callMonitor(manager, "api", control.Request{Command: control.CommandStatus}, nil); err == nil || !strings.Contains(err.Error(), "does not serve the control API") {
+		t.Errorf("Expected an unavailable control API, got %v", err)
+	}
+
+	// A monitor without a daemon record is looked up in the agent
+	var got control.Request
+	server, err := control.Listen(manager.AgentSocketPath(), func(req control.Request) (interface{}, error) {
+		got = req
+		return collector.Counters{Lines: 7}, nil
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+	defer server.Close()
+
+	var counters collector.Counters
+	if err := callMonitor(manager, "worker", control.Request{Command: control.CommandCounters}, &counters); err != nil {
+		t.Fatalf("callMonitor failed: %v", err)
+	}
+	if got.Monitor != "worker" || got.Command != control.CommandCounters || counters.Lines != 7 {
+		t.Errorf("Unexpected agent request %+v or result %+v", got, counters)
+	}
+}
+
+func TestFormatCounters(t *testing.T) {
+	got := formatCounters(collector.Counters{Lines: 120, Summaries: 3, Alerts: 1, Notifications: 4})
+	if got != "lines=120 summaries=3 alerts=1 notified=4" {
+		t.Errorf("Unexpected counters %q", got)
+	}
+
+	got = formatCounters(collector.Counters{FailedNotifications: 2, PausedLines: 9})
+	if !strings.HasSuffix(got, "failed=2 paused=9") {
+		t.Errorf("Expected failures and paused lines, got %q", got)
+	}
+}
+
+func TestIsMonitorCommand(t *testing.T) {
+	if !isMonitorCommand(control.CommandLastSummary) || isMonitorCommand(control.CommandShutdown) {
+		t.Error("Expected monitor commands only")
+	}
+}
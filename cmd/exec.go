@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+
 	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +39,16 @@ func NewExecCommand() *cobra.Command {
 				}
 			} else {
 				if err := runner.Run(options, source); err != nil {
+					// Pass the child's exit code through so lai exec can be used in scripts
+					var exitErr *collector.ExitError
+					if errors.As(err, &exitErr) {
+						logger.UserErrorf("Command %s", exitErr.Info.Status())
+						os.Exit(exitErr.Info.ExitCode)
+					}
+					if errors.Is(err, exec.ErrNotFound) {
+						logger.UserErrorf("Command monitor failed: %v", err)
+						os.Exit(127)
+					}
 					logger.Fatalf("Command monitor failed: %v", err)
 				}
 			}
@@ -42,6 +57,7 @@ func NewExecCommand() *cobra.Command {
 
 	// Add common parameters
 	AddCommonFlags(cmd)
+	cmd.Flags().String("restart-delay", "", "Pause before the command is relaunched under --restart (default 5s, at least 1s)")
 
 	return cmd
 }
@@ -53,6 +69,8 @@ func (r *ExecCommandRunner) ParseArgs(cmd *cobra.Command, args []string) (*Comma
 	if err != nil {
 		return nil, nil, err
 	}
+	options.MonitorType = daemon.MonitorTypeExec
+	options.SourceArgs = args
 
 	// Parse command
 	var commandStr string
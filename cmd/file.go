@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -51,6 +52,11 @@ func (r *FileCommandRunner) ParseArgs(cmd *cobra.Command, args []string) (*Comma
 	if err != nil {
 		return nil, nil, err
 	}
+	options.MonitorType = daemon.MonitorTypeFile
+	options.SourceArgs = args
+	if options.RestartPolicy != "" && options.RestartPolicy != daemon.RestartNo && !options.DaemonMode {
+		logger.Warnf("--restart only applies to file monitors run as daemons (-d); it is ignored in the foreground")
+	}
 
 	// Create file monitoring source
 	logFile := args[0]
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/shiquda/lai/internal/collector"
 	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/spf13/cobra"
@@ -32,13 +36,51 @@ var listCmd = &cobra.Command{
 		logger.UserInfof("%-20s %-8s %-10s %-20s %s\n", "----------", "---", "------", "----------", "--------")
 
 		for _, proc := range processes {
+			// Running daemons report their state through the control API
+			status := proc.Status
+			var snapshot *collector.MonitorSnapshot
+			if status == "running" {
+				snapshot = daemonSnapshot(manager, proc.ID)
+				if snapshot != nil && snapshot.Paused {
+					status = "paused"
+				}
+			}
+
 			startTime := proc.StartTime.Format("2006-01-02 15:04:05")
 			logger.UserInfof("%-20s %-8d %-10s %-20s %s\n",
-				proc.ID, proc.PID, proc.Status, startTime, proc.LogFile)
+				proc.ID, proc.PID, status, startTime, proc.LogFile)
+			if proc.Launch != nil {
+				logger.UserInfof("%-20s %s\n", "", formatLaunchSettings(proc.Launch))
+			}
+			if snapshot != nil {
+				logger.UserInfof("%-20s %s\n", "", formatCounters(snapshot.Counters))
+			}
 		}
 	},
 }
 
+// formatLaunchSettings describes the monitor type and options of a daemon
+// process, e.g. "exec: line-threshold=10 interval=30s"
+func formatLaunchSettings(spec *daemon.LaunchSpec) string {
+	settings := launchSettings(spec.Options)
+	if len(settings) == 0 {
+		return spec.Type + ": default settings"
+	}
+
+	parts := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		value := strings.Join(strings.Fields(setting.value), " ")
+		if len(value) > 40 {
+			value = value[:37] + "..."
+		}
+		if strings.ContainsAny(value, " \"") {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, setting.flag+"="+value)
+	}
+	return spec.Type + ": " + strings.Join(parts, " ")
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 }
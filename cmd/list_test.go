@@ -207,3 +207,21 @@ func formatListLine(id string, pid int, status, startTime, logFile string) strin
 	return fmt.Sprintf("%-20s %-8d %-10s %-20s %s",
 		id, pid, status, startTime, logFile)
 }
+
+func TestFormatLaunchSettings(t *testing.T) {
+	threshold := 10
+	interval := 30 * time.Second
+	spec := &daemon.LaunchSpec{
+		Type:    daemon.MonitorTypeExec,
+		Options: daemon.LaunchOptions{LineThreshold: &threshold, CheckInterval: &interval, Notifiers: []string{"telegram"}},
+	}
+
+	expected := "exec: line-threshold=10 interval=30s notifiers=telegram"
+	if got := formatLaunchSettings(spec); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := formatLaunchSettings(&daemon.LaunchSpec{Type: daemon.MonitorTypeFile}); got != "file: default settings" {
+		t.Errorf("Unexpected settings for defaults: %q", got)
+	}
+}
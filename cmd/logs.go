@@ -3,7 +3,10 @@ package cmd
 import (
 	"bufio"
 	"os"
+	"time"
 
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
 	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/spf13/cobra"
@@ -12,12 +15,15 @@ import (
 var logsCmd = &cobra.Command{
 	Use:   "logs <process-id>",
 	Short: "Show logs for a daemon process",
-	Long:  "Show logs for the specified daemon process",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show logs for the specified daemon process. With --summary, show the
+latest summary or alert of the running daemon, or of a monitor running in the
+agent, instead.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		processID := args[0]
 		follow, _ := cmd.Flags().GetBool("follow")
 		lines, _ := cmd.Flags().GetInt("lines")
+		summary, _ := cmd.Flags().GetBool("summary")
 
 		manager, err := daemon.NewManager()
 		if err != nil {
@@ -25,6 +31,13 @@ var logsCmd = &cobra.Command{
 			return
 		}
 
+		if summary {
+			if err := showLastSummary(manager, processID); err != nil {
+				logger.Errorf("%v", err)
+			}
+			return
+		}
+
 		// Check if process exists
 		_, err = manager.LoadProcessInfo(processID)
 		if err != nil {
@@ -56,6 +69,23 @@ func init() {
 	rootCmd.AddCommand(logsCmd)
 	logsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
 	logsCmd.Flags().IntP("lines", "n", 50, "Number of lines to show from the end")
+	logsCmd.Flags().Bool("summary", false, "Show the latest summary or alert instead of the log")
+}
+
+// showLastSummary prints the latest summary or alert of a running monitor
+func showLastSummary(manager *daemon.Manager, processID string) error {
+	var record collector.SummaryRecord
+	if err := callMonitor(manager, processID, control.Request{Command: control.CommandLastSummary}, &record); err != nil {
+		return err
+	}
+
+	logger.UserInfof("%s (%s, %d lines) at %s\n", record.Type, record.Severity, record.Lines, record.Time.Format(time.DateTime))
+	if !record.WindowStart.IsZero() {
+		logger.UserInfof("Window: %s - %s\n", record.WindowStart.Format(time.DateTime), record.WindowEnd.Format(time.DateTime))
+	}
+	logger.UserInfo("")
+	logger.UserInfo(record.Summary)
+	return nil
 }
 
 func showLastLines(filePath string, numLines int) error {
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+var outboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "Manage undelivered notifications",
+	Long: `Notifications that could not be delivered are stored in ~/.lai/outbox and retried
+automatically with backoff by running monitors. Use these commands to inspect the queue,
+retry it immediately or discard entries.`,
+}
+
+var outboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued notifications",
+	Run: func(cmd *cobra.Command, args []string) {
+		outbox, err := openOutbox()
+		if err != nil {
+			logger.Errorf("Failed to open outbox: %v", err)
+			return
+		}
+
+		if err := listOutbox(outbox); err != nil {
+			logger.Errorf("Failed to list outbox: %v", err)
+		}
+	},
+}
+
+var outboxRetryCmd = &cobra.Command{
+	Use:   "retry [entry-id...]",
+	Short: "Retry queued notifications now",
+	Long:  "Immediately retry delivery of all queued notifications, or only the given entries.",
+	Run: func(cmd *cobra.Command, args []string) {
+		outbox, err := openOutbox()
+		if err != nil {
+			logger.Errorf("Failed to open outbox: %v", err)
+			return
+		}
+
+		if err := retryOutbox(outbox, args); err != nil {
+			logger.Errorf("Failed to retry outbox: %v", err)
+		}
+	},
+}
+
+var outboxPurgeCmd = &cobra.Command{
+	Use:   "purge [entry-id...]",
+	Short: "Discard queued notifications",
+	Long:  "Remove the given entries from the outbox. Use --all to discard every queued notification.",
+	Run: func(cmd *cobra.Command, args []string) {
+		purgeAll, _ := cmd.Flags().GetBool("all")
+
+		outbox, err := openOutbox()
+		if err != nil {
+			logger.Errorf("Failed to open outbox: %v", err)
+			return
+		}
+
+		if err := purgeOutbox(outbox, args, purgeAll); err != nil {
+			logger.Errorf("Failed to purge outbox: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(outboxCmd)
+	outboxCmd.AddCommand(outboxListCmd)
+	outboxCmd.AddCommand(outboxRetryCmd)
+	outboxCmd.AddCommand(outboxPurgeCmd)
+	outboxPurgeCmd.Flags().BoolP("all", "a", false, "Discard all queued notifications")
+}
+
+// openOutbox opens the default outbox directory
+func openOutbox() (*notifier.Outbox, error) {
+	dir, err := notifier.DefaultOutboxDir()
+	if err != nil {
+		return nil, err
+	}
+	return notifier.NewOutbox(dir)
+}
+
+// useOutbox keeps the notifications the monitors of this process fail to
+// deliver in the default outbox, retried in the background
+func useOutbox() {
+	dir, err := notifier.DefaultOutboxDir()
+	if err == nil {
+		err = notifier.UseOutbox(dir)
+	}
+	if err != nil {
+		logger.Warnf("Outbox disabled: %v", err)
+	}
+}
+
+func listOutbox(outbox *notifier.Outbox) error {
+	entries, err := outbox.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		logger.UserInfo("Outbox is empty")
+		return nil
+	}
+
+	logger.UserInfof("%-30s %-12s %-8s %-20s %-30s %s\n", "ENTRY ID", "PROVIDER", "TRIES", "NEXT RETRY", "NOTIFICATION", "LAST ERROR")
+	logger.UserInfof("%-30s %-12s %-8s %-20s %-30s %s\n", "--------", "--------", "-----", "----------", "------------", "----------")
+
+	for _, entry := range entries {
+		logger.UserInfof("%-30s %-12s %-8d %-20s %-30s %s\n",
+			entry.ID,
+			entry.Provider,
+			entry.Attempts,
+			entry.NextAttempt.Local().Format("2006-01-02 15:04:05"),
+			truncateOutboxField(describeOutboxMessage(entry.Message), 30),
+			truncateOutboxField(entry.LastError, 60))
+	}
+
+	logger.UserInfof("\n%d notification(s) queued in %s\n", len(entries), outbox.Dir())
+	return nil
+}
+
+func retryOutbox(outbox *notifier.Outbox, ids []string) error {
+	entries, err := outbox.List()
+	if err != nil {
+		return err
+	}
+
+	// Only the providers referenced by the queued entries are needed; selecting
+	// them also enables providers a monitor used via --notifiers
+	providerSet := make(map[string]bool)
+	for _, entry := range entries {
+		if len(ids) == 0 || hasOutboxID(ids, entry.ID) {
+			providerSet[entry.Provider] = true
+		}
+	}
+	if len(providerSet) == 0 {
+		logger.UserInfo("Nothing to retry")
+		return nil
+	}
+
+	cfg, err := config.BuildRuntimeConfig("", nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var providers []string
+	for provider := range providerSet {
+		if _, exists := cfg.Notifications.Providers[provider]; exists {
+			providers = append(providers, provider)
+		} else {
+			logger.UserWarningf("Provider %s is no longer configured, its entries are kept", provider)
+		}
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("none of the queued providers are configured")
+	}
+
+	selected, err := notifier.SelectProviders(cfg.Notifications, providers)
+	if err != nil {
+		return err
+	}
+
+	notifyNotifier, err := notifier.NewNotifyNotifier(&selected)
+	if err != nil {
+		return fmt.Errorf("failed to create notifier: %w", err)
+	}
+	notifyNotifier.SetOutbox(outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sent, failed := notifyNotifier.RetryOutbox(ctx, true, ids)
+	if failed > 0 {
+		logger.UserWarningf("Delivered %d notification(s), %d still failing (see 'lai outbox list')", sent, failed)
+	} else {
+		logger.UserSuccessf("Delivered %d notification(s)", sent)
+	}
+	return nil
+}
+
+func purgeOutbox(outbox *notifier.Outbox, ids []string, purgeAll bool) error {
+	if purgeAll {
+		purged, err := outbox.Purge()
+		if err != nil {
+			return err
+		}
+		logger.UserSuccessf("Discarded %d queued notification(s)", purged)
+		return nil
+	}
+
+	if len(ids) == 0 {
+		logger.UserError("Please specify an entry ID or use --all flag")
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := outbox.Remove(id); err != nil {
+			logger.Errorf("Failed to discard %s: %v", id, err)
+			continue
+		}
+		logger.UserSuccessf("Discarded queued notification: %s", id)
+	}
+	return nil
+}
+
+// describeOutboxMessage returns a one-line description of a queued message
+func describeOutboxMessage(msg *notifier.Message) string {
+	if msg == nil {
+		return "-"
+	}
+	if msg.Source != "" {
+		return fmt.Sprintf("%s: %s", msg.Type, msg.Source)
+	}
+	return fmt.Sprintf("%s: %s", msg.Type, strings.Split(msg.Body, "\n")[0])
+}
+
+// truncateOutboxField shortens a table field to the given width
+func truncateOutboxField(value string, width int) string {
+	value = strings.ReplaceAll(value, "\n", " ")
+	if len([]rune(value)) <= width {
+		return value
+	}
+	return string([]rune(value)[:width-1]) + "…"
+}
+
+// hasOutboxID reports whether the entry ID was requested
+func hasOutboxID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
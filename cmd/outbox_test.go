@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shiquda/lai/internal/notifier"
+)
+
+func createTestOutbox(t *testing.T) *notifier.Outbox {
+	outbox, err := notifier.NewOutbox(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create outbox: %v", err)
+	}
+	return outbox
+}
+
+func TestPurgeOutboxByID(t *testing.T) {
+	outbox := createTestOutbox(t)
+
+	keep, err := outbox.Add("telegram", notifier.NewMessage(notifier.MessageTypeMessage, "", "keep", notifier.SeverityInfo), errors.New("timeout"))
+	if err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	drop, err := outbox.Add("telegram", notifier.NewMessage(notifier.MessageTypeMessage, "", "drop", notifier.SeverityInfo), errors.New("timeout"))
+	if err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+
+	if err := purgeOutbox(outbox, []string{drop.ID}, false); err != nil {
+		t.Fatalf("purgeOutbox failed: %v", err)
+	}
+
+	entries, err := outbox.List()
+	if err != nil {
+		t.Fatalf("Failed to list outbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != keep.ID {
+		t.Errorf("Expected only %s to remain, got %d entries", keep.ID, len(entries))
+	}
+}
+
+func TestPurgeOutboxAll(t *testing.T) {
+	outbox := createTestOutbox(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := outbox.Add("email", notifier.NewMessage(notifier.MessageTypeMessage, "", "msg", notifier.SeverityInfo), nil); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	if err := purgeOutbox(outbox, nil, true); err != nil {
+		t.Fatalf("purgeOutbox failed: %v", err)
+	}
+
+	entries, _ := outbox.List()
+	if len(entries) != 0 {
+		t.Errorf("Expected empty outbox, got %d entries", len(entries))
+	}
+}
+
+func TestListOutbox(t *testing.T) {
+	outbox := createTestOutbox(t)
+
+	// Empty outbox
+	if err := listOutbox(outbox); err != nil {
+		t.Errorf("listOutbox failed on empty outbox: %v", err)
+	}
+
+	if _, err := outbox.Add("telegram", notifier.NewMessage(notifier.MessageTypeSummary, "/var/log/app.log", "summary", notifier.SeverityError), errors.New("unreachable")); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := listOutbox(outbox); err != nil {
+		t.Errorf("listOutbox failed: %v", err)
+	}
+}
+
+func TestDescribeOutboxMessage(t *testing.T) {
+	tests := []struct {
+		msg      *notifier.Message
+		expected string
+	}{
+		{nil, "-"},
+		{&notifier.Message{Type: notifier.MessageTypeSummary, Source: "/var/log/app.log"}, "summary: /var/log/app.log"},
+		{&notifier.Message{Type: notifier.MessageTypeMessage, Body: "first line\nsecond line"}, "message: first line"},
+	}
+
+	for _, tt := range tests {
+		if got := describeOutboxMessage(tt.msg); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
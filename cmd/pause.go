@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause <process-id>",
+	Short: "Pause analysis of a running monitor, e.g. during maintenance",
+	Long: `Stop summarizing and alerting for a running daemon, or a monitor running in
+the agent, without stopping it. Lines are still read while paused; on resume
+they are summarized as one "During Maintenance" digest, or discarded with
+--drop. With --for the monitor resumes on its own.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		processID := args[0]
+		duration, _ := cmd.Flags().GetDuration("for")
+		drop, _ := cmd.Flags().GetBool("drop")
+		if duration < 0 {
+			logger.Fatalf("--for must not be negative")
+		}
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		var snapshot collector.MonitorSnapshot
+		req := control.Request{Command: control.CommandPause, Duration: duration, Drop: drop}
+		if err := callMonitor(manager, processID, req, &snapshot); err != nil {
+			logger.Fatalf("Failed to pause %s: %v", processID, err)
+		}
+
+		if snapshot.PausedUntil.IsZero() {
+			logger.UserSuccessf("Paused %s until 'lai unpause %s'\n", processID, processID)
+		} else {
+			logger.UserSuccessf("Paused %s until %s\n", processID, snapshot.PausedUntil.Local().Format(time.DateTime))
+		}
+		if drop {
+			logger.UserInfo("Lines collected while paused will be discarded")
+		} else {
+			logger.UserInfo("Lines collected while paused will be summarized when it resumes")
+		}
+	},
+}
+
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause <process-id>",
+	Short: "Resume analysis of a paused monitor",
+	Long:  "Resume summarizing and alerting for a paused monitor, sending the digest of the lines collected while it was paused.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		processID := args[0]
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		if err := callMonitor(manager, processID, control.Request{Command: control.CommandResume}, nil); err != nil {
+			logger.Fatalf("Failed to unpause %s: %v", processID, err)
+		}
+		logger.UserSuccessf("Resumed %s\n", processID)
+	},
+}
+
+func init() {
+	pauseCmd.Flags().Duration("for", 0, "Resume automatically after this duration, e.g. 30m (default: until unpaused)")
+	pauseCmd.Flags().Bool("drop", false, "Discard the lines collected while paused instead of summarizing them")
+	rootCmd.AddCommand(pauseCmd, unpauseCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+func TestPauseCommandFlags(t *testing.T) {
+	for _, flag := range []string{"for", "drop"} {
+		if pauseCmd.Flag(flag) == nil {
+			t.Errorf("Flag '%s' not found", flag)
+		}
+	}
+	if pauseCmd.Args == nil || unpauseCmd.Args == nil {
+		t.Error("Expected pause and unpause to validate their arguments")
+	}
+}
+
+func TestPauseRequestCarriesOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := daemon.NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var got control.Request
+	server, err := control.Listen(manager.AgentSocketPath(), func(req control.Request) (interface{}, error) {
+		got = req
+		return collector.MonitorSnapshot{Paused: true}, nil
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+	defer server.Close()
+
+	var snapshot collector.MonitorSnapshot
+	req := control.Request{Command: control.CommandPause, Duration: 30 * time.Minute, Drop: true}
+	if err := callMonitor(manager, "worker", req, &snapshot); err != nil {
+		t.Fatalf("callMonitor failed: %v", err)
+	}
+	if got.Duration != 30*time.Minute || !got.Drop || got.Monitor != "worker" || !snapshot.Paused {
+		t.Errorf("Unexpected request %+v or result %+v", got, snapshot)
+	}
+}
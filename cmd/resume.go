@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shiquda/lai/internal/daemon"
@@ -47,8 +49,13 @@ func resumeProcess(manager *daemon.Manager, processID string) error {
 		return fmt.Errorf("process %s is already running", processID)
 	}
 
-	// Save original log file, but keep process info intact
-	originalLogFile := info.LogFile
+	// Recreate the monitor from its launch spec
+	if info.Launch == nil {
+		info.Launch = legacyLaunchSpec(info)
+	}
+
+	// A manual resume starts the supervisor's restart count afresh
+	info.Restarts = 0
 
 	// Mark process as resuming to prevent duplicate operations
 	info.Status = "resuming"
@@ -56,13 +63,25 @@ func resumeProcess(manager *daemon.Manager, processID string) error {
 		return fmt.Errorf("failed to update process status: %w", err)
 	}
 
-	// Get log file path for daemon
+	if err := launchDaemon(manager, info); err != nil {
+		return err
+	}
+
 	daemonLogPath := manager.GetProcessLogPath(processID)
+	logger.UserSuccessf("Resumed daemon with process ID: %s (PID: %d)", processID, info.PID)
+	logger.UserInfof("Log file: %s\n", daemonLogPath)
+	logger.UserInfo("Use 'lai list' to see running processes")
+	logger.UserInfof("Use 'lai logs %s' to view logs\n", processID)
+	logger.UserInfof("Use 'lai stop %s' to stop the process\n", processID)
 
-	// Set environment variable for daemon mode
-	os.Setenv("LAI_DAEMON_MODE", "1")
+	return nil
+}
 
+// launchDaemon starts a daemon process from its launch spec under its
+// existing process ID and saves the new PID
+func launchDaemon(manager *daemon.Manager, info *daemon.ProcessInfo) error {
 	// Redirect output to log file
+	daemonLogPath := manager.GetProcessLogPath(info.ID)
 	logFileHandle, err := os.OpenFile(daemonLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		// Restore status on error
@@ -73,17 +92,16 @@ func resumeProcess(manager *daemon.Manager, processID string) error {
 	defer logFileHandle.Close()
 
 	// Start daemon process
-	cmd := os.Args[0]
-	args := []string{cmd, "start", originalLogFile, "-d"}
-
-	// Add the original process name if it was a custom name (no timestamp)
-	if !containsTimestamp(processID) {
-		args = append(args, "-n", processID)
+	cmd, err := os.Executable()
+	if err != nil {
+		cmd = os.Args[0]
 	}
+	args := append([]string{cmd}, resumeArgs(info.Launch)...)
 
 	// Use platform-specific daemon process creation
 	p := platform.New()
-	process, err := p.Process.StartDaemonProcess(cmd, args, logFileHandle, append(os.Environ(), "LAI_DAEMON_MODE=1", "LAI_RESUME_MODE=1"))
+	env := append(os.Environ(), "LAI_DAEMON_MODE=1", "LAI_PROCESS_ID="+info.ID)
+	process, err := p.Process.StartDaemonProcess(cmd, args, logFileHandle, env)
 	if err != nil {
 		// Restore status on error
 		info.Status = "stopped"
@@ -95,19 +113,105 @@ func resumeProcess(manager *daemon.Manager, processID string) error {
 	info.PID = process.Pid
 	info.Status = "running"
 	info.StartTime = time.Now()
+	info.Exit = ""
+	info.StopRequested = false
 	if err := manager.SaveProcessInfo(info); err != nil {
 		logger.Warnf("Warning: failed to update process info: %v", err)
 	}
 
-	logger.UserSuccessf("Resumed daemon with process ID: %s (PID: %d)", processID, process.Pid)
-	logger.UserInfof("Log file: %s\n", daemonLogPath)
-	logger.UserInfo("Use 'lai list' to see running processes")
-	logger.UserInfof("Use 'lai logs %s' to view logs\n", processID)
-	logger.UserInfof("Use 'lai stop %s' to stop the process\n", processID)
-
 	return nil
 }
 
+// resumeArgs returns the lai arguments that recreate the monitor of a launch
+// spec in daemon mode
+func resumeArgs(spec *daemon.LaunchSpec) []string {
+	args := []string{spec.Type}
+	for _, setting := range launchSettings(spec.Options) {
+		args = append(args, "--"+setting.flag+"="+setting.value)
+	}
+	args = append(args, "--daemon", "--")
+	return append(args, spec.Args...)
+}
+
+// legacyLaunchSpec rebuilds the launch spec of a process saved without one.
+// Only the source and the custom name can be recovered.
+func legacyLaunchSpec(info *daemon.ProcessInfo) *daemon.LaunchSpec {
+	spec := &daemon.LaunchSpec{Type: daemon.MonitorTypeFile, Source: info.LogFile}
+	if command, ok := strings.CutPrefix(info.LogFile, "COMMAND_SOURCE:"); ok {
+		spec.Type = daemon.MonitorTypeExec
+		spec.Source = command
+	}
+	spec.Args = []string{spec.Source}
+
+	// Add the original process name if it was a custom name (no timestamp)
+	if !containsTimestamp(info.ID) {
+		spec.Options.Name = info.ID
+	}
+	return spec
+}
+
+// launchSetting is a command-line option of a launch spec
+type launchSetting struct {
+	flag  string
+	value string
+}
+
+// launchSettings lists the options a monitor was started with, leaving out
+// those that fall back to the global config
+func launchSettings(options daemon.LaunchOptions) []launchSetting {
+	var settings []launchSetting
+	add := func(flag, value string) {
+		settings = append(settings, launchSetting{flag: flag, value: value})
+	}
+
+	if options.Name != "" {
+		add("name", options.Name)
+	}
+	if options.LineThreshold != nil {
+		add("line-threshold", strconv.Itoa(*options.LineThreshold))
+	}
+	if options.CheckInterval != nil {
+		add("interval", options.CheckInterval.String())
+	}
+	if options.ChatID != nil {
+		add("chat-id", *options.ChatID)
+	}
+	if options.WorkingDir != "" {
+		add("workdir", options.WorkingDir)
+	}
+	if options.FinalSummary != nil {
+		add("final-summary", strconv.FormatBool(*options.FinalSummary))
+	}
+	if options.ErrorOnlyMode != nil {
+		add("error-only", strconv.FormatBool(*options.ErrorOnlyMode))
+	}
+	if options.FinalSummaryOnly != nil {
+		add("final-summary-only", strconv.FormatBool(*options.FinalSummaryOnly))
+	}
+	if len(options.Notifiers) > 0 {
+		add("notifiers", strings.Join(options.Notifiers, ","))
+	}
+	if options.ExpectActivityWithin != nil {
+		add("expect-activity-within", options.ExpectActivityWithin.String())
+	}
+	if options.Restart != "" {
+		add("restart", options.Restart)
+	}
+	if options.RestartDelay != nil {
+		add("restart-delay", options.RestartDelay.String())
+	}
+	for _, pattern := range options.Include {
+		add("include", pattern)
+	}
+	for _, pattern := range options.Exclude {
+		add("exclude", pattern)
+	}
+	if options.Template != "" {
+		add("template", options.Template)
+	}
+	return settings
+}
+
 // Helper function to check if process ID contains timestamp
 func containsTimestamp(processID string) bool {
 	// Simple heuristic: if it contains underscore followed by digits, likely has timestamp
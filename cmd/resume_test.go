@@ -280,3 +280,117 @@ func contains(s, substr string) bool {
 			(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
 				strings.Contains(s, substr))))
 }
+
+func TestResumeArgs_RecreatesExecMonitor(t *testing.T) {
+	runner := &ExecCommandRunner{}
+	cmd := NewExecCommand()
+	if err := cmd.Flags().Parse([]string{"-l", "5", "--interval", "1m", "-E", "--notifiers", "slack,email", "-n", "build", "--restart", "on-failure", "--restart-delay", "10s", "--", "make", "-j4"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	options, source, err := runner.ParseArgs(cmd, cmd.Flags().Args())
+	if err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	spec := options.LaunchSpec(source)
+	if spec.Type != daemon.MonitorTypeExec || spec.Source != "make -j4" {
+		t.Errorf("Unexpected spec type/source: %s %q", spec.Type, spec.Source)
+	}
+	cwd, _ := os.Getwd()
+	if spec.Options.WorkingDir != cwd {
+		t.Errorf("Expected exec spec to keep the working directory %s, got %s", cwd, spec.Options.WorkingDir)
+	}
+
+	// Parse the resume arguments again and compare the resulting options
+	args := resumeArgs(spec)
+	if args[0] != "exec" {
+		t.Fatalf("Expected exec subcommand, got %v", args)
+	}
+	resumed := NewExecCommand()
+	if err := resumed.Flags().Parse(args[1:]); err != nil {
+		t.Fatalf("Failed to parse resume args %v: %v", args, err)
+	}
+	resumedOptions, resumedSource, err := runner.ParseArgs(resumed, resumed.Flags().Args())
+	if err != nil {
+		t.Fatalf("Failed to parse resume args: %v", err)
+	}
+
+	if resumedSource.GetIdentifier() != source.GetIdentifier() {
+		t.Errorf("Source mismatch: expected %s, got %s", source.GetIdentifier(), resumedSource.GetIdentifier())
+	}
+	if !resumedOptions.DaemonMode {
+		t.Error("Resumed monitor should run in daemon mode")
+	}
+	if resumedOptions.ProcessName != "build" || *resumedOptions.LineThreshold != 5 || *resumedOptions.CheckInterval != time.Minute {
+		t.Errorf("Options not recreated: %+v", resumedOptions)
+	}
+	if resumedOptions.ErrorOnlyMode == nil || !*resumedOptions.ErrorOnlyMode {
+		t.Error("Expected error-only mode to be recreated")
+	}
+	if strings.Join(resumedOptions.EnabledNotifiers, ",") != "slack,email" {
+		t.Errorf("Notifiers mismatch: %v", resumedOptions.EnabledNotifiers)
+	}
+	if resumedOptions.WorkingDir != cwd {
+		t.Errorf("Working directory mismatch: %s", resumedOptions.WorkingDir)
+	}
+	if resumedOptions.RestartPolicy != "on-failure" || resumedOptions.RestartDelay == nil || *resumedOptions.RestartDelay != 10*time.Second {
+		t.Errorf("Restart policy not recreated: %q %v", resumedOptions.RestartPolicy, resumedOptions.RestartDelay)
+	}
+	if resumedOptions.FinalSummary != nil || resumedOptions.ChatID != nil {
+		t.Error("Options left unset at launch should stay unset")
+	}
+}
+
+func TestLaunchSpec_FileMonitorUsesAbsolutePath(t *testing.T) {
+	runner := &FileCommandRunner{}
+	cmd := NewFileCommand()
+	options, source, err := runner.ParseArgs(cmd, []string{"app.log"})
+	if err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	spec := options.LaunchSpec(source)
+	expected, _ := filepath.Abs("app.log")
+	if spec.Type != daemon.MonitorTypeFile || spec.Source != expected {
+		t.Errorf("Expected file spec for %s, got %s %s", expected, spec.Type, spec.Source)
+	}
+
+	args := resumeArgs(spec)
+	if strings.Join(args, " ") != "file --daemon -- "+expected {
+		t.Errorf("Unexpected resume args: %v", args)
+	}
+}
+
+func TestLegacyLaunchSpec(t *testing.T) {
+	spec := legacyLaunchSpec(&daemon.ProcessInfo{ID: "webapp", LogFile: "/var/log/app.log"})
+	if spec.Type != daemon.MonitorTypeFile || spec.Args[0] != "/var/log/app.log" || spec.Options.Name != "webapp" {
+		t.Errorf("Unexpected file spec: %+v", spec)
+	}
+
+	spec = legacyLaunchSpec(&daemon.ProcessInfo{ID: "COMMAND_SOURCE_1700000000", LogFile: "COMMAND_SOURCE:npm run dev"})
+	if spec.Type != daemon.MonitorTypeExec || spec.Source != "npm run dev" || spec.Options.Name != "" {
+		t.Errorf("Unexpected exec spec: %+v", spec)
+	}
+}
+
+func TestParseCommonArgs_InvalidRestartPolicy(t *testing.T) {
+	cmd := NewFileCommand()
+	if err := cmd.Flags().Parse([]string{"-d", "--restart", "sometimes"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	_, _, err := (&FileCommandRunner{}).ParseArgs(cmd, []string{"app.log"})
+	if err == nil || !strings.Contains(err.Error(), "invalid restart policy") {
+		t.Errorf("Expected invalid restart policy error, got: %v", err)
+	}
+}
+
+func TestParseCommonArgs_RestartDelayTooShort(t *testing.T) {
+	cmd := NewExecCommand()
+	if err := cmd.Flags().Parse([]string{"--restart", "always", "--restart-delay", "0s", "--", "./worker.sh"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	_, _, err := (&ExecCommandRunner{}).ParseArgs(cmd, cmd.Flags().Args())
+	if err == nil || !strings.Contains(err.Error(), "restart-delay must be at least 1s") {
+		t.Errorf("Expected restart-delay error, got: %v", err)
+	}
+}
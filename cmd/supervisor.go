@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+var supervisorCmd = &cobra.Command{
+	Use:   "supervisor",
+	Short: "Restart crashed daemon processes",
+	Long: `Watch all registered daemon processes and restart the ones that died,
+following the --restart policy they were started with (always or on-failure).
+Restarts back off exponentially and stop after --max-restarts, at which point
+a lifecycle notification is sent. Daemons stopped with 'lai stop' are left alone.
+
+The supervisor runs in the foreground; use systemd, launchd or nohup to keep it running.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := parseSupervisorFlags(cmd)
+		if err != nil {
+			logger.Fatalf("Invalid supervisor options: %v", err)
+		}
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		if err := runSupervisor(manager, cfg); err != nil {
+			logger.Fatalf("Supervisor failed: %v", err)
+		}
+	},
+}
+
+func init() {
+	defaults := daemon.DefaultSupervisorConfig()
+	supervisorCmd.Flags().Duration("interval", defaults.Interval, "How often to check daemon processes")
+	supervisorCmd.Flags().Duration("backoff", defaults.Backoff, "Delay before the first restart, doubled for each further one")
+	supervisorCmd.Flags().Duration("max-backoff", defaults.MaxBackoff, "Longest delay between restarts")
+	supervisorCmd.Flags().Int("max-restarts", defaults.MaxRestarts, "Restarts before giving up on a daemon (0 for no limit)")
+	supervisorCmd.Flags().Duration("reset-after", defaults.ResetAfter, "Uptime after which a daemon's restart count is reset")
+	rootCmd.AddCommand(supervisorCmd)
+}
+
+// parseSupervisorFlags reads the supervisor settings from the command flags
+func parseSupervisorFlags(cmd *cobra.Command) (daemon.SupervisorConfig, error) {
+	var cfg daemon.SupervisorConfig
+	cfg.Interval, _ = cmd.Flags().GetDuration("interval")
+	cfg.Backoff, _ = cmd.Flags().GetDuration("backoff")
+	cfg.MaxBackoff, _ = cmd.Flags().GetDuration("max-backoff")
+	cfg.MaxRestarts, _ = cmd.Flags().GetInt("max-restarts")
+	cfg.ResetAfter, _ = cmd.Flags().GetDuration("reset-after")
+
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("interval must be positive")
+	}
+	if cfg.Backoff < 0 || cfg.MaxBackoff < 0 || cfg.MaxRestarts < 0 {
+		return cfg, fmt.Errorf("backoff, max-backoff and max-restarts must not be negative")
+	}
+	return cfg, nil
+}
+
+// runSupervisor supervises the daemons until interrupted. Only one supervisor
+// may run at a time, so restarts are not duplicated.
+func runSupervisor(manager *daemon.Manager, cfg daemon.SupervisorConfig) error {
+	pidFile := manager.SupervisorPidPath()
+	if data, err := os.ReadFile(pidFile); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid != os.Getpid() && manager.IsProcessRunning(pid) {
+			return fmt.Errorf("a supervisor is already running (PID %d)", pid)
+		}
+	}
+	if err := daemon.CreatePidFile(pidFile); err != nil {
+		return fmt.Errorf("failed to create PID file: %w", err)
+	}
+	defer daemon.RemovePidFile(pidFile)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	restart := func(info *daemon.ProcessInfo) error {
+		return launchDaemon(manager, info)
+	}
+	supervisor := daemon.NewSupervisor(manager, cfg, restart, notifyRestartsExhausted)
+
+	logger.UserInfof("Supervising daemon processes (checking every %v, max %d restarts)\n", cfg.Interval, cfg.MaxRestarts)
+	supervisor.Run(ctx)
+	logger.UserInfo("Supervisor stopped")
+	return nil
+}
+
+// notifyRestartsExhausted sends a lifecycle notification when the supervisor
+// gives up on a daemon. It goes to the daemon's own notifiers if it was
+// started with --notifiers, otherwise to every enabled provider.
+func notifyRestartsExhausted(info *daemon.ProcessInfo, reason string) {
+	cfg, err := config.BuildRuntimeConfig("", nil, nil, nil)
+	if err != nil {
+		logger.Errorf("Failed to load configuration: %v", err)
+		return
+	}
+
+	source := strings.TrimPrefix(info.LogFile, "COMMAND_SOURCE:")
+	notifications := cfg.Notifications
+	if info.Launch != nil {
+		source = info.Launch.Source
+		if len(info.Launch.Options.Notifiers) > 0 {
+			if notifications, err = notifier.SelectProviders(cfg.Notifications, info.Launch.Options.Notifiers); err != nil {
+				logger.Errorf("Failed to select notifiers for %s: %v", info.ID, err)
+				return
+			}
+		}
+	}
+
+	notifyNotifier, err := notifier.NewNotifyNotifier(&notifications)
+	if err != nil {
+		logger.Errorf("Failed to create notifier: %v", err)
+		return
+	}
+	defer notifyNotifier.Close()
+
+	body := fmt.Sprintf("Monitor: %s\nSource: %s\n\n%s. Run 'lai resume %s' once the problem is fixed.", info.ID, source, reason, info.ID)
+	msg := notifier.NewMessage(notifier.MessageTypeLifecycle, source, body, notifier.SeverityError)
+	msg.Event = notifier.LifecycleGaveUp
+	msg.Monitor = info.ID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := notifyNotifier.Send(ctx, msg); err != nil {
+		logger.Errorf("Failed to send notification for %s: %v", info.ID, err)
+	}
+}
@@ -274,6 +274,14 @@ func getProviderDescription(provider string) string {
 		"pagerduty":       "PagerDuty",
 		"dingtalk":        "DingTalk",
 		"wechat":          "WeChat",
+		"webhook":         "Generic Webhook",
+		"teams":           "Microsoft Teams",
+		"mattermost":      "Mattermost",
+		"rocketchat":      "Rocket.Chat",
+		"ntfy":            "ntfy",
+		"gotify":          "Gotify",
+		"matrix":          "Matrix",
+		"signal":          "Signal (signal-cli REST)",
 	}
 
 	if desc, exists := descriptions[provider]; exists {
@@ -288,7 +296,7 @@ func showServiceConfigurationDetails(serviceName string, serviceConfig config.Se
 	logger.UserInfof("   Provider: %s\n", serviceConfig.Provider)
 
 	// Show required configuration keys
-	requiredKeys := getRequiredConfigKeys(serviceConfig.Provider)
+	requiredKeys := getRequiredConfigKeys(serviceConfig)
 	var missingKeys []string
 
 	for _, key := range requiredKeys {
@@ -308,12 +316,15 @@ func showServiceConfigurationDetails(serviceName string, serviceConfig config.Se
 }
 
 // getRequiredConfigKeys returns the required configuration keys for a provider
-func getRequiredConfigKeys(provider string) []string {
-	switch provider {
+func getRequiredConfigKeys(serviceConfig config.ServiceConfig) []string {
+	switch serviceConfig.Provider {
 	case "telegram":
 		return []string{"bot_token", "chat_id"}
 	case "slack":
-		return []string{"oauth_token"}
+		if _, ok := serviceConfig.Config["webhook_url"]; ok {
+			return []string{"webhook_url"}
+		}
+		return []string{"oauth_token", "channel_ids"}
 	case "slack_webhook":
 		return []string{"webhook_url"}
 	case "discord":
@@ -336,6 +347,18 @@ func getRequiredConfigKeys(provider string) []string {
 		return []string{"access_token"}
 	case "wechat":
 		return []string{"corp_id", "corp_secret", "agent_id"}
+	case "webhook":
+		return []string{"url"}
+	case "teams", "mattermost", "rocketchat":
+		return []string{"webhook_url"}
+	case "ntfy":
+		return []string{"topic"}
+	case "gotify":
+		return []string{"server_url", "app_token"}
+	case "matrix":
+		return []string{"homeserver", "access_token", "room_id"}
+	case "signal":
+		return []string{"api_url", "number", "recipients"}
 	default:
 		return []string{}
 	}
@@ -380,7 +403,7 @@ func validateServiceConfiguration(serviceName string, serviceConfig config.Servi
 		return fmt.Errorf("service is disabled")
 	}
 
-	requiredKeys := getRequiredConfigKeys(serviceConfig.Provider)
+	requiredKeys := getRequiredConfigKeys(serviceConfig)
 	var missingKeys []string
 
 	for _, key := range requiredKeys {
@@ -608,13 +631,15 @@ func listAvailableNotifiers() error {
 	groupedProviders := make(map[string][]string)
 	for name := range cfg.Notifications.Providers {
 		group := "Other"
-		if strings.Contains(name, "telegram") {
+		if strings.Contains(name, "telegram") || strings.Contains(name, "matrix") || strings.Contains(name, "signal") {
 			group = "Messaging"
-		} else if strings.Contains(name, "slack") || strings.Contains(name, "discord") {
+		} else if strings.Contains(name, "slack") || strings.Contains(name, "discord") || strings.Contains(name, "teams") ||
+			strings.Contains(name, "mattermost") || strings.Contains(name, "rocketchat") {
 			group = "Team Chat"
 		} else if strings.Contains(name, "email") || strings.Contains(name, "smtp") || strings.Contains(name, "gmail") {
 			group = "Email"
-		} else if strings.Contains(name, "pushover") || strings.Contains(name, "twilio") {
+		} else if strings.Contains(name, "pushover") || strings.Contains(name, "twilio") || strings.Contains(name, "ntfy") ||
+			strings.Contains(name, "gotify") {
 			group = "SMS/Push"
 		}
 
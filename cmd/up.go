@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// defaultMonitorsFile is picked up from the current directory by up, down
+// and reload when no --file is given
+const defaultMonitorsFile = "lai.yaml"
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Start the monitors declared in the configuration",
+	Long: `Start the monitors declared in a monitors file as daemons. Monitors that
+are already running with the same settings are left alone, changed ones are
+restarted with their new settings.
+
+The monitors are read from --file, otherwise from ./lai.yaml if it exists,
+otherwise from the monitors section of ~/.lai/config.yaml.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReconcile(cmd, false)
+	},
+}
+
+var reloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Apply changes to the declared monitors",
+	Long: `Reconcile the running daemons with the monitors file: start new monitors,
+restart changed ones and stop and remove monitors that are no longer declared.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReconcile(cmd, true)
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop the monitors declared in the configuration",
+	Long:  "Stop and remove every daemon started by 'lai up' from the monitors file. Other daemons are left alone.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("file")
+		configPath, _, err := loadDeclaredMonitors(path)
+		if err != nil {
+			logger.Fatalf("Failed to load monitors: %v", err)
+		}
+
+		manager, err := daemon.NewManager()
+		if err != nil {
+			logger.Fatalf("Failed to create daemon manager: %v", err)
+		}
+
+		processes, err := manager.ListProcesses()
+		if err != nil {
+			logger.Fatalf("Failed to list processes: %v", err)
+		}
+
+		stopped := 0
+		for _, info := range processes {
+			if !managedBy(info, configPath) {
+				continue
+			}
+			if err := removeMonitor(manager, info); err != nil {
+				logger.UserErrorf("Failed to stop %s: %v\n", info.ID, err)
+				continue
+			}
+			logger.UserInfof("Stopped %s\n", info.ID)
+			stopped++
+		}
+		logger.UserSuccessf("%d monitor(s) from %s stopped\n", stopped, configPath)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{upCmd, downCmd, reloadCmd} {
+		c.Flags().StringP("file", "f", "", "Monitors file (default ./lai.yaml, then ~/.lai/config.yaml)")
+		rootCmd.AddCommand(c)
+	}
+}
+
+// Reconcile actions
+const (
+	actionStart    = "start"
+	actionRecreate = "recreate"
+	actionRemove   = "remove"
+	actionOrphan   = "orphan"
+)
+
+// declaredMonitor is a monitor from the monitors file with the launch spec
+// its daemon should run
+type declaredMonitor struct {
+	Name string
+	Spec *daemon.LaunchSpec
+}
+
+// reconcileAction is one change needed to bring the daemons in line with the
+// monitors file
+type reconcileAction struct {
+	Kind string
+	ID   string
+	Spec *daemon.LaunchSpec // Desired spec for start and recreate
+}
+
+// runReconcile starts and restarts the declared monitors. With prune set,
+// monitors removed from the file are stopped too.
+func runReconcile(cmd *cobra.Command, prune bool) {
+	path, _ := cmd.Flags().GetString("file")
+	configPath, monitors, err := loadDeclaredMonitors(path)
+	if err != nil {
+		logger.Fatalf("Failed to load monitors: %v", err)
+	}
+	if len(monitors) == 0 {
+		logger.UserWarningf("No monitors declared in %s\n", configPath)
+	}
+
+	manager, err := daemon.NewManager()
+	if err != nil {
+		logger.Fatalf("Failed to create daemon manager: %v", err)
+	}
+
+	processes, err := manager.ListProcesses()
+	if err != nil {
+		logger.Fatalf("Failed to list processes: %v", err)
+	}
+
+	actions, err := planReconcile(desiredMonitors(monitors, configPath), processes, configPath, prune)
+	if err != nil {
+		logger.Fatalf("Cannot apply %s: %v", configPath, err)
+	}
+
+	existing := make(map[string]*daemon.ProcessInfo)
+	for _, info := range processes {
+		existing[info.ID] = info
+	}
+
+	failed := 0
+	for _, action := range actions {
+		if err := applyAction(manager, action, existing[action.ID]); err != nil {
+			logger.UserErrorf("Failed to %s %s: %v\n", action.Kind, action.ID, err)
+			failed++
+		}
+	}
+
+	if len(actions) == 0 {
+		logger.UserSuccessf("All %d monitor(s) are up to date\n", len(monitors))
+		return
+	}
+	if failed > 0 {
+		logger.Fatalf("%d of %d change(s) failed", failed, len(actions))
+	}
+	logger.UserInfo("Use 'lai list' to see running processes")
+}
+
+// loadDeclaredMonitors reads and validates the monitors, returning the
+// absolute path of the file that declares them
+func loadDeclaredMonitors(path string) (string, []config.MonitorDefinition, error) {
+	globalConfig, err := config.LoadGlobalConfig()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	if path == "" {
+		if _, err := os.Stat(defaultMonitorsFile); err == nil {
+			path = defaultMonitorsFile
+		}
+	}
+
+	var monitors []config.MonitorDefinition
+	if path != "" {
+		if monitors, err = config.LoadMonitorsFile(path); err != nil {
+			return "", nil, err
+		}
+	} else {
+		if path, err = config.GetGlobalConfigPath(); err != nil {
+			return "", nil, err
+		}
+		monitors = globalConfig.Monitors
+	}
+
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+
+	if err := config.ValidateMonitors(monitors, globalConfig.PromptTemplates); err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return path, monitors, nil
+}
+
+// desiredMonitors converts monitor definitions into launch specs. Relative
+// paths are resolved against the directory of the monitors file.
+func desiredMonitors(monitors []config.MonitorDefinition, configPath string) []declaredMonitor {
+	baseDir := filepath.Dir(configPath)
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(baseDir, path)
+	}
+
+	declared := make([]declaredMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		spec := &daemon.LaunchSpec{
+			Type:   monitor.Type,
+			Source: monitor.Source,
+			Config: configPath,
+			Options: daemon.LaunchOptions{
+				Name:                 monitor.Name,
+				LineThreshold:        monitor.LineThreshold,
+				CheckInterval:        monitor.CheckInterval,
+				FinalSummary:         monitor.FinalSummary,
+				ErrorOnlyMode:        monitor.ErrorOnlyMode,
+				FinalSummaryOnly:     monitor.FinalSummaryOnly,
+				Notifiers:            monitor.Notifiers,
+				ExpectActivityWithin: monitor.ExpectActivityWithin,
+				Restart:              monitor.Restart,
+				RestartDelay:         monitor.RestartDelay,
+				Include:              monitor.Filters.Include,
+				Exclude:              monitor.Filters.Exclude,
+				Template:             monitor.Template,
+			},
+		}
+
+		switch monitor.Type {
+		case daemon.MonitorTypeFile:
+			spec.Source = resolve(monitor.Source)
+		case daemon.MonitorTypeExec:
+			spec.Options.WorkingDir = baseDir
+			if monitor.WorkingDir != "" {
+				spec.Options.WorkingDir = resolve(monitor.WorkingDir)
+			}
+		}
+		spec.Args = []string{spec.Source}
+
+		declared = append(declared, declaredMonitor{Name: monitor.Name, Spec: spec})
+	}
+	return declared
+}
+
+// planReconcile works out the actions that bring the daemons in line with the
+// declared monitors. Daemons started by hand are never touched, and a
+// declared name already taken by one is an error.
+func planReconcile(declared []declaredMonitor, processes []*daemon.ProcessInfo, configPath string, prune bool) ([]reconcileAction, error) {
+	existing := make(map[string]*daemon.ProcessInfo)
+	for _, info := range processes {
+		existing[info.ID] = info
+	}
+
+	var actions []reconcileAction
+	wanted := make(map[string]bool)
+	for _, monitor := range declared {
+		wanted[monitor.Name] = true
+		info, ok := existing[monitor.Name]
+		switch {
+		case !ok:
+			actions = append(actions, reconcileAction{Kind: actionStart, ID: monitor.Name, Spec: monitor.Spec})
+		case !managedBy(info, configPath):
+			return nil, fmt.Errorf("monitor %s: a daemon with this ID was not started from this file (stop and clean it first)", monitor.Name)
+		case !sameLaunchSpec(info.Launch, monitor.Spec):
+			actions = append(actions, reconcileAction{Kind: actionRecreate, ID: monitor.Name, Spec: monitor.Spec})
+		case info.Status != "running":
+			actions = append(actions, reconcileAction{Kind: actionStart, ID: monitor.Name, Spec: monitor.Spec})
+		}
+	}
+
+	var undeclared []string
+	for id, info := range existing {
+		if !wanted[id] && managedBy(info, configPath) {
+			undeclared = append(undeclared, id)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, id := range undeclared {
+		kind := actionOrphan
+		if prune {
+			kind = actionRemove
+		}
+		actions = append(actions, reconcileAction{Kind: kind, ID: id})
+	}
+
+	return actions, nil
+}
+
+// managedBy reports whether a daemon was started from the given monitors file
+func managedBy(info *daemon.ProcessInfo, configPath string) bool {
+	return info.Launch != nil && info.Launch.Config == configPath
+}
+
+// sameLaunchSpec compares launch specs as stored, so an empty list and a
+// missing one are equal
+func sameLaunchSpec(a, b *daemon.LaunchSpec) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// applyAction carries out a reconcile action
+func applyAction(manager *daemon.Manager, action reconcileAction, info *daemon.ProcessInfo) error {
+	switch action.Kind {
+	case actionOrphan:
+		logger.UserWarningf("%s is no longer declared; run 'lai reload' to stop it\n", action.ID)
+		return nil
+	case actionRemove:
+		if err := removeMonitor(manager, info); err != nil {
+			return err
+		}
+		logger.UserInfof("Removed %s\n", action.ID)
+		return nil
+	case actionRecreate:
+		if err := stopAndWait(manager, info); err != nil {
+			return err
+		}
+	}
+
+	if info == nil {
+		info = &daemon.ProcessInfo{ID: action.ID}
+	}
+	info.Launch = action.Spec
+	info.LogFile = action.Spec.Source
+	if action.Spec.Type == daemon.MonitorTypeExec {
+		info.LogFile = "COMMAND_SOURCE:" + action.Spec.Source
+	}
+	info.Restarts = 0
+	info.Status = "starting"
+	if err := manager.SaveProcessInfo(info); err != nil {
+		return fmt.Errorf("failed to save process info: %w", err)
+	}
+
+	if err := launchDaemon(manager, info); err != nil {
+		return err
+	}
+
+	verb := "Started"
+	if action.Kind == actionRecreate {
+		verb = "Restarted"
+	}
+	logger.UserSuccessf("%s %s (PID: %d)\n", verb, action.ID, info.PID)
+	return nil
+}
+
+// removeMonitor stops a daemon and removes its process record
+func removeMonitor(manager *daemon.Manager, info *daemon.ProcessInfo) error {
+	if err := stopAndWait(manager, info); err != nil {
+		return err
+	}
+	return manager.RemoveProcessInfo(info.ID)
+}
+
+// stopAndWait stops a daemon and waits briefly for it to exit, so it cannot
+// race with a replacement for its process record
+func stopAndWait(manager *daemon.Manager, info *daemon.ProcessInfo) error {
+	if info.Status != "running" {
+		return nil
+	}
+	if err := manager.StopProcess(info.ID); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for manager.IsProcessRunning(info.PID) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
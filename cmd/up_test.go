@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+func TestDesiredMonitors_ResolvesPathsAgainstConfigFile(t *testing.T) {
+	configPath := filepath.Join(string(filepath.Separator), "srv", "app", "lai.yaml")
+	baseDir := filepath.Dir(configPath)
+	threshold := 3
+	monitors := []config.MonitorDefinition{
+		{Name: "app", Type: "file", Source: "logs/app.log", LineThreshold: &threshold, Filters: config.FilterConfig{Exclude: []string{"health"}}},
+		{Name: "worker", Type: "exec", Source: "python worker.py", Restart: "always"},
+		{Name: "jobs", Type: "exec", Source: "./jobs.sh", WorkingDir: "jobs"},
+	}
+
+	declared := desiredMonitors(monitors, configPath)
+	if len(declared) != 3 {
+		t.Fatalf("Expected 3 monitors, got %d", len(declared))
+	}
+
+	app := declared[0].Spec
+	if app.Source != filepath.Join(baseDir, "logs", "app.log") || app.Args[0] != app.Source {
+		t.Errorf("Expected file source resolved against %s, got %q %v", baseDir, app.Source, app.Args)
+	}
+	if app.Config != configPath || app.Options.Name != "app" || *app.Options.LineThreshold != 3 {
+		t.Errorf("Unexpected file spec: %+v", app)
+	}
+	if strings.Join(app.Options.Exclude, ",") != "health" {
+		t.Errorf("Expected filters in launch options, got %v", app.Options.Exclude)
+	}
+
+	worker := declared[1].Spec
+	if worker.Source != "python worker.py" || worker.Options.WorkingDir != baseDir || worker.Options.Restart != "always" {
+		t.Errorf("Unexpected exec spec: %+v", worker)
+	}
+	if jobs := declared[2].Spec; jobs.Options.WorkingDir != filepath.Join(baseDir, "jobs") {
+		t.Errorf("Expected working directory resolved against %s, got %s", baseDir, jobs.Options.WorkingDir)
+	}
+}
+
+func TestDesiredMonitors_ResumeArgsRecreateFilters(t *testing.T) {
+	monitors := []config.MonitorDefinition{{
+		Name:     "worker",
+		Type:     "exec",
+		Source:   "python worker.py",
+		Template: "Summarize {{log_content}}",
+		Filters:  config.FilterConfig{Include: []string{"ERROR|WARN", "a,b"}, Exclude: []string{"health"}},
+	}}
+	spec := desiredMonitors(monitors, "/srv/lai.yaml")[0].Spec
+
+	args := resumeArgs(spec)
+	cmd := NewExecCommand()
+	if err := cmd.Flags().Parse(args[1:]); err != nil {
+		t.Fatalf("Failed to parse launch args %v: %v", args, err)
+	}
+	options, _, err := (&ExecCommandRunner{}).ParseArgs(cmd, cmd.Flags().Args())
+	if err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	if strings.Join(options.Include, " ") != "ERROR|WARN a,b" || strings.Join(options.Exclude, " ") != "health" {
+		t.Errorf("Filters not recreated: include=%v exclude=%v", options.Include, options.Exclude)
+	}
+	if options.SummarizeTemplate != "Summarize {{log_content}}" {
+		t.Errorf("Template not recreated: %q", options.SummarizeTemplate)
+	}
+	if options.ProcessName != "worker" || !options.DaemonMode {
+		t.Errorf("Expected daemon named worker, got %+v", options)
+	}
+}
+
+func TestPlanReconcile(t *testing.T) {
+	configPath := "/srv/lai.yaml"
+	declared := desiredMonitors([]config.MonitorDefinition{
+		{Name: "new", Type: "file", Source: "/var/log/new.log"},
+		{Name: "same", Type: "file", Source: "/var/log/same.log"},
+		{Name: "changed", Type: "file", Source: "/var/log/changed.log"},
+		{Name: "down", Type: "file", Source: "/var/log/down.log"},
+	}, configPath)
+
+	spec := func(name string) *daemon.LaunchSpec {
+		for _, monitor := range declared {
+			if monitor.Name == name {
+				copied := *monitor.Spec
+				return &copied
+			}
+		}
+		t.Fatalf("No declared monitor %s", name)
+		return nil
+	}
+
+	changed := spec("changed")
+	changed.Source = "/var/log/old.log"
+	removed := spec("same")
+	removed.Options.Name = "removed"
+
+	processes := []*daemon.ProcessInfo{
+		{ID: "same", Status: "running", Launch: spec("same")},
+		{ID: "changed", Status: "running", Launch: changed},
+		{ID: "down", Status: "stopped", Launch: spec("down")},
+		{ID: "removed", Status: "running", Launch: removed},
+		{ID: "manual", Status: "running", Launch: &daemon.LaunchSpec{Type: "file", Source: "/tmp/x.log"}},
+	}
+
+	actions, err := planReconcile(declared, processes, configPath, false)
+	if err != nil {
+		t.Fatalf("planReconcile failed: %v", err)
+	}
+
+	var got []string
+	for _, action := range actions {
+		got = append(got, action.Kind+":"+action.ID)
+	}
+	expected := "start:new recreate:changed start:down orphan:removed"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("Expected actions %q, got %q", expected, strings.Join(got, " "))
+	}
+
+	actions, err = planReconcile(declared, processes, configPath, true)
+	if err != nil {
+		t.Fatalf("planReconcile failed: %v", err)
+	}
+	if last := actions[len(actions)-1]; last.Kind != actionRemove || last.ID != "removed" {
+		t.Errorf("Expected reload to remove undeclared monitor, got %+v", last)
+	}
+}
+
+func TestPlanReconcile_NameTakenByManualDaemon(t *testing.T) {
+	declared := desiredMonitors([]config.MonitorDefinition{
+		{Name: "nginx", Type: "file", Source: "/var/log/nginx.log"},
+	}, "/srv/lai.yaml")
+	processes := []*daemon.ProcessInfo{
+		{ID: "nginx", Status: "running", Launch: &daemon.LaunchSpec{Type: "file", Source: "/var/log/nginx.log"}},
+	}
+
+	if _, err := planReconcile(declared, processes, "/srv/lai.yaml", false); err == nil {
+		t.Error("Expected an error when a declared name belongs to a daemon started by hand")
+	}
+}
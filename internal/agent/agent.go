@@ -0,0 +1,318 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+)
+
+// Monitor states reported by Status
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	StateFailed  = "failed"
+)
+
+// Monitor is a monitor hosted by the agent, described by the same launch spec
+// a daemon would run
+type Monitor struct {
+	Name string
+	Spec *daemon.LaunchSpec
+}
+
+// MonitorState is a snapshot of a hosted monitor
+type MonitorState struct {
+	Name             string             `json:"name"`
+	Type             string             `json:"type"`
+	Source           string             `json:"source"`
+	State            string             `json:"state"`
+	Error            string             `json:"error,omitempty"`
+	StartedAt        time.Time          `json:"started_at"`
+	Paused           bool               `json:"paused"`
+	Counters         collector.Counters `json:"counters"`
+	LastNotification time.Time          `json:"last_notification,omitempty"`
+}
+
+// BuildFunc builds the monitor configuration of a launch spec
+type BuildFunc func(spec *daemon.LaunchSpec) (*collector.MonitorConfig, error)
+
+// Agent runs many monitors as goroutines of one process. The monitors share
+// the summarizer client, the notifiers with their rate limiters and outbox,
+// and the Telegram command listener.
+type Agent struct {
+	shared *collector.SharedResources
+	build  BuildFunc
+
+	mutex    sync.Mutex
+	monitors map[string]*hostedMonitor
+	wg       sync.WaitGroup
+}
+
+// hostedMonitor is a monitor goroutine and its state
+type hostedMonitor struct {
+	name      string
+	spec      *daemon.LaunchSpec
+	monitor   *collector.UnifiedMonitor
+	cancel    context.CancelCauseFunc
+	done      chan struct{}
+	startedAt time.Time
+
+	// Set when the monitor returns
+	state string
+	err   error
+}
+
+// errRemoved is the cancel cause of a monitor removed from the agent
+var errRemoved = errors.New("removed from agent")
+
+// New creates an agent that creates its monitors with build
+func New(build BuildFunc) *Agent {
+	return &Agent{
+		shared:   collector.NewSharedResources(),
+		build:    build,
+		monitors: make(map[string]*hostedMonitor),
+	}
+}
+
+// Start starts a monitor. A stopped or failed monitor of the same name is
+// replaced; a running one is an error.
+func (a *Agent) Start(m Monitor) error {
+	cfg, err := a.build(m.Spec)
+	if err != nil {
+		return fmt.Errorf("monitor %s: %w", m.Name, err)
+	}
+	monitor, err := collector.NewSharedUnifiedMonitor(cfg, a.shared)
+	if err != nil {
+		return fmt.Errorf("monitor %s: %w", m.Name, err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if existing, ok := a.monitors[m.Name]; ok && existing.state == StateRunning {
+		return fmt.Errorf("monitor %s is already running", m.Name)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	hosted := &hostedMonitor{
+		name:      m.Name,
+		spec:      m.Spec,
+		monitor:   monitor,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		startedAt: time.Now(),
+		state:     StateRunning,
+	}
+	a.monitors[m.Name] = hosted
+
+	a.wg.Add(1)
+	go a.run(ctx, hosted)
+
+	logger.Infof("Agent: started monitor %s", m.Name)
+	return nil
+}
+
+// run runs a monitor until it ends or is stopped
+func (a *Agent) run(ctx context.Context, hosted *hostedMonitor) {
+	defer a.wg.Done()
+	defer close(hosted.done)
+
+	err := hosted.monitor.Run(ctx)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	hosted.err = err
+	hosted.state = StateStopped
+	if err != nil {
+		hosted.state = StateFailed
+		logger.Errorf("Agent: monitor %s failed: %v", hosted.name, err)
+	} else if ctx.Err() == nil {
+		logger.Infof("Agent: monitor %s finished", hosted.name)
+	}
+}
+
+// Stop stops a monitor and removes it from the agent
+func (a *Agent) Stop(name string) error {
+	a.mutex.Lock()
+	hosted, ok := a.monitors[name]
+	if ok {
+		delete(a.monitors, name)
+	}
+	a.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("monitor not found: %s", name)
+	}
+
+	hosted.cancel(errRemoved)
+	<-hosted.done
+	logger.Infof("Agent: stopped monitor %s", name)
+	return nil
+}
+
+// Apply brings the hosted monitors in line with a list of monitors: new ones
+// are started, changed ones restarted, stopped ones started again and
+// monitors that are not listed are stopped
+func (a *Agent) Apply(monitors []Monitor) error {
+	wanted := make(map[string]bool)
+	var errs []error
+
+	for _, m := range monitors {
+		wanted[m.Name] = true
+
+		a.mutex.Lock()
+		hosted, ok := a.monitors[m.Name]
+		unchanged := ok && hosted.state == StateRunning && sameSpec(hosted.spec, m.Spec)
+		a.mutex.Unlock()
+
+		if unchanged {
+			continue
+		}
+		if ok {
+			if err := a.Stop(m.Name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := a.Start(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range a.names() {
+		if !wanted[name] {
+			if err := a.Stop(name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Status returns a snapshot of every hosted monitor, sorted by name
+func (a *Agent) Status() []MonitorState {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	states := make([]MonitorState, 0, len(a.monitors))
+	for _, hosted := range a.monitors {
+		snapshot := hosted.monitor.Snapshot()
+		state := MonitorState{
+			Name:             hosted.name,
+			Type:             hosted.spec.Type,
+			Source:           hosted.spec.Source,
+			State:            hosted.state,
+			StartedAt:        hosted.startedAt,
+			Paused:           snapshot.Paused,
+			Counters:         snapshot.Counters,
+			LastNotification: snapshot.LastNotification,
+		}
+		if hosted.err != nil {
+			state.Error = hosted.err.Error()
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Reload re-reads the shared settings: the summarizer clients and the
+// notifiers whose settings changed are created again and every running
+// monitor applies its rebuilt configuration. Monitors whose configuration no
+// longer builds keep their previous settings.
+func (a *Agent) Reload() error {
+	release := a.shared.Reset()
+	// Monitors that failed to reload keep sending through their old notifiers
+	defer func() { release(a.hostedMonitors()) }()
+
+	var errs []error
+	for _, name := range a.names() {
+		hosted := a.running(name)
+		if hosted == nil {
+			continue
+		}
+		if err := a.reloadMonitor(hosted); err != nil {
+			errs = append(errs, fmt.Errorf("monitor %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reloadMonitor applies the rebuilt configuration of a hosted monitor
+func (a *Agent) reloadMonitor(hosted *hostedMonitor) error {
+	cfg, err := a.build(hosted.spec)
+	if err != nil {
+		return err
+	}
+	return hosted.monitor.Reload(cfg)
+}
+
+// hostedMonitors returns the monitors of the agent
+func (a *Agent) hostedMonitors() []*collector.UnifiedMonitor {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	monitors := make([]*collector.UnifiedMonitor, 0, len(a.monitors))
+	for _, hosted := range a.monitors {
+		monitors = append(monitors, hosted.monitor)
+	}
+	return monitors
+}
+
+// running returns a hosted monitor that is running, or nil
+func (a *Agent) running(name string) *hostedMonitor {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if hosted, ok := a.monitors[name]; ok && hosted.state == StateRunning {
+		return hosted
+	}
+	return nil
+}
+
+// Shutdown stops every monitor and closes the shared notifiers, flushing
+// notifications that are still pending
+func (a *Agent) Shutdown() {
+	for _, name := range a.names() {
+		a.mutex.Lock()
+		hosted := a.monitors[name]
+		a.mutex.Unlock()
+		hosted.cancel(errors.New("agent shutting down"))
+	}
+	a.wg.Wait()
+
+	a.mutex.Lock()
+	a.monitors = make(map[string]*hostedMonitor)
+	a.mutex.Unlock()
+
+	a.shared.Close()
+}
+
+// names returns the names of the hosted monitors
+func (a *Agent) names() []string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	names := make([]string, 0, len(a.monitors))
+	for name := range a.monitors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sameSpec compares launch specs as they would be stored
+func sameSpec(a, b *daemon.LaunchSpec) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+// newTestAgent returns an agent whose file monitors notify a local webhook
+func newTestAgent(t *testing.T) *Agent {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	build := func(spec *daemon.LaunchSpec) (*collector.MonitorConfig, error) {
+		return &collector.MonitorConfig{
+			Source:        collector.NewFileSource(spec.Source),
+			Name:          spec.Options.Name,
+			LineThreshold: 100,
+			CheckInterval: 10 * time.Millisecond,
+			Notifications: config.NotificationsConfig{
+				Providers: map[string]config.ServiceConfig{
+					"webhook": {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": server.URL}},
+				},
+			},
+		}, nil
+	}
+	return New(build)
+}
+
+// fileMonitor declares a file monitor for a new log file
+func fileMonitor(t *testing.T, name string) Monitor {
+	path := filepath.Join(t.TempDir(), name+".log")
+	if err := os.WriteFile(path, []byte("start\n"), 0644); err != nil {
+		t.Fatalf("Failed to create log file: %v", err)
+	}
+	return Monitor{Name: name, Spec: &daemon.LaunchSpec{Type: daemon.MonitorTypeFile, Source: path, Options: daemon.LaunchOptions{Name: name}}}
+}
+
+func stateNames(states []MonitorState) string {
+	var names []string
+	for _, state := range states {
+		names = append(names, state.Name+"="+state.State)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestAgentApplyStartsRestartsAndStopsMonitors(t *testing.T) {
+	a := newTestAgent(t)
+	defer a.Shutdown()
+
+	api, worker := fileMonitor(t, "api"), fileMonitor(t, "worker")
+	if err := a.Apply([]Monitor{api, worker}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := stateNames(a.Status()); got != "api=running worker=running" {
+		t.Fatalf("Unexpected monitors: %s", got)
+	}
+
+	a.mutex.Lock()
+	apiBefore, workerBefore := a.monitors["api"], a.monitors["worker"]
+	a.mutex.Unlock()
+
+	// Change worker, drop api, add db
+	changed := fileMonitor(t, "worker")
+	db := fileMonitor(t, "db")
+	if err := a.Apply([]Monitor{changed, db}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := stateNames(a.Status()); got != "db=running worker=running" {
+		t.Fatalf("Unexpected monitors after reload: %s", got)
+	}
+
+	select {
+	case <-apiBefore.done:
+	default:
+		t.Error("Expected the removed monitor to be stopped")
+	}
+	a.mutex.Lock()
+	workerAfter := a.monitors["worker"]
+	a.mutex.Unlock()
+	if workerAfter == workerBefore {
+		t.Error("Expected the changed monitor to be restarted")
+	}
+
+	// Applying the same monitors again leaves them running
+	if err := a.Apply([]Monitor{changed, db}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	a.mutex.Lock()
+	unchanged := a.monitors["worker"] == workerAfter
+	a.mutex.Unlock()
+	if !unchanged {
+		t.Error("Expected an unchanged monitor to keep running")
+	}
+}
+
+func TestAgentStartRejectsRunningMonitor(t *testing.T) {
+	a := newTestAgent(t)
+	defer a.Shutdown()
+
+	api := fileMonitor(t, "api")
+	if err := a.Start(api); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := a.Start(api); err == nil {
+		t.Error("Expected starting a running monitor to fail")
+	}
+	if err := a.Stop("missing"); err == nil {
+		t.Error("Expected stopping an unknown monitor to fail")
+	}
+}
+
+func TestAgentHandlerOverControlSocket(t *testing.T) {
+	a := newTestAgent(t)
+	defer a.Shutdown()
+
+	api, worker := fileMonitor(t, "api"), fileMonitor(t, "worker")
+	declared := []Monitor{api}
+	shutdown := make(chan struct{})
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	server, err := control.Listen(path, a.Handler(func() ([]Monitor, error) { return declared, nil }, func() { close(shutdown) }))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+	defer server.Close()
+
+	declared = []Monitor{api, worker}
+	var result ReloadResult
+	if err := control.Call(path, control.Request{Command: control.CommandReload}, &result); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := stateNames(result.Monitors); got != "api=running worker=running" || result.Error != "" {
+		t.Errorf("Unexpected reload result: %s %s", got, result.Error)
+	}
+
+	if err := control.Call(path, control.Request{Command: control.CommandStop, Monitor: "api"}, nil); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	var states []MonitorState
+	if err := control.Call(path, control.Request{Command: control.CommandStatus}, &states); err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if got := stateNames(states); got != "worker=running" {
+		t.Errorf("Expected only worker after stop, got %s", got)
+	}
+	if states[0].Type != daemon.MonitorTypeFile || states[0].Source != worker.Spec.Source {
+		t.Errorf("Unexpected state %+v", states[0])
+	}
+
+	// Requests naming a monitor are executed by that monitor
+	var snapshot collector.MonitorSnapshot
+	if err := control.Call(path, control.Request{Command: control.CommandPause, Monitor: "worker"}, &snapshot); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if !snapshot.Paused || snapshot.Name != "worker" {
+		t.Errorf("Expected worker to be paused, got %+v", snapshot)
+	}
+	if err := control.Call(path, control.Request{Command: control.CommandStatus}, &states); err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !states[0].Paused {
+		t.Error("Expected the agent status to report the paused monitor")
+	}
+	if err := control.Call(path, control.Request{Command: control.CommandPause, Monitor: "api"}, nil); err == nil {
+		t.Error("Expected a request for a stopped monitor to fail")
+	}
+	if err := control.Call(path, control.Request{Command: control.CommandPause}, nil); err == nil {
+		t.Error("Expected pause without a monitor to fail")
+	}
+
+	if err := control.Call(path, control.Request{Command: control.CommandShutdown}, nil); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Error("Expected shutdown to be requested")
+	}
+}
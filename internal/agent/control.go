@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shiquda/lai/internal/collector"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+// ReloadResult reports the monitors running after a reload
+type ReloadResult struct {
+	Monitors []MonitorState `json:"monitors"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// Handler returns the control socket handler of the agent. Requests naming a
+// monitor are executed by that monitor; stop removes it from the agent. reload
+// loads the monitors to apply on a reload request, and shutdown is called once
+// the agent is asked to exit.
+func (a *Agent) Handler(reload func() ([]Monitor, error), shutdown func()) control.Handler {
+	return func(req control.Request) (interface{}, error) {
+		if req.Monitor != "" {
+			return a.handleMonitor(req)
+		}
+
+		switch req.Command {
+		case control.CommandStatus:
+			return a.Status(), nil
+		case control.CommandReload:
+			monitors, err := reload()
+			if err != nil {
+				return nil, err
+			}
+			result := ReloadResult{}
+			if err := errors.Join(a.Reload(), a.Apply(monitors)); err != nil {
+				result.Error = err.Error()
+			}
+			result.Monitors = a.Status()
+			return result, nil
+		case control.CommandShutdown:
+			go shutdown()
+			return nil, nil
+		case control.CommandStop:
+			return nil, fmt.Errorf("monitor is required")
+		default:
+			return nil, fmt.Errorf("command %q needs a monitor", req.Command)
+		}
+	}
+}
+
+// handleMonitor executes a request on one hosted monitor
+func (a *Agent) handleMonitor(req control.Request) (interface{}, error) {
+	if req.Command == control.CommandStop {
+		return nil, a.Stop(req.Monitor)
+	}
+
+	hosted := a.running(req.Monitor)
+	if hosted == nil {
+		return nil, fmt.Errorf("monitor %s is not running", req.Monitor)
+	}
+	return hosted.monitor.HandleControl(req, func() (*collector.MonitorConfig, error) {
+		return a.build(hosted.spec)
+	})
+}
+
+// BuildConfig builds the configuration of a monitor from a launch spec, with
+// the same defaults as the lai file and lai exec commands
+func BuildConfig(spec *daemon.LaunchSpec) (*collector.MonitorConfig, error) {
+	identifier := spec.Source
+	if spec.Type == daemon.MonitorTypeExec {
+		identifier = "COMMAND_SOURCE:" + spec.Source
+	}
+
+	options := spec.Options
+	cfg, err := collector.BuildMonitorConfig(collector.NewFileSource(identifier), collector.MonitorOverrides{
+		Name:                 options.Name,
+		LineThreshold:        options.LineThreshold,
+		CheckInterval:        options.CheckInterval,
+		ChatID:               options.ChatID,
+		WorkingDir:           options.WorkingDir,
+		FinalSummary:         options.FinalSummary,
+		ErrorOnlyMode:        options.ErrorOnlyMode,
+		FinalSummaryOnly:     options.FinalSummaryOnly,
+		ExpectActivityWithin: options.ExpectActivityWithin,
+		Notifiers:            options.Notifiers,
+		Restart:              options.Restart,
+		RestartDelay:         options.RestartDelay,
+		Include:              options.Include,
+		Exclude:              options.Exclude,
+		SummarizeTemplate:    options.Template,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return cfg, nil
+}
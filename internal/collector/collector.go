@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/shiquda/lai/internal/logger"
@@ -13,6 +14,30 @@ import (
 type LogCollector interface {
 	SetTriggerHandler(handler func(newContent string) error)
 	Start() error
+	// LastActivity returns the time the source last produced output
+	LastActivity() time.Time
+}
+
+// FinalSummaryCollector is implemented by collectors that report a final
+// summary when their source ends
+type FinalSummaryCollector interface {
+	SetFinalSummaryHandler(handler func(content string) error)
+}
+
+// BatchCollector is implemented by collectors that relaunch their source.
+// The batch handler gets the restarts since the previous batch alongside its
+// content, which holds only source output.
+type BatchCollector interface {
+	SetBatchHandler(handler func(newContent string, restarts []string) error)
+}
+
+// PendingFlusher is implemented by collectors that can hand over lines that
+// have not reached the line threshold yet
+type PendingFlusher interface {
+	// FlushPending passes the pending lines, and the restarts since the
+	// previous batch, to handler and marks them as processed. It returns the
+	// number of lines flushed.
+	FlushPending(handler func(newContent string, restarts []string) error) (int, error)
 }
 
 // Collector represents a file-based log collector
@@ -22,6 +47,16 @@ type Collector struct {
 	lastLineCount int
 	checkInterval time.Duration
 	onTrigger     func(newContent string) error
+
+	lastSeenCount int
+	lastActivity  time.Time
+	activityMutex sync.RWMutex
+
+	// triggerMutex serializes threshold checks and explicit flushes
+	triggerMutex sync.Mutex
+
+	stopChan chan struct{}
+	stopOnce sync.Once
 }
 
 func New(filePath string, lineThreshold int, checkInterval time.Duration) *Collector {
@@ -29,6 +64,7 @@ func New(filePath string, lineThreshold int, checkInterval time.Duration) *Colle
 		filePath:      filePath,
 		lineThreshold: lineThreshold,
 		checkInterval: checkInterval,
+		stopChan:      make(chan struct{}),
 	}
 }
 
@@ -40,17 +76,28 @@ func (c *Collector) Start() error {
 	if err := c.initLastLineCount(); err != nil {
 		return fmt.Errorf("failed to initialize last line count: %w", err)
 	}
+	c.markActivity(c.lastLineCount)
 
 	ticker := time.NewTicker(c.checkInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if err := c.checkAndTrigger(); err != nil {
-			logger.Errorf("Error checking file: %v", err)
+	for {
+		select {
+		case <-c.stopChan:
+			return nil
+		case <-ticker.C:
+			if err := c.checkAndTrigger(); err != nil {
+				logger.Errorf("Error checking file: %v", err)
+			}
 		}
 	}
+}
 
-	return nil
+// Stop ends Start after its current check
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
 }
 
 func (c *Collector) initLastLineCount() error {
@@ -67,6 +114,9 @@ func (c *Collector) initLastLineCount() error {
 }
 
 func (c *Collector) checkAndTrigger() error {
+	c.triggerMutex.Lock()
+	defer c.triggerMutex.Unlock()
+
 	currentLineCount, err := c.countLines()
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -75,6 +125,11 @@ func (c *Collector) checkAndTrigger() error {
 		return err
 	}
 
+	// Any change in line count (including truncation) counts as activity
+	if currentLineCount != c.lastSeenCount {
+		c.markActivity(currentLineCount)
+	}
+
 	lineDiff := currentLineCount - c.lastLineCount
 
 	if lineDiff >= c.lineThreshold {
@@ -95,6 +150,52 @@ func (c *Collector) checkAndTrigger() error {
 	return nil
 }
 
+// FlushPending passes lines added since the last trigger to handler, even if
+// they have not reached the line threshold. A file has no restarts.
+func (c *Collector) FlushPending(handler func(newContent string, restarts []string) error) (int, error) {
+	c.triggerMutex.Lock()
+	defer c.triggerMutex.Unlock()
+
+	currentLineCount, err := c.countLines()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	lineDiff := currentLineCount - c.lastLineCount
+	if lineDiff <= 0 {
+		return 0, nil
+	}
+
+	newContent, err := c.readNewLines(c.lastLineCount, currentLineCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read new lines: %w", err)
+	}
+	if err := handler(newContent, nil); err != nil {
+		return 0, err
+	}
+
+	c.lastLineCount = currentLineCount
+	return lineDiff, nil
+}
+
+// markActivity records that the file changed and now has lineCount lines
+func (c *Collector) markActivity(lineCount int) {
+	c.activityMutex.Lock()
+	defer c.activityMutex.Unlock()
+	c.lastSeenCount = lineCount
+	c.lastActivity = time.Now()
+}
+
+// LastActivity returns the time the file was last seen changing
+func (c *Collector) LastActivity() time.Time {
+	c.activityMutex.RLock()
+	defer c.activityMutex.RUnlock()
+	return c.lastActivity
+}
+
 func (c *Collector) countLines() (int, error) {
 	file, err := os.Open(c.filePath)
 	if err != nil {
@@ -286,3 +286,27 @@ func TestCollector_Integration(t *testing.T) {
 	assert.Len(t, receivedContent, 1)
 	assert.Equal(t, "new line 1\nnew line 2\n", receivedContent[0])
 }
+
+func TestCollectorFlushPending(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	if err := os.WriteFile(path, []byte("line1\nline2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	collector := New(path, 10, time.Second)
+
+	_, err := collector.FlushPending(func(string, []string) error { return assert.AnError })
+	assert.Error(t, err)
+
+	var flushed string
+	lines, err := collector.FlushPending(func(content string, _ []string) error {
+		flushed = content
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lines, "lines are kept when the handler fails")
+	assert.Equal(t, "line1\nline2\n", flushed)
+
+	lines, err = collector.FlushPending(func(string, []string) error { return nil })
+	assert.NoError(t, err)
+	assert.Zero(t, lines)
+}
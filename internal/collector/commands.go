@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+)
+
+// newCommandListener creates a Telegram command listener for the first bot
+// this monitor notifies that has commands enabled, or returns nil
+func (m *UnifiedMonitor) newCommandListener() *notifier.TelegramCommandListener {
+	names := make([]string, 0, len(m.config.Notifications.Providers))
+	for name := range m.config.Notifications.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		serviceConfig := m.config.Notifications.Providers[name]
+		if !m.config.usesProvider(name, serviceConfig) || !notifier.TelegramCommandsEnabled(serviceConfig) {
+			continue
+		}
+		listener, err := notifier.NewTelegramCommandListener(serviceConfig)
+		if err != nil {
+			logger.Warnf("Telegram commands for %s are disabled: %v", name, err)
+			return nil
+		}
+		listener.SetRemoteTargets(func() []notifier.CommandTarget {
+			return remoteCommandTargets(listener.Bot())
+		})
+		return listener
+	}
+	return nil
+}
+
+// setCommandBot records the Telegram bot the monitor takes commands from, so
+// that the process polling that bot can forward commands to it
+func (m *UnifiedMonitor) setCommandBot(bot string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.commandBot = bot
+}
+
+// Status returns the current state of the monitor
+func (m *UnifiedMonitor) Status() notifier.MonitorStatus {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return notifier.MonitorStatus{
+		Name:             m.config.DisplayName(),
+		Source:           m.config.SourceLabel(),
+		StartedAt:        m.startedAt,
+		Lines:            m.lines,
+		Notifications:    m.notifications,
+		LastNotification: m.lastNotification,
+		MutedUntil:       m.mutedUntil,
+	}
+}
+
+// defaultAckExpiry is how long an acknowledgement suppresses repeats of an
+// alert when no dedup window is configured
+const defaultAckExpiry = 24 * time.Hour
+
+// Ack acknowledges the latest alert so that repeats of it are not sent again
+// for the dedup window, or a day without one, and stops the escalation of the
+// monitor's pending alerts
+func (m *UnifiedMonitor) Ack() bool {
+	escalations := 0
+	for _, n := range m.currentNotifiers() {
+		if acknowledger, ok := n.(notifier.Acknowledger); ok {
+			escalations += acknowledger.Acknowledge(m.config.DisplayName())
+		}
+	}
+
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if m.lastSignature == "" && escalations == 0 {
+		return false
+	}
+	if m.lastSignature != "" {
+		if m.ackedSignatures == nil {
+			m.ackedSignatures = make(map[string]time.Time)
+		}
+		// Forget acknowledgements that expired, so the map does not keep growing
+		now, expiry := time.Now(), m.ackExpiry()
+		for signature, ackedAt := range m.ackedSignatures {
+			if now.Sub(ackedAt) >= expiry {
+				delete(m.ackedSignatures, signature)
+			}
+		}
+		m.ackedSignatures[m.lastSignature] = now
+	}
+	logger.Infof("Alert acknowledged for %s", m.config.DisplayName())
+	return true
+}
+
+// Mute suppresses all notifications of the monitor for the given duration
+func (m *UnifiedMonitor) Mute(d time.Duration) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.mutedUntil = time.Now().Add(d)
+	logger.Infof("Notifications for %s muted until %s", m.config.DisplayName(), m.mutedUntil.Format(time.DateTime))
+}
+
+// Unmute lifts a mute
+func (m *UnifiedMonitor) Unmute() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.mutedUntil = time.Time{}
+	logger.Infof("Notifications for %s unmuted", m.config.DisplayName())
+}
+
+// SummaryNow summarizes and sends the lines collected since the last summary
+// that pass the filters, even if they have not reached the line threshold.
+// The summary is sent while muted, since it was asked for. In
+// final-summary-only mode the held back activity is included and stays held
+// for the final summary. A paused monitor refuses, as its lines are held for
+// the maintenance digest.
+func (m *UnifiedMonitor) SummaryNow() (int, error) {
+	flusher, ok := m.collector.(PendingFlusher)
+	if !ok {
+		return 0, fmt.Errorf("this monitor cannot flush pending lines")
+	}
+	if m.isPaused() {
+		return 0, fmt.Errorf("monitoring is paused, new lines are held for the maintenance digest")
+	}
+
+	lines, err := flusher.FlushPending(func(newContent string, restarts []string) error {
+		newContent, held := m.admitBatch(newContent, restarts)
+		if held {
+			// Paused since the check above
+			return nil
+		}
+
+		content := newContent
+		if m.config.FinalSummaryOnly {
+			content = m.bufferedContent() + newContent
+		}
+		if countLines(content) == 0 {
+			logger.Info("No lines left after filtering, skipping summary")
+			return nil
+		}
+		if err := m.sendSummaryNow(content, restarts); err != nil {
+			return err
+		}
+		if m.config.FinalSummaryOnly && countLines(newContent) > 0 {
+			m.bufferBatch(newContent)
+		}
+		return nil
+	})
+	if err != nil || lines > 0 {
+		return lines, err
+	}
+
+	// Nothing new since the last batch, but final-summary-only mode may be
+	// holding earlier batches back
+	if m.config.FinalSummaryOnly && m.hasBufferedContent() {
+		content := m.bufferedContent()
+		return countLines(content), m.sendSummaryNow(content, nil)
+	}
+	return 0, nil
+}
+
+// sendSummaryNow summarizes content, listing the command restarts it covers,
+// and delivers it regardless of mutes
+func (m *UnifiedMonitor) sendSummaryNow(content string, restarts []string) error {
+	windowStart, windowEnd := m.summaryWindow(!m.config.FinalSummaryOnly)
+
+	summary, err := m.summarize(content)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	msg := newSummaryMessage(notifier.MessageTypeSummary, m.config.SourceLabel(), content, summary, batchSeverity(content), windowStart, windowEnd)
+	msg.Monitor = m.config.DisplayName()
+	addRestartNotes(msg, restarts)
+	m.recordSummary(msg)
+	return m.deliver(msg)
+}
+
+// suppressed reports whether a message must not be sent because the monitor
+// is muted or paused, or the alert was acknowledged, and remembers the latest
+// alert. While paused only summaries, such as a final summary, are sent.
+func (m *UnifiedMonitor) suppressed(msg *notifier.Message) bool {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if time.Now().Before(m.mutedUntil) {
+		logger.Infof("Notifications for %s are muted until %s, skipping", m.config.DisplayName(), m.mutedUntil.Format(time.DateTime))
+		return true
+	}
+	if m.pause != nil && msg.Type != notifier.MessageTypeSummary && msg.Type != notifier.MessageTypeFinalSummary {
+		logger.Infof("Monitoring of %s is paused, skipping %s notification", m.config.DisplayName(), msg.Type)
+		return true
+	}
+
+	if msg.Signature == "" || msg.Type == notifier.MessageTypeFinalSummary {
+		return false
+	}
+	if ackedAt, ok := m.ackedSignatures[msg.Signature]; ok {
+		if time.Since(ackedAt) < m.ackExpiry() {
+			logger.Info("Alert was acknowledged, skipping repeat")
+			return true
+		}
+		delete(m.ackedSignatures, msg.Signature)
+	}
+	m.lastSignature = msg.Signature
+	return false
+}
+
+// ackExpiry returns how long an acknowledgement suppresses repeats of an alert
+func (m *UnifiedMonitor) ackExpiry() time.Duration {
+	if m.config.DedupWindow > 0 {
+		return m.config.DedupWindow
+	}
+	return defaultAckExpiry
+}
+
+// recordNotification counts a message delivered to at least one notifier
+func (m *UnifiedMonitor) recordNotification() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.notifications++
+	m.lastNotification = time.Now()
+}
+
+// recordFailedNotification counts a message that no notifier accepted
+func (m *UnifiedMonitor) recordFailedNotification() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.counters.FailedNotifications++
+}
+
+// recordSummary keeps a summary or alert as the latest one of the monitor
+func (m *UnifiedMonitor) recordSummary(msg *notifier.Message) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if msg.Type == notifier.MessageTypeError {
+		m.counters.Alerts++
+	} else {
+		m.counters.Summaries++
+	}
+	m.lastSummary = &SummaryRecord{
+		Type:        string(msg.Type),
+		Severity:    msg.Severity,
+		Summary:     msg.Body,
+		Lines:       msg.LineCount,
+		WindowStart: msg.WindowStart,
+		WindowEnd:   msg.WindowEnd,
+		Time:        time.Now(),
+	}
+}
@@ -0,0 +1,253 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+	"github.com/shiquda/lai/internal/summarizer"
+)
+
+// Counters are the running totals of a monitor
+type Counters struct {
+	Lines               int `json:"lines"`                // Lines collected
+	FilteredLines       int `json:"filtered_lines"`       // Lines dropped by the include/exclude filters
+	PausedLines         int `json:"paused_lines"`         // Lines collected while paused
+	Batches             int `json:"batches"`              // Batches handled while not paused
+	Summaries           int `json:"summaries"`            // Summaries generated
+	Alerts              int `json:"alerts"`               // Error alerts raised
+	Notifications       int `json:"notifications"`        // Messages delivered to at least one notifier
+	FailedNotifications int `json:"failed_notifications"` // Messages no notifier accepted
+}
+
+// SummaryRecord is the latest summary or alert of a monitor
+type SummaryRecord struct {
+	Type        string    `json:"type"`
+	Severity    string    `json:"severity"`
+	Summary     string    `json:"summary"`
+	Lines       int       `json:"lines"`
+	WindowStart time.Time `json:"window_start,omitempty"`
+	WindowEnd   time.Time `json:"window_end,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// MonitorSnapshot is the state of a monitor reported by the control API
+type MonitorSnapshot struct {
+	Name             string    `json:"name"`
+	Type             string    `json:"type"`
+	Source           string    `json:"source"`
+	StartedAt        time.Time `json:"started_at"`
+	Paused           bool      `json:"paused"`
+	PausedUntil      time.Time `json:"paused_until,omitempty"`
+	MutedUntil       time.Time `json:"muted_until,omitempty"`
+	LastNotification time.Time `json:"last_notification,omitempty"`
+	Counters         Counters  `json:"counters"`
+	// CommandBot identifies the Telegram bot whose chat commands the monitor
+	// accepts, if any
+	CommandBot string `json:"command_bot,omitempty"`
+}
+
+// FlushResult reports how many lines a flush request summarized
+type FlushResult struct {
+	Lines int `json:"lines"`
+}
+
+// errControlStop is the cancel cause of a monitor stopped through the control API
+var errControlStop = errors.New("stopped through the control API")
+
+// HandleControl executes a control socket request. reload builds the
+// monitor's configuration again for the reload command.
+func (m *UnifiedMonitor) HandleControl(req control.Request, reload func() (*MonitorConfig, error)) (interface{}, error) {
+	switch req.Command {
+	case control.CommandStatus:
+		return m.Snapshot(), nil
+	case control.CommandCounters:
+		return m.Counters(), nil
+	case control.CommandPause:
+		if req.Duration < 0 {
+			return nil, fmt.Errorf("pause duration must not be negative")
+		}
+		m.Pause(req.Duration, req.Drop)
+		return m.Snapshot(), nil
+	case control.CommandResume:
+		if !m.Resume() {
+			return nil, fmt.Errorf("monitor is not paused")
+		}
+		return m.Snapshot(), nil
+	case control.CommandFlush:
+		lines, err := m.SummaryNow()
+		if err != nil {
+			return nil, err
+		}
+		return FlushResult{Lines: lines}, nil
+	case control.CommandReload:
+		if reload == nil {
+			return nil, fmt.Errorf("reload is not supported by this monitor")
+		}
+		cfg, err := reload()
+		if err != nil {
+			return nil, err
+		}
+		if err := m.Reload(cfg); err != nil {
+			return nil, err
+		}
+		return m.Snapshot(), nil
+	case control.CommandLastSummary:
+		record := m.LastSummary()
+		if record == nil {
+			return nil, fmt.Errorf("no summary has been generated yet")
+		}
+		return record, nil
+	case control.CommandAck:
+		if !m.Ack() {
+			return nil, fmt.Errorf("no alert to acknowledge")
+		}
+		return m.Snapshot(), nil
+	case control.CommandMute:
+		if req.Duration <= 0 {
+			return nil, fmt.Errorf("mute duration must be positive")
+		}
+		m.Mute(req.Duration)
+		return m.Snapshot(), nil
+	case control.CommandUnmute:
+		m.Unmute()
+		return m.Snapshot(), nil
+	case control.CommandStop:
+		// Answer before the monitor winds down
+		go m.Shutdown(errControlStop)
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q", req.Command)
+	}
+}
+
+// Snapshot returns the state and counters of the monitor
+func (m *UnifiedMonitor) Snapshot() MonitorSnapshot {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	snapshot := MonitorSnapshot{
+		Name:             m.config.DisplayName(),
+		Type:             string(m.config.Source.GetType()),
+		Source:           m.config.SourceLabel(),
+		StartedAt:        m.startedAt,
+		MutedUntil:       m.mutedUntil,
+		LastNotification: m.lastNotification,
+		Counters:         m.countersLocked(),
+		CommandBot:       m.commandBot,
+	}
+	if m.pause != nil {
+		snapshot.Paused = true
+		snapshot.PausedUntil = m.pause.until
+	}
+	return snapshot
+}
+
+// Counters returns the running totals of the monitor
+func (m *UnifiedMonitor) Counters() Counters {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	return m.countersLocked()
+}
+
+// countersLocked returns the counters; the caller holds stateMutex
+func (m *UnifiedMonitor) countersLocked() Counters {
+	counters := m.counters
+	counters.Lines = m.lines
+	counters.Notifications = m.notifications
+	return counters
+}
+
+// LastSummary returns the latest summary or alert, or nil if there is none yet
+func (m *UnifiedMonitor) LastSummary() *SummaryRecord {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if m.lastSummary == nil {
+		return nil
+	}
+	record := *m.lastSummary
+	return &record
+}
+
+// Shutdown stops a running monitor as if its context was cancelled with cause
+func (m *UnifiedMonitor) Shutdown(cause error) {
+	m.stateMutex.Lock()
+	cancel := m.cancel
+	m.stateMutex.Unlock()
+
+	if cancel != nil {
+		cancel(cause)
+	}
+}
+
+// Reload applies the notification providers, AI settings, language and
+// prompt templates of cfg. The source, thresholds and filters of a running
+// monitor only change when it is restarted.
+func (m *UnifiedMonitor) Reload(cfg *MonitorConfig) error {
+	key := notifierSetKey(cfg)
+	var client *summarizer.OpenAIClient
+	var notifiers []notifier.Notifier
+	var err error
+	if m.shared != nil {
+		client = m.shared.summarizer(cfg.OpenAI)
+		notifiers, err = m.shared.notifiersFor(cfg)
+	} else {
+		client = summarizer.NewOpenAIClient(cfg.OpenAI.APIKey, cfg.OpenAI.BaseURL, cfg.OpenAI.Model)
+		if key == m.notifierSettings() {
+			// Keep unchanged notifiers, and with them pending escalations
+			notifiers = m.currentNotifiers()
+		} else {
+			notifiers, err = createNotifiers(cfg)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create notifiers: %w", err)
+	}
+
+	m.settingsMutex.Lock()
+	previous := m.notifiers
+	m.summarizer = client
+	m.notifiers = notifiers
+	m.notifierKey = key
+	m.config.Language = cfg.Language
+	m.config.PromptTemplates = cfg.PromptTemplates
+	m.settingsMutex.Unlock()
+
+	// Shared notifiers are closed by their owner
+	if m.shared == nil && !sameNotifierSet(notifiers, previous) {
+		for _, n := range previous {
+			if err := n.Close(); err != nil {
+				logger.Errorf("Failed to close %s notifier: %v", n.Name(), err)
+			}
+		}
+	}
+
+	logger.Infof("Configuration of %s reloaded", m.config.DisplayName())
+	return nil
+}
+
+// notifierSettings returns the key of the configuration the notifiers were created from
+func (m *UnifiedMonitor) notifierSettings() string {
+	m.settingsMutex.RLock()
+	defer m.settingsMutex.RUnlock()
+	return m.notifierKey
+}
+
+// currentNotifiers returns the notifiers messages are sent to
+func (m *UnifiedMonitor) currentNotifiers() []notifier.Notifier {
+	m.settingsMutex.RLock()
+	defer m.settingsMutex.RUnlock()
+	return m.notifiers
+}
+
+// analysisSettings returns the summarizer, language and prompt templates
+func (m *UnifiedMonitor) analysisSettings() (*summarizer.OpenAIClient, string, config.PromptTemplatesConfig) {
+	m.settingsMutex.RLock()
+	defer m.settingsMutex.RUnlock()
+	return m.summarizer, m.config.Language, m.config.PromptTemplates
+}
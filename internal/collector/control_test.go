@@ -0,0 +1,302 @@
+package collector
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/notifier"
+)
+
+func TestHandleControlPauseHoldsLinesForDigest(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), FinalSummaryOnly: true}}
+
+	result, err := m.HandleControl(control.Request{Command: control.CommandPause}, nil)
+	if err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	if snapshot := result.(MonitorSnapshot); !snapshot.Paused || !snapshot.PausedUntil.IsZero() || snapshot.Name != "api" || snapshot.Type != "file" {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+
+	if err := m.handleBatch("line1\nline2\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	if m.hasBufferedContent() {
+		t.Error("Lines collected while paused should not be summarized")
+	}
+
+	// Final-summary-only mode keeps the maintenance digest for the final summary
+	if _, err := m.HandleControl(control.Request{Command: control.CommandResume}, nil); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !m.hasBufferedContent() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if content := m.bufferedContent(); !strings.Contains(content, "line1\nline2\n") {
+		t.Errorf("Expected the paused lines in the digest, got %q", content)
+	}
+	if _, err := m.HandleControl(control.Request{Command: control.CommandResume}, nil); err == nil {
+		t.Error("Expected resuming a running monitor to fail")
+	}
+
+	if err := m.handleBatch("line3\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	result, err = m.HandleControl(control.Request{Command: control.CommandCounters}, nil)
+	if err != nil {
+		t.Fatalf("counters failed: %v", err)
+	}
+	counters := result.(Counters)
+	if counters.Lines != 3 || counters.PausedLines != 2 || counters.Batches != 1 {
+		t.Errorf("Unexpected counters %+v", counters)
+	}
+}
+
+func TestPauseDropAndExpiry(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), FinalSummaryOnly: true}}
+
+	if _, err := m.HandleControl(control.Request{Command: control.CommandPause, Duration: -time.Second}, nil); err == nil {
+		t.Error("Expected a negative duration to fail")
+	}
+
+	m.Pause(time.Hour, true)
+	if err := m.handleBatch("dropped\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+
+	// Pausing again replaces the duration and ends the pause on its own
+	m.Pause(20*time.Millisecond, true)
+	if snapshot := m.Snapshot(); !snapshot.Paused || snapshot.PausedUntil.IsZero() {
+		t.Fatalf("Expected a timed pause, got %+v", snapshot)
+	}
+	deadline := time.Now().Add(time.Second)
+	for m.Snapshot().Paused && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if m.Snapshot().Paused {
+		t.Fatal("Expected the pause to expire")
+	}
+	if m.hasBufferedContent() {
+		t.Error("Expected dropped lines not to be summarized")
+	}
+	if counters := m.Counters(); counters.PausedLines != 1 {
+		t.Errorf("Expected the dropped line to be counted, got %+v", counters)
+	}
+}
+
+func TestHandleControlLastSummary(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log")}}
+
+	if _, err := m.HandleControl(control.Request{Command: control.CommandLastSummary}, nil); err == nil {
+		t.Error("Expected an error before the first summary")
+	}
+
+	start := time.Now().Add(-time.Minute)
+	m.recordSummary(newSummaryMessage(notifier.MessageTypeError, "/var/log/app.log", "ERROR db\n", "Database down", notifier.SeverityError, start, time.Now()))
+
+	result, err := m.HandleControl(control.Request{Command: control.CommandLastSummary}, nil)
+	if err != nil {
+		t.Fatalf("last-summary failed: %v", err)
+	}
+	record := result.(*SummaryRecord)
+	if record.Type != string(notifier.MessageTypeError) || record.Summary != "Database down" || record.Lines != 1 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if counters := m.Counters(); counters.Alerts != 1 || counters.Summaries != 0 {
+		t.Errorf("Expected the alert to be counted, got %+v", counters)
+	}
+
+	if _, err := m.HandleControl(control.Request{Command: "bogus"}, nil); err == nil {
+		t.Error("Expected an unknown command to fail")
+	}
+	if _, err := m.HandleControl(control.Request{Command: control.CommandReload}, nil); err == nil {
+		t.Error("Expected reload without a loader to fail")
+	}
+}
+
+func TestPauseHoldsOnlyFilteredLines(t *testing.T) {
+	filter, err := NewLineFilter(nil, []string{"DEBUG"})
+	if err != nil {
+		t.Fatalf("NewLineFilter failed: %v", err)
+	}
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), FinalSummaryOnly: true}, filter: filter}
+
+	m.Pause(0, false)
+	if err := m.handleBatch("DEBUG noise\nERROR db\nDEBUG more\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	if snapshot := m.Snapshot(); !snapshot.Paused {
+		t.Fatalf("Expected a paused monitor, got %+v", snapshot)
+	}
+	m.stateMutex.Lock()
+	heldLines, heldContent := m.pause.lines, m.pause.content.String()
+	m.stateMutex.Unlock()
+	if heldLines != 1 || heldContent != "ERROR db\n" {
+		t.Errorf("Expected only the filtered line to be held, got %d %q", heldLines, heldContent)
+	}
+
+	if counters := m.Counters(); counters.PausedLines != 3 || counters.FilteredLines != 2 || counters.Batches != 0 {
+		t.Errorf("Unexpected counters %+v", counters)
+	}
+}
+
+func TestHandleControlAckAndMute(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log")}}
+
+	if _, err := m.HandleControl(control.Request{Command: control.CommandAck}, nil); err == nil {
+		t.Error("Expected nothing to acknowledge before the first alert")
+	}
+	m.suppressed(&notifier.Message{Type: notifier.MessageTypeError, Signature: "sig-1"})
+	if _, err := m.HandleControl(control.Request{Command: control.CommandAck}, nil); err != nil {
+		t.Errorf("ack failed: %v", err)
+	}
+
+	if _, err := m.HandleControl(control.Request{Command: control.CommandMute}, nil); err == nil {
+		t.Error("Expected a mute without a duration to fail")
+	}
+	result, err := m.HandleControl(control.Request{Command: control.CommandMute, Duration: time.Hour}, nil)
+	if err != nil {
+		t.Fatalf("mute failed: %v", err)
+	}
+	if snapshot := result.(MonitorSnapshot); snapshot.MutedUntil.IsZero() {
+		t.Errorf("Expected a muted monitor, got %+v", snapshot)
+	}
+	result, err = m.HandleControl(control.Request{Command: control.CommandUnmute}, nil)
+	if err != nil {
+		t.Fatalf("unmute failed: %v", err)
+	}
+	if snapshot := result.(MonitorSnapshot); !snapshot.MutedUntil.IsZero() {
+		t.Errorf("Expected the mute to be lifted, got %+v", snapshot)
+	}
+}
+
+func TestPauseHoldsRestartsOutsideContent(t *testing.T) {
+	filter, err := NewLineFilter([]string{"ERROR"}, nil)
+	if err != nil {
+		t.Fatalf("NewLineFilter failed: %v", err)
+	}
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), FinalSummaryOnly: true}, filter: filter}
+
+	m.Pause(0, false)
+	restarts := []string{"12:00:00: command exited with code 1 and was restarted (restart 1)"}
+	if err := m.handleBatch("INFO ready\n", restarts); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	m.stateMutex.Lock()
+	heldLines, heldContent, heldRestarts := m.pause.lines, m.pause.content.String(), m.pause.restarts
+	m.stateMutex.Unlock()
+	if heldLines != 0 || heldContent != "" {
+		t.Errorf("Expected no lines held, got %d %q", heldLines, heldContent)
+	}
+	if len(heldRestarts) != 1 || heldRestarts[0] != restarts[0] {
+		t.Errorf("Expected the restart to be held for the digest, got %v", heldRestarts)
+	}
+	if counters := m.Counters(); counters.FilteredLines != 1 {
+		t.Errorf("Unexpected counters %+v", counters)
+	}
+}
+
+func TestFlushRefusedWhilePaused(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	if err := os.WriteFile(path, []byte("line1\nline2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource(path)}, collector: New(path, 10, time.Second)}
+
+	m.Pause(0, false)
+	if _, err := m.HandleControl(control.Request{Command: control.CommandFlush}, nil); err == nil {
+		t.Error("Expected a flush of a paused monitor to fail")
+	}
+	if lines, err := m.collector.(PendingFlusher).FlushPending(func(string, []string) error { return nil }); err != nil || lines != 2 {
+		t.Errorf("Expected the lines to be left for the maintenance digest, got %d (%v)", lines, err)
+	}
+}
+
+// countingNotifier counts the structured messages sent to it
+type countingNotifier struct {
+	sent []*notifier.Message
+}
+
+func (c *countingNotifier) Name() string                     { return "counting" }
+func (c *countingNotifier) SendMessage(string) error         { return nil }
+func (c *countingNotifier) SendLogSummary(_, _ string) error { return nil }
+func (c *countingNotifier) Close() error                     { return nil }
+func (c *countingNotifier) Send(msg *notifier.Message) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func TestPauseHoldsAlerts(t *testing.T) {
+	lifecycle := true
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:    &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), ExpectActivityWithin: time.Minute, Events: config.EventsConfig{Lifecycle: &lifecycle}},
+		notifiers: []notifier.Notifier{counting},
+	}
+
+	m.Pause(0, false)
+	m.handleSilent(2 * time.Minute)
+	m.handleExit(ExitInfo{Command: "worker", ExitCode: 1, Duration: time.Second})
+	m.notifyLifecycle(notifier.LifecycleCrashed, notifier.SeverityError, "boom")
+	if len(counting.sent) != 0 {
+		t.Errorf("Expected no alerts while paused, got %d", len(counting.sent))
+	}
+
+	m.Resume()
+	m.handleSilent(2 * time.Minute)
+	if len(counting.sent) != 1 {
+		t.Errorf("Expected alerts again after resuming, got %d", len(counting.sent))
+	}
+}
+
+func TestFlushRatesSummaryBySeverity(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	if err := os.WriteFile(path, []byte("ERROR db unreachable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:     &MonitorConfig{Name: "api", Source: NewFileSource(path)},
+		collector:  New(path, 10, time.Second),
+		summarizer: newTestSummarizer(t),
+		notifiers:  []notifier.Notifier{counting},
+	}
+
+	if _, err := m.HandleControl(control.Request{Command: control.CommandFlush}, nil); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if len(counting.sent) != 1 || counting.sent[0].Severity != notifier.SeverityError {
+		t.Errorf("Expected one error summary, got %+v", counting.sent)
+	}
+}
+
+func TestMaintenanceDigestRatedBySeverity(t *testing.T) {
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:     &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log")},
+		summarizer: newTestSummarizer(t),
+		notifiers:  []notifier.Notifier{counting},
+	}
+
+	m.Pause(0, false)
+	if err := m.handleBatch("ERROR db unreachable\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	m.Resume()
+
+	deadline := time.Now().Add(time.Second)
+	for m.Counters().Summaries == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	m.stateMutex.Lock()
+	record := m.lastSummary
+	m.stateMutex.Unlock()
+	if record == nil || record.Severity != notifier.SeverityError {
+		t.Errorf("Expected an error digest, got %+v", record)
+	}
+}
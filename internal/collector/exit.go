@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// lastStderrLines is how many trailing stderr lines an exit report keeps
+const lastStderrLines = 10
+
+// ExitInfo describes how a monitored command ended
+type ExitInfo struct {
+	Command string
+	// ExitCode is the process exit code. A process killed by a signal reports
+	// 128 plus the signal number, as shells do.
+	ExitCode      int
+	Signal        string // Name of the signal that killed the process, if any
+	Duration      time.Duration
+	TotalLines    int
+	PeakLineRate  int      // Highest number of output lines in one second
+	LastStderr    []string // Trailing stderr lines, oldest first
+	StoppedByUser bool     // The process was killed by lai stop or Ctrl+C
+	Restarts      int      // Times the command was relaunched under its restart policy
+}
+
+// Failed reports whether the command ended on its own with a non-zero exit code or a signal
+func (e ExitInfo) Failed() bool {
+	return !e.StoppedByUser && (e.ExitCode != 0 || e.Signal != "")
+}
+
+// Status returns a short description of how the command ended
+func (e ExitInfo) Status() string {
+	switch {
+	case e.StoppedByUser:
+		return "stopped by user"
+	case e.Signal != "":
+		return fmt.Sprintf("killed by signal %s (exit code %d)", e.Signal, e.ExitCode)
+	case e.ExitCode != 0:
+		return fmt.Sprintf("exited with code %d", e.ExitCode)
+	default:
+		return "exited successfully"
+	}
+}
+
+// ExitError is returned by StreamCollector.Start when the command fails
+type ExitError struct {
+	Info ExitInfo
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command %s", e.Info.Status())
+}
+
+// exitStatus extracts the exit code and signal name from the result of exec.Cmd.Wait.
+// Failures without an exit status map to the codes shells use: 127 when the
+// command was not found and 1 otherwise.
+func exitStatus(waitErr error) (code int, signal string) {
+	if waitErr == nil {
+		return 0, ""
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(waitErr, &exitErr) {
+		if errors.Is(waitErr, exec.ErrNotFound) || errors.Is(waitErr, fs.ErrNotExist) {
+			return 127, ""
+		}
+		return 1, ""
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), status.Signal().String()
+	}
+	return exitErr.ExitCode(), ""
+}
+
+// lastStderr returns up to limit trailing stderr lines from collected output
+func lastStderr(lines []string, limit int) []string {
+	var result []string
+	for i := len(lines) - 1; i >= 0 && len(result) < limit; i-- {
+		if text, ok := strings.CutPrefix(lines[i], "[stderr] "); ok {
+			result = append(result, text)
+		}
+	}
+
+	// Restore chronological order
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestLastStderr(t *testing.T) {
+	lines := []string{"[stderr] a", "[stdout] b", "[stderr] c", "[stderr] d"}
+
+	if got := lastStderr(lines, 2); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("Expected [c d], got %v", got)
+	}
+	if got := lastStderr(lines, 10); !reflect.DeepEqual(got, []string{"a", "c", "d"}) {
+		t.Errorf("Expected [a c d], got %v", got)
+	}
+	if got := lastStderr([]string{"[stdout] x"}, 10); len(got) != 0 {
+		t.Errorf("Expected no stderr lines, got %v", got)
+	}
+}
+
+func TestExitInfoStatus(t *testing.T) {
+	tests := []struct {
+		info   ExitInfo
+		failed bool
+		status string
+	}{
+		{ExitInfo{}, false, "exited successfully"},
+		{ExitInfo{ExitCode: 2}, true, "exited with code 2"},
+		{ExitInfo{ExitCode: 137, Signal: "killed"}, true, "killed by signal killed (exit code 137)"},
+		{ExitInfo{ExitCode: 137, Signal: "killed", StoppedByUser: true}, false, "stopped by user"},
+	}
+
+	for _, tt := range tests {
+		if tt.info.Failed() != tt.failed || tt.info.Status() != tt.status {
+			t.Errorf("%+v: got failed=%v status=%q", tt.info, tt.info.Failed(), tt.info.Status())
+		}
+	}
+}
+
+func TestExitStatusWithoutExitCode(t *testing.T) {
+	notFound := fmt.Errorf("failed to start command: %w", &exec.Error{Name: "nope", Err: exec.ErrNotFound})
+	if code, signal := exitStatus(notFound); code != 127 || signal != "" {
+		t.Errorf("Expected 127 for a missing command, got %d %q", code, signal)
+	}
+	if code, _ := exitStatus(errors.New("broken pipe")); code != 1 {
+		t.Errorf("Expected 1 for other failures, got %d", code)
+	}
+	if code, _ := exitStatus(nil); code != 0 {
+		t.Errorf("Expected 0 without an error, got %d", code)
+	}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LineFilter selects the log lines of a batch that are summarized. With
+// include patterns only matching lines are kept; lines matching an exclude
+// pattern are always dropped.
+type LineFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewLineFilter compiles include and exclude patterns. It returns nil when
+// there are none, which keeps every line.
+func NewLineFilter(include, exclude []string) (*LineFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	filter := &LineFilter{}
+	var err error
+	if filter.include, err = compilePatterns(include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compilePatterns(exclude); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// compilePatterns compiles a list of regular expressions
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Apply returns the lines of content the filter keeps. A nil filter keeps
+// everything.
+func (f *LineFilter) Apply(content string) string {
+	if f == nil {
+		return content
+	}
+
+	var kept strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		if f.keeps(strings.TrimSuffix(line, "\n")) {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
+
+// keeps reports whether a single line passes the filter
+func (f *LineFilter) keeps(line string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(line) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package collector
+
+import "testing"
+
+func TestLineFilter(t *testing.T) {
+	content := "GET /health 200\nERROR db timeout\nWARN slow query\nERROR healthcheck failed\n"
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected string
+	}{
+		{"no patterns", nil, nil, content},
+		{"include", []string{"^ERROR"}, nil, "ERROR db timeout\nERROR healthcheck failed\n"},
+		{"exclude", nil, []string{"health"}, "ERROR db timeout\nWARN slow query\n"},
+		{"exclude wins", []string{"ERROR", "WARN"}, []string{"healthcheck"}, "ERROR db timeout\nWARN slow query\n"},
+		{"nothing kept", []string{"FATAL"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewLineFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewLineFilter failed: %v", err)
+			}
+			if got := filter.Apply(content); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewLineFilterInvalidPattern(t *testing.T) {
+	if _, err := NewLineFilter(nil, []string{"("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+)
+
+// pauseBufferLimit is how much of the content collected while paused is kept
+// for the maintenance digest; older lines are dropped first
+const pauseBufferLimit = 32 * 1024
+
+// pauseState is a pause of a monitor and the lines collected during it
+type pauseState struct {
+	since time.Time
+	until time.Time // Zero while paused until resumed
+	drop  bool
+	timer *time.Timer
+
+	lines    int
+	content  strings.Builder
+	restarts []string
+}
+
+// hold collects the lines of a batch, and the command restarts it covers,
+// received while paused
+func (p *pauseState) hold(content string, lines int, restarts []string) {
+	p.lines += lines
+	p.restarts = append(p.restarts, restarts...)
+	if p.drop {
+		return
+	}
+	p.content.WriteString(content)
+	if p.content.Len() > pauseBufferLimit {
+		recent := tailContent(p.content.String(), pauseBufferLimit)
+		p.content.Reset()
+		p.content.WriteString(recent)
+	}
+}
+
+// Pause stops summarizing for d, or until Resume when d is zero. Lines are
+// still collected and counted, and unless drop is set they are summarized as
+// one maintenance digest on resume. Pausing a paused monitor replaces the
+// duration and drop setting, keeping the lines held so far.
+func (m *UnifiedMonitor) Pause(d time.Duration, drop bool) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	pause := &pauseState{since: time.Now(), drop: drop}
+	if previous := m.pause; previous != nil {
+		if previous.timer != nil {
+			previous.timer.Stop()
+		}
+		pause.since = previous.since
+		pause.lines = previous.lines
+		pause.restarts = previous.restarts
+		if !drop {
+			pause.content.WriteString(previous.content.String())
+		}
+	}
+	if d > 0 {
+		pause.until = time.Now().Add(d)
+		pause.timer = time.AfterFunc(d, func() { m.endPause(pause) })
+	}
+	m.pause = pause
+
+	if d > 0 {
+		logger.Infof("Monitoring of %s paused until %s", m.config.DisplayName(), pause.until.Format(time.DateTime))
+	} else {
+		logger.Infof("Monitoring of %s paused", m.config.DisplayName())
+	}
+}
+
+// Resume summarizes new lines again after Pause and sends the maintenance
+// digest in the background. It returns false if the monitor was not paused.
+func (m *UnifiedMonitor) Resume() bool {
+	return m.endPause(nil)
+}
+
+// endPause ends the current pause, or only the given one when expected is set
+// (a timed pause expiring after it was replaced must not end its successor)
+func (m *UnifiedMonitor) endPause(expected *pauseState) bool {
+	m.stateMutex.Lock()
+	pause := m.pause
+	if pause == nil || (expected != nil && pause != expected) {
+		m.stateMutex.Unlock()
+		return false
+	}
+	m.pause = nil
+	if pause.timer != nil {
+		pause.timer.Stop()
+	}
+	m.stateMutex.Unlock()
+
+	logger.Infof("Monitoring of %s resumed after %v", m.config.DisplayName(), time.Since(pause.since).Round(time.Second))
+	// Summarizing may take a while; a resume request is answered first
+	go func() {
+		if err := m.sendMaintenanceDigest(pause); err != nil {
+			logger.Errorf("Failed to send maintenance digest: %v", err)
+		}
+	}()
+	return true
+}
+
+// isPaused reports whether the monitor is paused
+func (m *UnifiedMonitor) isPaused() bool {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	return m.pause != nil
+}
+
+// stopPauseTimer keeps a timed pause from expiring, e.g. once the monitor stopped
+func (m *UnifiedMonitor) stopPauseTimer() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if m.pause != nil && m.pause.timer != nil {
+		m.pause.timer.Stop()
+	}
+}
+
+// sendMaintenanceDigest summarizes the lines collected during a pause as one
+// "during maintenance" summary. In final-summary-only mode they are held for
+// the final summary instead.
+func (m *UnifiedMonitor) sendMaintenanceDigest(pause *pauseState) error {
+	if pause.lines == 0 {
+		return nil
+	}
+	if pause.drop || pause.content.Len() == 0 {
+		logger.Infof("Dropped %d lines collected while paused", pause.lines)
+		return nil
+	}
+
+	content := pause.content.String()
+	if m.config.FinalSummaryOnly {
+		m.bufferBatch(content)
+		return nil
+	}
+	if !m.config.Events.SummaryEnabled() {
+		logger.Info("Batch summaries are disabled, skipping maintenance digest")
+		return nil
+	}
+
+	logger.Infof("Generating maintenance digest of %d lines...", pause.lines)
+	summary, err := m.summarize(content)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	pausedFor := time.Since(pause.since).Round(time.Second)
+	msg := newSummaryMessage(notifier.MessageTypeSummary, m.config.SourceLabel(), content, summary, batchSeverity(content), pause.since, time.Now())
+	msg.Title = "🛠️ During Maintenance"
+	msg.LineCount = pause.lines
+	msg.Details = []notifier.MessageField{
+		{Name: "Paused for", Value: pausedFor.String()},
+		{Name: "Lines", Value: strconv.Itoa(pause.lines)},
+	}
+	addRestartNotes(msg, pause.restarts)
+	return m.sendToAllNotifiers(msg)
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"os"
+	"time"
+
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+)
+
+// remoteTarget is a monitor of another lai process, controlled through the
+// control socket of that process. Chat commands for it reach the process
+// that polls the bot.
+type remoteTarget struct {
+	socket  string
+	monitor string // Set for monitors of the agent
+	status  notifier.MonitorStatus
+}
+
+func (r *remoteTarget) Status() notifier.MonitorStatus {
+	return r.status
+}
+
+func (r *remoteTarget) Ack() bool {
+	return r.call(control.Request{Command: control.CommandAck}, nil) == nil
+}
+
+func (r *remoteTarget) Mute(d time.Duration) {
+	if err := r.call(control.Request{Command: control.CommandMute, Duration: d}, nil); err != nil {
+		logger.Warnf("Failed to mute %s: %v", r.status.Name, err)
+	}
+}
+
+func (r *remoteTarget) Unmute() {
+	if err := r.call(control.Request{Command: control.CommandUnmute}, nil); err != nil {
+		logger.Warnf("Failed to unmute %s: %v", r.status.Name, err)
+	}
+}
+
+func (r *remoteTarget) SummaryNow() (int, error) {
+	var result FlushResult
+	err := r.call(control.Request{Command: control.CommandFlush}, &result)
+	return result.Lines, err
+}
+
+// call sends a request for the monitor to its process
+func (r *remoteTarget) call(req control.Request, result interface{}) error {
+	req.Monitor = r.monitor
+	return control.Call(r.socket, req, result)
+}
+
+// remoteCommandTargets returns the monitors of the daemons and the agent that
+// take commands from the given Telegram bot
+func remoteCommandTargets(bot string) []notifier.CommandTarget {
+	manager, err := daemon.NewManager()
+	if err != nil {
+		logger.Warnf("Failed to look up monitors of other processes: %v", err)
+		return nil
+	}
+	return findCommandTargets(manager, bot)
+}
+
+// findCommandTargets asks every running daemon and the agent for the
+// monitors that take commands from bot. Processes that do not answer are
+// skipped.
+func findCommandTargets(manager *daemon.Manager, bot string) []notifier.CommandTarget {
+	var targets []notifier.CommandTarget
+	add := func(socket, monitor string) {
+		var snapshot MonitorSnapshot
+		req := control.Request{Command: control.CommandStatus, Monitor: monitor}
+		if err := control.Call(socket, req, &snapshot); err != nil || snapshot.CommandBot != bot {
+			return
+		}
+		targets = append(targets, &remoteTarget{socket: socket, monitor: monitor, status: snapshot.commandStatus()})
+	}
+
+	processes, err := manager.ListProcesses()
+	if err != nil {
+		logger.Warnf("Failed to list daemons: %v", err)
+	}
+	for _, info := range processes {
+		if info.Status == "running" && info.PID != os.Getpid() {
+			add(manager.ControlSocketPath(info.ID), "")
+		}
+	}
+
+	var monitors []struct {
+		Name string `json:"name"`
+	}
+	if err := control.Call(manager.AgentSocketPath(), control.Request{Command: control.CommandStatus}, &monitors); err == nil {
+		for _, monitor := range monitors {
+			add(manager.AgentSocketPath(), monitor.Name)
+		}
+	}
+	return targets
+}
+
+// commandStatus converts a snapshot to the status shown by chat commands
+func (s MonitorSnapshot) commandStatus() notifier.MonitorStatus {
+	return notifier.MonitorStatus{
+		Name:             s.Name,
+		Source:           s.Source,
+		StartedAt:        s.StartedAt,
+		Lines:            s.Counters.Lines,
+		Notifications:    s.Counters.Notifications,
+		LastNotification: s.LastNotification,
+		MutedUntil:       s.MutedUntil,
+	}
+}
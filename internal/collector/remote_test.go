@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/control"
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+func TestFindCommandTargetsForwardsToOtherProcesses(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := daemon.NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var requests []control.Request
+	serve := func(id, bot string) {
+		if err := manager.SaveProcessInfo(&daemon.ProcessInfo{ID: id, PID: 1, Status: "running"}); err != nil {
+			t.Fatalf("Failed to save process: %v", err)
+		}
+		server, err := control.Listen(manager.ControlSocketPath(id), func(req control.Request) (interface{}, error) {
+			requests = append(requests, req)
+			if req.Command == control.CommandFlush {
+				return FlushResult{Lines: 3}, nil
+			}
+			return MonitorSnapshot{Name: id, Source: id + ".log", CommandBot: bot, Counters: Counters{Lines: 5}}, nil
+		})
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		go server.Serve()
+		t.Cleanup(func() { server.Close() })
+	}
+	serve("worker", "bot-a")
+	serve("billing", "bot-b")
+
+	targets := findCommandTargets(manager, "bot-a")
+	if len(targets) != 1 {
+		t.Fatalf("Expected only the monitor of the same bot, got %d", len(targets))
+	}
+	if status := targets[0].Status(); status.Name != "worker" || status.Lines != 5 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	requests = nil
+	targets[0].Mute(time.Hour)
+	if lines, err := targets[0].SummaryNow(); err != nil || lines != 3 {
+		t.Errorf("Expected 3 lines summarized, got %d (%v)", lines, err)
+	}
+	if len(requests) != 2 || requests[0].Command != control.CommandMute || requests[0].Duration != time.Hour || requests[1].Command != control.CommandFlush {
+		t.Errorf("Unexpected requests %+v", requests)
+	}
+}
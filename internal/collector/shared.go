@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/notifier"
+	"github.com/shiquda/lai/internal/summarizer"
+)
+
+// SharedResources is used by monitors running in the same process to share
+// one summarizer client, one set of notifiers per provider selection (with
+// their rate limiters, dedup windows and outbox) and one Telegram command
+// listener
+type SharedResources struct {
+	mutex       sync.Mutex
+	summarizers map[config.OpenAIConfig]*summarizer.OpenAIClient
+	notifiers   map[string][]notifier.Notifier
+
+	// During a reload, the sets created before it that no monitor has reused yet
+	previous map[string][]notifier.Notifier
+	// Replaced sets still used by a monitor, e.g. one that failed to reload
+	retired [][]notifier.Notifier
+
+	listener       *notifier.TelegramCommandListener
+	listenerCancel context.CancelFunc
+}
+
+// NewSharedResources creates an empty set of shared resources. Resources are
+// created on first use.
+func NewSharedResources() *SharedResources {
+	return &SharedResources{
+		summarizers: make(map[config.OpenAIConfig]*summarizer.OpenAIClient),
+		notifiers:   make(map[string][]notifier.Notifier),
+	}
+}
+
+// summarizer returns the summarizer client for an OpenAI configuration
+func (s *SharedResources) summarizer(cfg config.OpenAIConfig) *summarizer.OpenAIClient {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	client, ok := s.summarizers[cfg]
+	if !ok {
+		client = summarizer.NewOpenAIClient(cfg.APIKey, cfg.BaseURL, cfg.Model)
+		s.summarizers[cfg] = client
+	}
+	return client
+}
+
+// notifiersFor returns the notifiers of a monitor. Monitors selecting the same
+// providers share them, so rate limits and dedup apply across monitors.
+func (s *SharedResources) notifiersFor(cfg *MonitorConfig) ([]notifier.Notifier, error) {
+	key := notifierSetKey(cfg)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if notifiers, ok := s.notifiers[key]; ok {
+		return notifiers, nil
+	}
+	// An unchanged set is carried over a reload with its pending escalations
+	if notifiers, ok := s.previous[key]; ok {
+		delete(s.previous, key)
+		s.notifiers[key] = notifiers
+		return notifiers, nil
+	}
+
+	notifiers, err := createNotifiers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.notifiers[key] = notifiers
+	return notifiers, nil
+}
+
+// commandListener returns the shared Telegram command listener if the
+// monitor's notifiers have commands enabled, starting it on first use
+func (s *SharedResources) commandListener(m *UnifiedMonitor) *notifier.TelegramCommandListener {
+	listener := m.newCommandListener()
+	if listener == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listener == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.listener, s.listenerCancel = listener, cancel
+		go listener.Run(ctx)
+	}
+	return s.listener
+}
+
+// Reset drops the summarizer clients and notifiers so that monitors
+// reloading their configuration create them again; notifiers whose
+// configuration did not change are reused. It returns a function to call with
+// the monitors once they have reloaded, which closes the previous notifiers
+// none of them uses any more. The command listener keeps running.
+func (s *SharedResources) Reset() (release func(monitors []*UnifiedMonitor)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.previous = s.notifiers
+	s.notifiers = make(map[string][]notifier.Notifier)
+	s.summarizers = make(map[config.OpenAIConfig]*summarizer.OpenAIClient)
+	return s.release
+}
+
+// release closes the sets replaced by a reload unless a monitor still uses
+// them. Those are closed by a later reload or by Close.
+func (s *SharedResources) release(monitors []*UnifiedMonitor) {
+	inUse := make([][]notifier.Notifier, 0, len(monitors))
+	for _, m := range monitors {
+		inUse = append(inUse, m.currentNotifiers())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replaced := s.retired
+	for _, notifiers := range s.previous {
+		replaced = append(replaced, notifiers)
+	}
+	s.previous = nil
+	s.retired = nil
+
+	for _, notifiers := range replaced {
+		if containsNotifierSet(inUse, notifiers) {
+			s.retired = append(s.retired, notifiers)
+			continue
+		}
+		closeNotifierSet(notifiers)
+	}
+}
+
+// Close stops the command listener and flushes and closes all notifiers
+func (s *SharedResources) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listenerCancel != nil {
+		s.listenerCancel()
+	}
+	closeNotifiers(s.notifiers)
+	closeNotifiers(s.previous)
+	for _, notifiers := range s.retired {
+		closeNotifierSet(notifiers)
+	}
+	s.notifiers = make(map[string][]notifier.Notifier)
+	s.previous = nil
+	s.retired = nil
+}
+
+// closeNotifiers flushes and closes every notifier of a set
+func closeNotifiers(sets map[string][]notifier.Notifier) {
+	for _, notifiers := range sets {
+		closeNotifierSet(notifiers)
+	}
+}
+
+// closeNotifierSet flushes and closes the notifiers of one set
+func closeNotifierSet(notifiers []notifier.Notifier) {
+	for _, n := range notifiers {
+		if err := n.Close(); err != nil {
+			logger.Errorf("Failed to close %s notifier: %v", n.Name(), err)
+		}
+	}
+}
+
+// containsNotifierSet reports whether sets holds the given set itself, not
+// merely an equal one
+func containsNotifierSet(sets [][]notifier.Notifier, set []notifier.Notifier) bool {
+	for _, candidate := range sets {
+		if sameNotifierSet(candidate, set) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameNotifierSet reports whether a and b are the same set of notifiers
+func sameNotifierSet(a, b []notifier.Notifier) bool {
+	return len(a) > 0 && len(a) == len(b) && &a[0] == &b[0]
+}
+
+// notifierSetKey identifies the notifiers of a monitor by its provider
+// selection, dedup window and notification settings, so that notifiers are
+// only recreated when their configuration changes
+func notifierSetKey(cfg *MonitorConfig) string {
+	names := make([]string, 0, len(cfg.Notifiers))
+	for _, name := range cfg.Notifiers {
+		names = append(names, strings.ToLower(strings.TrimSpace(name)))
+	}
+	sort.Strings(names)
+
+	settings, err := json.Marshal(cfg.Notifications)
+	if err != nil {
+		// Settings that cannot be compared are never reused
+		settings = []byte(fmt.Sprintf("%p", cfg))
+	}
+	return fmt.Sprintf("%s|%v|%x", strings.Join(names, ","), cfg.DedupWindow, sha256.Sum256(settings))
+}
+
+// createNotifiers creates the notifiers of a monitor configuration
+func createNotifiers(cfg *MonitorConfig) ([]notifier.Notifier, error) {
+	return notifier.CreateNotifiers(&config.Config{
+		Notifications: cfg.Notifications,
+		DedupWindow:   cfg.DedupWindow,
+	}, cfg.Notifiers)
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+func webhookMonitorConfig(url string) *MonitorConfig {
+	return &MonitorConfig{
+		Notifications: config.NotificationsConfig{
+			Providers: map[string]config.ServiceConfig{
+				"webhook": {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": url}},
+			},
+		},
+	}
+}
+
+func TestSharedResourcesReloadKeepsNotifiersInUse(t *testing.T) {
+	shared := NewSharedResources()
+	defer shared.Close()
+
+	original, err := shared.notifiersFor(webhookMonitorConfig("http://127.0.0.1:1/a"))
+	if err != nil {
+		t.Fatalf("notifiersFor failed: %v", err)
+	}
+
+	// An unchanged configuration keeps its notifiers, and their escalations
+	release := shared.Reset()
+	reused, err := shared.notifiersFor(webhookMonitorConfig("http://127.0.0.1:1/a"))
+	if err != nil {
+		t.Fatalf("notifiersFor failed: %v", err)
+	}
+	release([]*UnifiedMonitor{{notifiers: reused}})
+	if !sameNotifierSet(original, reused) || len(shared.retired) != 0 {
+		t.Error("Expected unchanged notifiers to be reused")
+	}
+
+	// A monitor that failed to reload keeps using the replaced set
+	release = shared.Reset()
+	changed, err := shared.notifiersFor(webhookMonitorConfig("http://127.0.0.1:1/b"))
+	if err != nil {
+		t.Fatalf("notifiersFor failed: %v", err)
+	}
+	if sameNotifierSet(original, changed) {
+		t.Fatal("Expected changed settings to create new notifiers")
+	}
+	release([]*UnifiedMonitor{{notifiers: original}, {notifiers: changed}})
+	if len(shared.retired) != 1 || !sameNotifierSet(shared.retired[0], original) {
+		t.Fatalf("Expected the set still in use to be kept open, got %d retired sets", len(shared.retired))
+	}
+
+	// Once no monitor uses it, the next reload closes it
+	release = shared.Reset()
+	if _, err := shared.notifiersFor(webhookMonitorConfig("http://127.0.0.1:1/b")); err != nil {
+		t.Fatalf("notifiersFor failed: %v", err)
+	}
+	release([]*UnifiedMonitor{{notifiers: changed}})
+	if len(shared.retired) != 0 || len(shared.previous) != 0 {
+		t.Errorf("Expected replaced sets to be closed, got %d retired and %d previous", len(shared.retired), len(shared.previous))
+	}
+}
+
+func TestReloadKeepsUnchangedDaemonNotifiers(t *testing.T) {
+	cfg := webhookMonitorConfig("http://127.0.0.1:1/a")
+	notifiers, err := createNotifiers(cfg)
+	if err != nil {
+		t.Fatalf("createNotifiers failed: %v", err)
+	}
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api"}, notifiers: notifiers, notifierKey: notifierSetKey(cfg)}
+
+	if err := m.Reload(webhookMonitorConfig("http://127.0.0.1:1/a")); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !sameNotifierSet(m.currentNotifiers(), notifiers) {
+		t.Error("Expected unchanged notifiers to be kept")
+	}
+
+	if err := m.Reload(webhookMonitorConfig("http://127.0.0.1:1/b")); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if sameNotifierSet(m.currentNotifiers(), notifiers) {
+		t.Error("Expected changed settings to create new notifiers")
+	}
+	closeNotifierSet(m.currentNotifiers())
+}
@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// SilenceDetector watches a source's last activity time and reports when it
+// goes quiet for longer than the configured window, and again when it recovers
+type SilenceDetector struct {
+	window       time.Duration
+	lastActivity func() time.Time
+	onSilent     func(silentFor time.Duration)
+	onRecovered  func(silentFor time.Duration)
+
+	mutex       sync.Mutex
+	silent      bool
+	silentSince time.Time
+}
+
+// NewSilenceDetector creates a detector for the given window.
+// lastActivity is polled to learn when the source last produced output.
+func NewSilenceDetector(window time.Duration, lastActivity func() time.Time, onSilent, onRecovered func(silentFor time.Duration)) *SilenceDetector {
+	return &SilenceDetector{
+		window:       window,
+		lastActivity: lastActivity,
+		onSilent:     onSilent,
+		onRecovered:  onRecovered,
+	}
+}
+
+// Check evaluates the source state at the given time and fires the
+// silent/recovered callbacks on state transitions only
+func (d *SilenceDetector) Check(now time.Time) {
+	last := d.lastActivity()
+	if last.IsZero() {
+		// Source has not started yet
+		return
+	}
+
+	d.mutex.Lock()
+	var fireSilent, fireRecovered bool
+	var silentFor time.Duration
+
+	switch {
+	case !d.silent && now.Sub(last) >= d.window:
+		d.silent = true
+		d.silentSince = last
+		fireSilent = true
+		silentFor = now.Sub(last)
+	case d.silent && last.After(d.silentSince):
+		d.silent = false
+		fireRecovered = true
+		silentFor = last.Sub(d.silentSince)
+	}
+	d.mutex.Unlock()
+
+	if fireSilent && d.onSilent != nil {
+		d.onSilent(silentFor)
+	}
+	if fireRecovered && d.onRecovered != nil {
+		d.onRecovered(silentFor)
+	}
+}
+
+// IsSilent reports whether the source is currently considered silent
+func (d *SilenceDetector) IsSilent() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.silent
+}
+
+// Run checks the source periodically until stopCh is closed
+func (d *SilenceDetector) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(d.checkInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			d.Check(now)
+		}
+	}
+}
+
+// checkInterval returns how often to poll, a quarter of the window clamped to [1s, 1m]
+func (d *SilenceDetector) checkInterval() time.Duration {
+	interval := d.window / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	return interval
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSilenceDetectorTransitions(t *testing.T) {
+	start := time.Now()
+	last := start
+
+	silentCount := 0
+	recoveredCount := 0
+	var recoveredAfter time.Duration
+
+	d := NewSilenceDetector(time.Minute, func() time.Time { return last },
+		func(time.Duration) { silentCount++ },
+		func(silentFor time.Duration) {
+			recoveredCount++
+			recoveredAfter = silentFor
+		})
+
+	// Within the window: nothing happens
+	d.Check(start.Add(30 * time.Second))
+	if silentCount != 0 || d.IsSilent() {
+		t.Fatalf("Expected source to be active, got silent=%v count=%d", d.IsSilent(), silentCount)
+	}
+
+	// Past the window: one silent alert, repeated checks do not re-alert
+	d.Check(start.Add(61 * time.Second))
+	d.Check(start.Add(90 * time.Second))
+	if silentCount != 1 {
+		t.Errorf("Expected 1 silent alert, got %d", silentCount)
+	}
+	if !d.IsSilent() {
+		t.Error("Expected source to be silent")
+	}
+
+	// New activity: one recovered alert
+	last = start.Add(2 * time.Minute)
+	d.Check(start.Add(2*time.Minute + time.Second))
+	d.Check(start.Add(2*time.Minute + 2*time.Second))
+	if recoveredCount != 1 {
+		t.Errorf("Expected 1 recovered alert, got %d", recoveredCount)
+	}
+	if recoveredAfter != 2*time.Minute {
+		t.Errorf("Expected silence duration 2m, got %v", recoveredAfter)
+	}
+	if d.IsSilent() {
+		t.Error("Expected source to be active after recovery")
+	}
+}
+
+func TestSilenceDetectorIgnoresUnstartedSource(t *testing.T) {
+	fired := false
+	d := NewSilenceDetector(time.Second, func() time.Time { return time.Time{} },
+		func(time.Duration) { fired = true }, nil)
+
+	d.Check(time.Now().Add(time.Hour))
+	if fired {
+		t.Error("Expected no alert before the source reports any activity")
+	}
+}
+
+func TestSilenceDetectorCheckInterval(t *testing.T) {
+	tests := []struct {
+		window   time.Duration
+		expected time.Duration
+	}{
+		{2 * time.Second, time.Second},
+		{2 * time.Minute, 30 * time.Second},
+		{time.Hour, time.Minute},
+	}
+
+	for _, tt := range tests {
+		d := NewSilenceDetector(tt.window, time.Now, nil, nil)
+		if got := d.checkInterval(); got != tt.expected {
+			t.Errorf("window %v: expected interval %v, got %v", tt.window, tt.expected, got)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/display"
 	"github.com/shiquda/lai/internal/logger"
 )
@@ -21,6 +22,9 @@ type StreamCollector struct {
 	lineCount     int
 	checkInterval time.Duration
 	onTrigger     func(newContent string) error
+	onBatch       func(newContent string, restarts []string) error
+	onFinal       func(content string) error
+	onExit        func(info ExitInfo)
 	finalSummary  bool
 	colorPrinter  *display.ColorPrinter
 
@@ -31,8 +35,38 @@ type StreamCollector struct {
 	running   bool
 	runMutex  sync.RWMutex
 	startTime time.Time
+
+	lastActivity time.Time
+
+	// Output rate tracking: lines seen in the current second and the peak so far
+	rateSecond int64
+	rateCount  int
+	peakRate   int
+
+	exitInfo ExitInfo
+
+	// processedCount is how many lines have been passed to the trigger handler;
+	// triggerMutex serializes threshold checks and explicit flushes
+	processedCount int
+	triggerMutex   sync.Mutex
+
+	workingDir string
+
+	// Restart policy for when the command exits on its own. restartNotes holds
+	// restarts not yet reported in a batch.
+	restartPolicy string
+	restartDelay  time.Duration
+	restarts      int
+	restartNotes  []string
 }
 
+// DefaultRestartDelay is the pause before a command is relaunched
+const DefaultRestartDelay = 5 * time.Second
+
+// MinRestartDelay keeps a command that exits at once from being relaunched in
+// a tight loop
+const MinRestartDelay = time.Second
+
 // NewStreamCollector creates a new stream collector for command output
 func NewStreamCollector(command string, args []string, lineThreshold int, checkInterval time.Duration, finalSummary bool, colorPrinter *display.ColorPrinter) *StreamCollector {
 	return &StreamCollector{
@@ -52,7 +86,60 @@ func (sc *StreamCollector) SetTriggerHandler(handler func(newContent string) err
 	sc.onTrigger = handler
 }
 
-// Start begins monitoring the command output
+// SetBatchHandler sets a callback for when threshold is reached that also
+// gets the restarts since the previous batch. It takes precedence over the
+// trigger handler.
+func (sc *StreamCollector) SetBatchHandler(handler func(newContent string, restarts []string) error) {
+	sc.onBatch = handler
+}
+
+// batchHandler returns the handler batches are passed to, or nil without one
+func (sc *StreamCollector) batchHandler() func(newContent string, restarts []string) error {
+	if sc.onBatch != nil {
+		return sc.onBatch
+	}
+	if sc.onTrigger != nil {
+		trigger := sc.onTrigger
+		return func(newContent string, _ []string) error { return trigger(newContent) }
+	}
+	return nil
+}
+
+// SetFinalSummaryHandler sets the callback for the summary sent when the command
+// exits. Without one, the final summary goes to the trigger handler.
+func (sc *StreamCollector) SetFinalSummaryHandler(handler func(content string) error) {
+	sc.onFinal = handler
+}
+
+// SetExitHandler sets the callback run after the command ends, whether or not
+// a final summary is enabled
+func (sc *StreamCollector) SetExitHandler(handler func(info ExitInfo)) {
+	sc.onExit = handler
+}
+
+// SetWorkingDir sets the directory the command runs in (default: the current one)
+func (sc *StreamCollector) SetWorkingDir(dir string) {
+	sc.workingDir = dir
+}
+
+// SetRestartPolicy makes the collector relaunch the command after it exits:
+// always, or only on-failure. Collected lines and batching state are kept
+// across restarts, and each restart is passed to the batch handler with the
+// next batch.
+func (sc *StreamCollector) SetRestartPolicy(policy string, delay time.Duration) {
+	sc.restartPolicy = policy
+	sc.restartDelay = delay
+}
+
+// ExitInfo returns how the command ended; it is only meaningful after Start returns
+func (sc *StreamCollector) ExitInfo() ExitInfo {
+	sc.lineMutex.RLock()
+	defer sc.lineMutex.RUnlock()
+	return sc.exitInfo
+}
+
+// Start begins monitoring the command output. It returns an *ExitError when
+// the command exits with a non-zero code or is killed by a signal.
 func (sc *StreamCollector) Start() error {
 	sc.runMutex.Lock()
 	if sc.running {
@@ -63,30 +150,91 @@ func (sc *StreamCollector) Start() error {
 	sc.startTime = time.Now() // Record start time
 	sc.runMutex.Unlock()
 
+	sc.lineMutex.Lock()
+	sc.lastActivity = sc.startTime
+	sc.lineMutex.Unlock()
+
 	defer func() {
 		sc.runMutex.Lock()
 		sc.running = false
 		sc.runMutex.Unlock()
 	}()
 
-	// Start the command
-	sc.cmd = exec.Command(sc.command, sc.args...)
+	// The threshold checker runs across restarts, from the first successful start
+	var checker sync.WaitGroup
+	startChecker := sync.OnceFunc(func() {
+		checker.Add(1)
+		go func() {
+			defer checker.Done()
+			sc.runThresholdChecker()
+		}()
+	})
+
+	var info ExitInfo
+	for {
+		waitErr, stoppedByUser, err := sc.runCommand(startChecker)
+		if err != nil {
+			if sc.restarts == 0 {
+				sc.closeStop()
+				checker.Wait()
+				return err
+			}
+			// Keep the exit of the last run and finish normally
+			logger.Errorf("Failed to restart command: %v", err)
+			break
+		}
+
+		info = sc.buildExitInfo(waitErr, stoppedByUser)
+		restart, stopped := sc.waitForRestart(info)
+		if stopped {
+			info = sc.markStopped()
+		}
+		if !restart {
+			break
+		}
+	}
+
+	// Signal the threshold checker to process what is left and stop
+	sc.closeStop()
+	checker.Wait()
+
+	// Send final summary if enabled
+	if sc.finalSummary && (sc.onFinal != nil || sc.batchHandler() != nil) {
+		sc.sendFinalSummary(info)
+	}
+
+	if sc.onExit != nil {
+		sc.onExit(info)
+	}
 
-	stdout, err := sc.cmd.StdoutPipe()
+	if info.Failed() {
+		return &ExitError{Info: info}
+	}
+	return nil
+}
+
+// runCommand runs the command once, streaming its output until it exits or
+// the collector is stopped. onStarted is called once the command is running.
+func (sc *StreamCollector) runCommand(onStarted func()) (waitErr error, stoppedByUser bool, err error) {
+	cmd := exec.Command(sc.command, sc.args...)
+	cmd.Dir = sc.workingDir
+
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+		return nil, false, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := sc.cmd.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+		return nil, false, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	if err := sc.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start command: %w", err)
+	if err := cmd.Start(); err != nil {
+		return nil, false, fmt.Errorf("failed to start command: %w", err)
 	}
+	sc.cmd = cmd
 
-	logger.Infof("Started command: %s %s (PID: %d)", sc.command, strings.Join(sc.args, " "), sc.cmd.Process.Pid)
+	logger.Infof("Started command: %s %s (PID: %d)", sc.command, strings.Join(sc.args, " "), cmd.Process.Pid)
 
 	// Create wait groups for goroutines
 	var wg sync.WaitGroup
@@ -107,33 +255,25 @@ func (sc *StreamCollector) Start() error {
 
 	// Small delay to ensure monitoring goroutines are ready before command might finish
 	time.Sleep(10 * time.Millisecond)
-
-	// Start threshold checker
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sc.runThresholdChecker()
-	}()
+	onStarted()
 
 	// Wait for command to finish or stop signal
 	cmdDone := make(chan error, 1)
 	go func() {
-		cmdDone <- sc.cmd.Wait()
+		cmdDone <- cmd.Wait()
 	}()
 
-	var commandError error
 	select {
 	case <-sc.stopCh:
 		// Stop signal received, kill the command
-		if sc.cmd.Process != nil {
-			sc.cmd.Process.Kill()
+		if cmd.Process != nil {
+			cmd.Process.Kill()
 		}
-		<-cmdDone // Wait for command to actually exit
+		waitErr = <-cmdDone // Wait for command to actually exit
+		stoppedByUser = true
 		logger.Info("Command stopped by user")
 	case err := <-cmdDone:
-		// Command finished - signal threshold checker to stop
-		close(sc.stopCh)
-		commandError = err
+		waitErr = err
 		if err != nil {
 			logger.Errorf("Command finished with error: %v", err)
 		} else {
@@ -141,15 +281,91 @@ func (sc *StreamCollector) Start() error {
 		}
 	}
 
-	// Wait for all goroutines to finish
+	// Wait for the output of this run to be read
 	wg.Wait()
+	return waitErr, stoppedByUser, nil
+}
 
-	// Send final summary if enabled
-	if sc.finalSummary && sc.onTrigger != nil {
-		sc.sendFinalSummary(commandError)
+// waitForRestart decides whether the command is relaunched under the restart
+// policy and waits for the restart delay. stopped reports that the collector
+// was stopped while waiting.
+func (sc *StreamCollector) waitForRestart(info ExitInfo) (restart, stopped bool) {
+	if info.StoppedByUser {
+		return false, false
+	}
+	switch sc.restartPolicy {
+	case daemon.RestartAlways:
+	case daemon.RestartOnFailure:
+		if !info.Failed() {
+			return false, false
+		}
+	default:
+		return false, false
 	}
 
-	return nil
+	sc.lineMutex.Lock()
+	sc.restarts++
+	restarts := sc.restarts
+	sc.restartNotes = append(sc.restartNotes, fmt.Sprintf("%s: command %s and was restarted (restart %d)",
+		time.Now().Format("2006-01-02 15:04:05"), info.Status(), restarts))
+	sc.lineMutex.Unlock()
+
+	logger.Warnf("Command %s, restarting in %v (restart %d)", info.Status(), sc.restartDelay, restarts)
+	select {
+	case <-sc.stopCh:
+		logger.Info("Command stopped by user")
+		return false, true
+	case <-time.After(sc.restartDelay):
+		return true, false
+	}
+}
+
+// markStopped records that the collector was stopped between runs
+func (sc *StreamCollector) markStopped() ExitInfo {
+	sc.lineMutex.Lock()
+	defer sc.lineMutex.Unlock()
+	sc.exitInfo.StoppedByUser = true
+	sc.exitInfo.Duration = time.Since(sc.startTime)
+	sc.exitInfo.Restarts = sc.restarts
+	return sc.exitInfo
+}
+
+// closeStop closes the stop channel unless Stop already did
+func (sc *StreamCollector) closeStop() {
+	sc.runMutex.Lock()
+	defer sc.runMutex.Unlock()
+	select {
+	case <-sc.stopCh:
+	default:
+		close(sc.stopCh)
+	}
+}
+
+// Restarts returns how many times the command has been restarted (thread-safe)
+func (sc *StreamCollector) Restarts() int {
+	sc.lineMutex.RLock()
+	defer sc.lineMutex.RUnlock()
+	return sc.restarts
+}
+
+// buildExitInfo records how the command ended
+func (sc *StreamCollector) buildExitInfo(waitErr error, stoppedByUser bool) ExitInfo {
+	sc.lineMutex.Lock()
+	defer sc.lineMutex.Unlock()
+
+	code, signal := exitStatus(waitErr)
+	sc.exitInfo = ExitInfo{
+		Command:       strings.TrimSpace(sc.command + " " + strings.Join(sc.args, " ")),
+		ExitCode:      code,
+		Signal:        signal,
+		Duration:      time.Since(sc.startTime),
+		TotalLines:    sc.lineCount,
+		PeakLineRate:  sc.peakRate,
+		LastStderr:    lastStderr(sc.lines, lastStderrLines),
+		StoppedByUser: stoppedByUser,
+		Restarts:      sc.restarts,
+	}
+	return sc.exitInfo
 }
 
 // Stop stops the stream collector
@@ -159,12 +375,7 @@ func (sc *StreamCollector) Stop() {
 	sc.runMutex.RUnlock()
 
 	if running {
-		select {
-		case <-sc.stopCh:
-			// Channel already closed
-		default:
-			close(sc.stopCh)
-		}
+		sc.closeStop()
 	}
 }
 
@@ -179,6 +390,8 @@ func (sc *StreamCollector) monitorStream(stream io.ReadCloser, streamType string
 		sc.lineMutex.Lock()
 		sc.lines = append(sc.lines, fmt.Sprintf("[%s] %s", streamType, line))
 		sc.lineCount++
+		sc.lastActivity = time.Now()
+		sc.recordRate(sc.lastActivity)
 		sc.lineMutex.Unlock()
 
 		// Print to console for immediate feedback with appropriate coloring
@@ -208,74 +421,77 @@ func (sc *StreamCollector) monitorStream(stream io.ReadCloser, streamType string
 	}
 }
 
+// recordRate counts a line towards the per-second output rate; callers hold lineMutex
+func (sc *StreamCollector) recordRate(now time.Time) {
+	if second := now.Unix(); second != sc.rateSecond {
+		sc.rateSecond = second
+		sc.rateCount = 0
+	}
+	sc.rateCount++
+	if sc.rateCount > sc.peakRate {
+		sc.peakRate = sc.rateCount
+	}
+}
+
 // runThresholdChecker periodically checks if threshold is reached
 func (sc *StreamCollector) runThresholdChecker() {
 	ticker := time.NewTicker(sc.checkInterval)
 	defer ticker.Stop()
 
-	lastProcessedCount := 0
-
 	for {
 		select {
 		case <-sc.stopCh:
 			// Before exiting, check if there are any unprocessed lines
-			sc.processRemainingLines(lastProcessedCount)
+			sc.processPending(sc.lineThreshold, sc.batchHandler(), "final trigger handler")
 			return
 		case <-ticker.C:
-			sc.lineMutex.RLock()
-			currentCount := sc.lineCount
-			sc.lineMutex.RUnlock()
-
-			newLines := currentCount - lastProcessedCount
-
-			if newLines >= sc.lineThreshold {
-				// Get the new content
-				sc.lineMutex.RLock()
-				var newContent strings.Builder
-				for i := lastProcessedCount; i < currentCount && i < len(sc.lines); i++ {
-					newContent.WriteString(sc.lines[i])
-					newContent.WriteString("\n")
-				}
-				contentStr := newContent.String()
-				sc.lineMutex.RUnlock()
-
-				// Call the trigger handler
-				if sc.onTrigger != nil && contentStr != "" {
-					if err := sc.onTrigger(contentStr); err != nil {
-						logger.Errorf("Error in trigger handler: %v", err)
-					}
-				}
-
-				lastProcessedCount = currentCount
-			}
+			sc.processPending(sc.lineThreshold, sc.batchHandler(), "trigger handler")
 		}
 	}
 }
 
-// processRemainingLines processes any unprocessed lines when stopping
-func (sc *StreamCollector) processRemainingLines(lastProcessedCount int) {
-	sc.lineMutex.RLock()
+// processPending passes unprocessed lines, and the restarts since the last
+// batch, to handler once at least minLines have accumulated, and returns how
+// many lines were passed
+func (sc *StreamCollector) processPending(minLines int, handler func(newContent string, restarts []string) error, label string) (int, error) {
+	sc.triggerMutex.Lock()
+	defer sc.triggerMutex.Unlock()
+
+	sc.lineMutex.Lock()
 	currentCount := sc.lineCount
+	newLines := currentCount - sc.processedCount
+	if newLines <= 0 || newLines < minLines {
+		sc.lineMutex.Unlock()
+		return 0, nil
+	}
 
-	// If there are unprocessed lines and we meet the threshold
-	if currentCount > lastProcessedCount && (currentCount-lastProcessedCount) >= sc.lineThreshold {
-		var newContent strings.Builder
-		for i := lastProcessedCount; i < currentCount && i < len(sc.lines); i++ {
-			newContent.WriteString(sc.lines[i])
-			newContent.WriteString("\n")
-		}
-		contentStr := newContent.String()
-		sc.lineMutex.RUnlock()
+	// Get the new content
+	var newContent strings.Builder
+	restarts := sc.restartNotes
+	sc.restartNotes = nil
+	for i := sc.processedCount; i < currentCount && i < len(sc.lines); i++ {
+		newContent.WriteString(sc.lines[i])
+		newContent.WriteString("\n")
+	}
+	contentStr := newContent.String()
+	sc.lineMutex.Unlock()
 
-		// Call the trigger handler one final time
-		if sc.onTrigger != nil && contentStr != "" {
-			if err := sc.onTrigger(contentStr); err != nil {
-				logger.Errorf("Error in final trigger handler: %v", err)
-			}
-		}
-	} else {
-		sc.lineMutex.RUnlock()
+	sc.processedCount = currentCount
+	if handler == nil || contentStr == "" {
+		return newLines, nil
 	}
+
+	if err := handler(contentStr, restarts); err != nil {
+		logger.Errorf("Error in %s: %v", label, err)
+		return newLines, err
+	}
+	return newLines, nil
+}
+
+// FlushPending passes lines produced since the last trigger to handler, even
+// if they have not reached the line threshold
+func (sc *StreamCollector) FlushPending(handler func(newContent string, restarts []string) error) (int, error) {
+	return sc.processPending(1, handler, "flush handler")
 }
 
 // GetLineCount returns current line count (thread-safe)
@@ -285,6 +501,13 @@ func (sc *StreamCollector) GetLineCount() int {
 	return sc.lineCount
 }
 
+// LastActivity returns the time the command last produced output (thread-safe)
+func (sc *StreamCollector) LastActivity() time.Time {
+	sc.lineMutex.RLock()
+	defer sc.lineMutex.RUnlock()
+	return sc.lastActivity
+}
+
 // GetLines returns all collected lines (thread-safe)
 func (sc *StreamCollector) GetLines() []string {
 	sc.lineMutex.RLock()
@@ -296,25 +519,30 @@ func (sc *StreamCollector) GetLines() []string {
 }
 
 // sendFinalSummary generates and sends a final summary when the command exits
-func (sc *StreamCollector) sendFinalSummary(commandError error) {
+func (sc *StreamCollector) sendFinalSummary(info ExitInfo) {
 	sc.lineMutex.RLock()
 	totalLines := sc.lineCount
 	allLines := make([]string, len(sc.lines))
 	copy(allLines, sc.lines)
 	sc.lineMutex.RUnlock()
 
-	duration := time.Since(sc.startTime)
-
 	// Build final summary content
 	var summaryBuilder strings.Builder
 	summaryBuilder.WriteString("=== PROGRAM EXIT SUMMARY ===\n")
-	summaryBuilder.WriteString(fmt.Sprintf("Command: %s %s\n", sc.command, strings.Join(sc.args, " ")))
-	summaryBuilder.WriteString(fmt.Sprintf("Duration: %v\n", duration.Round(time.Second)))
+	summaryBuilder.WriteString(fmt.Sprintf("Command: %s\n", info.Command))
+	summaryBuilder.WriteString(fmt.Sprintf("Duration: %v\n", info.Duration.Round(time.Second)))
 	summaryBuilder.WriteString(fmt.Sprintf("Total lines processed: %d\n", totalLines))
+	summaryBuilder.WriteString(fmt.Sprintf("Peak output rate: %d lines/s\n", info.PeakLineRate))
+	if info.Restarts > 0 {
+		summaryBuilder.WriteString(fmt.Sprintf("Restarts: %d\n", info.Restarts))
+	}
 
-	if commandError != nil {
-		summaryBuilder.WriteString(fmt.Sprintf("Exit status: ERROR - %v\n", commandError))
-	} else {
+	switch {
+	case info.Failed():
+		summaryBuilder.WriteString(fmt.Sprintf("Exit status: ERROR - %s\n", info.Status()))
+	case info.StoppedByUser:
+		summaryBuilder.WriteString("Exit status: STOPPED by user\n")
+	default:
 		summaryBuilder.WriteString("Exit status: SUCCESS\n")
 	}
 
@@ -339,8 +567,14 @@ func (sc *StreamCollector) sendFinalSummary(commandError error) {
 	finalContent := summaryBuilder.String()
 
 	// Send the final summary
+	handler := sc.onFinal
+	if handler == nil {
+		batchHandler := sc.batchHandler()
+		handler = func(content string) error { return batchHandler(content, nil) }
+	}
+
 	logger.Info("Generating final summary...")
-	if err := sc.onTrigger(finalContent); err != nil {
+	if err := handler(finalContent); err != nil {
 		logger.Errorf("Failed to send final summary: %v", err)
 	} else {
 		logger.Info("Final summary sent successfully")
@@ -1,12 +1,14 @@
 package collector
 
 import (
+	"errors"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/display"
 )
 
@@ -329,3 +331,186 @@ func TestStreamCollectorFinalSummary(t *testing.T) {
 		t.Errorf("Expected final summary to contain command output, got: %s", finalContent)
 	}
 }
+
+func TestStreamCollectorFinalSummaryHandler(t *testing.T) {
+	cmd, args := getSimpleEchoCommand("separate final handler")
+	sc := NewStreamCollector(cmd, args, 10, 50*time.Millisecond, true, getTestColorPrinter())
+
+	var triggerContent, finalContent string
+	sc.SetTriggerHandler(func(content string) error {
+		triggerContent += content
+		return nil
+	})
+	sc.SetFinalSummaryHandler(func(content string) error {
+		finalContent = content
+		return nil
+	})
+
+	if err := sc.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if !strings.Contains(finalContent, "PROGRAM EXIT SUMMARY") {
+		t.Errorf("Expected final summary in final handler, got: %s", finalContent)
+	}
+	if strings.Contains(triggerContent, "PROGRAM EXIT SUMMARY") {
+		t.Errorf("Final summary should not go to the trigger handler, got: %s", triggerContent)
+	}
+}
+
+func TestStreamCollectorExitInfo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	sc := NewStreamCollector("sh", []string{"-c", "echo out; echo boom >&2; exit 3"}, 100, 50*time.Millisecond, false, getTestColorPrinter())
+
+	var handled ExitInfo
+	sc.SetExitHandler(func(info ExitInfo) {
+		handled = info
+	})
+
+	err := sc.Start()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected *ExitError, got %v", err)
+	}
+
+	info := exitErr.Info
+	if info.ExitCode != 3 || info.Signal != "" || !info.Failed() {
+		t.Errorf("Unexpected exit info: %+v", info)
+	}
+	if info.TotalLines != 2 || info.PeakLineRate < 1 {
+		t.Errorf("Expected 2 lines and a peak rate, got %+v", info)
+	}
+	if len(info.LastStderr) != 1 || info.LastStderr[0] != "boom" {
+		t.Errorf("Expected last stderr line 'boom', got %v", info.LastStderr)
+	}
+	if handled.ExitCode != 3 {
+		t.Errorf("Expected exit handler to run even without final summary, got %+v", handled)
+	}
+}
+
+func TestStreamCollectorStopIsNotFailure(t *testing.T) {
+	cmd, args := getSleepCommand()
+	sc := NewStreamCollector(cmd, args, 1000, 100*time.Millisecond, false, getTestColorPrinter())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sc.Start()
+	}()
+	time.Sleep(200 * time.Millisecond)
+	sc.Stop()
+
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error when stopped by user, got %v", err)
+	}
+	if info := sc.ExitInfo(); !info.StoppedByUser || info.Failed() {
+		t.Errorf("Expected a user stop, got %+v", info)
+	}
+}
+
+func TestStreamCollectorFlushPending(t *testing.T) {
+	sc := NewStreamCollector("true", nil, 10, time.Second, false, getTestColorPrinter())
+	sc.lines = []string{"one", "two"}
+	sc.lineCount = 2
+
+	var flushed string
+	lines, err := sc.FlushPending(func(content string, _ []string) error {
+		flushed = content
+		return nil
+	})
+	if err != nil || lines != 2 {
+		t.Fatalf("Expected 2 flushed lines, got %d (%v)", lines, err)
+	}
+	if flushed != "one\ntwo\n" {
+		t.Errorf("Unexpected flushed content %q", flushed)
+	}
+
+	if lines, _ := sc.FlushPending(func(string, []string) error { return nil }); lines != 0 {
+		t.Errorf("Expected nothing left to flush, got %d lines", lines)
+	}
+}
+
+func TestStreamCollectorRestartOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	// Fails on the first two runs and succeeds on the third
+	counter := t.TempDir() + "/runs"
+	script := "n=$(cat " + counter + " 2>/dev/null || echo 0); n=$((n+1)); echo $n > " + counter + "; echo run$n; [ $n -ge 3 ]"
+	sc := NewStreamCollector("sh", []string{"-c", script}, 1, 20*time.Millisecond, false, getTestColorPrinter())
+	sc.SetRestartPolicy(daemon.RestartOnFailure, 10*time.Millisecond)
+
+	var batches strings.Builder
+	var restarts []string
+	sc.SetBatchHandler(func(content string, batchRestarts []string) error {
+		batches.WriteString(content)
+		restarts = append(restarts, batchRestarts...)
+		return nil
+	})
+
+	if err := sc.Start(); err != nil {
+		t.Fatalf("Expected the final successful run to end without error, got %v", err)
+	}
+
+	info := sc.ExitInfo()
+	if info.Restarts != 2 || info.TotalLines != 3 {
+		t.Errorf("Expected 2 restarts and 3 lines kept across runs, got %+v", info)
+	}
+
+	if len(restarts) != 2 {
+		t.Fatalf("Expected each restart passed with a batch, got %v", restarts)
+	}
+	if !strings.Contains(restarts[0], "exited with code 1 and was restarted (restart 1)") {
+		t.Errorf("Expected the restart note to describe the exit, got %q", restarts[0])
+	}
+	content := batches.String()
+	if strings.Contains(content, "restarted") {
+		t.Errorf("Expected only command output in the batches, got %q", content)
+	}
+	for _, line := range []string{"run1", "run2", "run3"} {
+		if !strings.Contains(content, line) {
+			t.Errorf("Expected %s in the batches, got %q", line, content)
+		}
+	}
+}
+
+func TestStreamCollectorRestartStoppedDuringDelay(t *testing.T) {
+	cmd, args := getTestCommand()
+	sc := NewStreamCollector(cmd, args, 1000, 50*time.Millisecond, false, getTestColorPrinter())
+	sc.SetRestartPolicy(daemon.RestartAlways, time.Hour)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sc.Start()
+	}()
+	time.Sleep(300 * time.Millisecond)
+	sc.Stop()
+
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error when stopped while waiting to restart, got %v", err)
+	}
+	if info := sc.ExitInfo(); !info.StoppedByUser || info.Restarts != 1 {
+		t.Errorf("Expected a user stop after one scheduled restart, got %+v", info)
+	}
+}
+
+func TestStreamCollectorWorkingDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	sc := NewStreamCollector("sh", []string{"-c", "pwd"}, 100, 50*time.Millisecond, false, getTestColorPrinter())
+	sc.SetWorkingDir(dir)
+	if err := sc.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	lines := sc.GetLines()
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], dir) {
+		t.Errorf("Expected the command to run in %s, got %v", dir, lines)
+	}
+}
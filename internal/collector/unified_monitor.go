@@ -1,11 +1,18 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/daemon"
 	"github.com/shiquda/lai/internal/display"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/shiquda/lai/internal/notifier"
@@ -16,6 +23,7 @@ import (
 // MonitorConfig represents unified monitoring configuration
 type MonitorConfig struct {
 	Source           MonitorSource
+	Name             string
 	LineThreshold    int
 	CheckInterval    time.Duration
 	ChatID           string
@@ -27,10 +35,55 @@ type MonitorConfig struct {
 	Notifications    config.NotificationsConfig
 	PromptTemplates  config.PromptTemplatesConfig
 	Display          config.DisplayConfig
+
+	// ExpectActivityWithin enables silence detection when greater than zero
+	ExpectActivityWithin time.Duration
+
+	// Events switches notification event types on or off
+	Events config.EventsConfig
+
+	// Notifiers limits delivery to the named providers (empty means all enabled)
+	Notifiers []string
+
+	// DedupWindow suppresses repeated notifications (0 disables)
+	DedupWindow time.Duration
+
+	// WorkingDir is the directory commands run in (empty for the current one)
+	WorkingDir string
+
+	// Restart relaunches a command when it exits: daemon.RestartAlways or
+	// daemon.RestartOnFailure (empty or daemon.RestartNo to run it once)
+	Restart      string
+	RestartDelay time.Duration
+
+	// Include and Exclude filter the lines of each batch before it is
+	// summarized (regular expressions)
+	Include []string
+	Exclude []string
+}
+
+// MonitorOverrides holds per-monitor settings that take precedence over the
+// global defaults. Nil pointers leave the corresponding default untouched.
+type MonitorOverrides struct {
+	Name                 string
+	LineThreshold        *int
+	CheckInterval        *time.Duration
+	ChatID               *string
+	WorkingDir           string
+	FinalSummary         *bool
+	ErrorOnlyMode        *bool
+	FinalSummaryOnly     *bool
+	ExpectActivityWithin *time.Duration
+	Notifiers            []string
+	Restart              string
+	RestartDelay         *time.Duration
+	Include              []string
+	Exclude              []string
+	SummarizeTemplate    string
 }
 
 // BuildMonitorConfig builds unified monitoring configuration
-func BuildMonitorConfig(source MonitorSource, lineThreshold *int, checkInterval *time.Duration, chatID *string, workingDir string, finalSummary *bool, errorOnlyMode *bool, finalSummaryOnly *bool) (*MonitorConfig, error) {
+func BuildMonitorConfig(source MonitorSource, overrides MonitorOverrides) (*MonitorConfig, error) {
 	// Ensure global config exists
 	if err := config.EnsureGlobalConfig(); err != nil {
 		return nil, fmt.Errorf("failed to ensure global config: %w", err)
@@ -44,37 +97,59 @@ func BuildMonitorConfig(source MonitorSource, lineThreshold *int, checkInterval
 
 	// Build unified monitoring configuration
 	cfg := &MonitorConfig{
-		Source:           source,
-		LineThreshold:    globalConfig.Defaults.LineThreshold,
-		CheckInterval:    globalConfig.Defaults.CheckInterval,
-		Language:         globalConfig.Defaults.Language,
-		FinalSummary:     globalConfig.Defaults.FinalSummary,
-		FinalSummaryOnly: globalConfig.Defaults.FinalSummaryOnly,
-		ErrorOnlyMode:    globalConfig.Defaults.ErrorOnlyMode,
-		OpenAI:           globalConfig.Notifications.OpenAI,
-		Notifications:    globalConfig.Notifications,
-		PromptTemplates:  globalConfig.PromptTemplates,
-		Display:          globalConfig.Display,
+		Source:               source,
+		Name:                 overrides.Name,
+		LineThreshold:        globalConfig.Defaults.LineThreshold,
+		CheckInterval:        globalConfig.Defaults.CheckInterval,
+		Language:             globalConfig.Defaults.Language,
+		FinalSummary:         globalConfig.Defaults.FinalSummary,
+		FinalSummaryOnly:     globalConfig.Defaults.FinalSummaryOnly,
+		ErrorOnlyMode:        globalConfig.Defaults.ErrorOnlyMode,
+		ExpectActivityWithin: globalConfig.Defaults.ExpectActivityWithin,
+		Notifiers:            globalConfig.Defaults.Notifiers,
+		DedupWindow:          globalConfig.Defaults.DedupWindow,
+		Events:               globalConfig.Defaults.Events,
+		OpenAI:               globalConfig.Notifications.OpenAI,
+		Notifications:        globalConfig.Notifications,
+		PromptTemplates:      globalConfig.PromptTemplates,
+		Display:              globalConfig.Display,
+		WorkingDir:           overrides.WorkingDir,
+		Restart:              overrides.Restart,
+		RestartDelay:         DefaultRestartDelay,
+		Include:              overrides.Include,
+		Exclude:              overrides.Exclude,
 	}
 
 	// Apply command line parameter overrides
-	if lineThreshold != nil {
-		cfg.LineThreshold = *lineThreshold
+	if overrides.LineThreshold != nil {
+		cfg.LineThreshold = *overrides.LineThreshold
+	}
+	if overrides.CheckInterval != nil {
+		cfg.CheckInterval = *overrides.CheckInterval
+	}
+	if overrides.ChatID != nil {
+		cfg.ChatID = *overrides.ChatID
+	}
+	if overrides.FinalSummary != nil {
+		cfg.FinalSummary = *overrides.FinalSummary
 	}
-	if checkInterval != nil {
-		cfg.CheckInterval = *checkInterval
+	if overrides.FinalSummaryOnly != nil {
+		cfg.FinalSummaryOnly = *overrides.FinalSummaryOnly
 	}
-	if chatID != nil {
-		cfg.ChatID = *chatID
+	if overrides.ErrorOnlyMode != nil {
+		cfg.ErrorOnlyMode = *overrides.ErrorOnlyMode
 	}
-	if finalSummary != nil {
-		cfg.FinalSummary = *finalSummary
+	if overrides.ExpectActivityWithin != nil {
+		cfg.ExpectActivityWithin = *overrides.ExpectActivityWithin
 	}
-	if finalSummaryOnly != nil {
-		cfg.FinalSummaryOnly = *finalSummaryOnly
+	if len(overrides.Notifiers) > 0 {
+		cfg.Notifiers = overrides.Notifiers
 	}
-	if errorOnlyMode != nil {
-		cfg.ErrorOnlyMode = *errorOnlyMode
+	if overrides.RestartDelay != nil {
+		cfg.RestartDelay = *overrides.RestartDelay
+	}
+	if overrides.SummarizeTemplate != "" {
+		cfg.PromptTemplates.SummarizeTemplate = overrides.SummarizeTemplate
 	}
 
 	// If no ChatID specified, use the default one from Telegram provider
@@ -103,29 +178,101 @@ func (c *MonitorConfig) Validate() error {
 		return fmt.Errorf("at least one notification provider must be configured")
 	}
 
-	// Check if Telegram is properly configured if enabled
-	if telegramProvider, exists := c.Notifications.Providers["telegram"]; exists && telegramProvider.Enabled {
+	// Check if Telegram is properly configured if it will be used
+	if telegramProvider, exists := c.Notifications.Providers["telegram"]; exists && c.usesProvider("telegram", telegramProvider) {
 		if token, ok := telegramProvider.Config["bot_token"].(string); !ok || token == "" {
 			return fmt.Errorf("telegram.bot_token is required when telegram provider is enabled")
 		}
 		if chatID, ok := telegramProvider.Config["chat_id"].(string); !ok || chatID == "" {
 			return fmt.Errorf("telegram.chat_id is required when telegram provider is enabled")
 		}
+		if c.ChatID == "" {
+			return fmt.Errorf("chat_id is required (set via --chat-id or defaults.chat_id in global config)")
+		}
 	}
-	if c.ChatID == "" {
-		return fmt.Errorf("chat_id is required (set via --chat-id or defaults.chat_id in global config)")
+	if c.ExpectActivityWithin < 0 {
+		return fmt.Errorf("expect_activity_within must not be negative")
+	}
+	if err := daemon.ValidateRestartPolicy(c.Restart); err != nil {
+		return err
+	}
+	if c.RestartDelay < MinRestartDelay {
+		return fmt.Errorf("restart delay must be at least %s", MinRestartDelay)
 	}
 	return nil
 }
 
+// usesProvider reports whether notifications for this monitor go to the given provider
+func (c *MonitorConfig) usesProvider(name string, serviceConfig config.ServiceConfig) bool {
+	if len(c.Notifiers) == 0 {
+		return serviceConfig.Enabled
+	}
+	for _, selected := range c.Notifiers {
+		selected = strings.ToLower(strings.TrimSpace(selected))
+		if selected == name || selected == strings.ToLower(serviceConfig.Provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceLabel returns the monitored file path or command line
+func (c *MonitorConfig) SourceLabel() string {
+	return strings.TrimPrefix(c.Source.GetIdentifier(), "COMMAND_SOURCE:")
+}
+
+// DisplayName returns a human-readable label for the monitored source
+func (c *MonitorConfig) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.SourceLabel()
+}
+
 // UnifiedMonitor represents a unified monitoring system
 type UnifiedMonitor struct {
-	config     *MonitorConfig
-	collector  LogCollector
-	summarizer *summarizer.OpenAIClient
-	notifiers  []notifier.Notifier
+	config    *MonitorConfig
+	collector LogCollector
+	filter    *LineFilter
+
+	// The summarizer, notifiers, language and prompt templates are replaced
+	// by Reload
+	settingsMutex sync.RWMutex
+	summarizer    *summarizer.OpenAIClient
+	notifiers     []notifier.Notifier
+	notifierKey   string // notifierSetKey of the configuration the notifiers were created from
+
+	// shared is set for monitors hosted by an agent, which owns the notifiers
+	shared *SharedResources
+
+	// In final-summary-only mode batches are held here instead of being sent.
+	// Once the raw lines grow past finalOnlyBufferLimit they are summarized
+	// and kept as partial summaries.
+	bufferMutex      sync.Mutex
+	buffered         strings.Builder
+	partialSummaries []string
+
+	// State changed by chat and control commands and reported by Status
+	stateMutex       sync.Mutex
+	startedAt        time.Time
+	windowStart      time.Time // When the lines of the next summary started to be collected
+	cancel           context.CancelCauseFunc
+	pause            *pauseState
+	lines            int
+	counters         Counters
+	notifications    int
+	lastNotification time.Time
+	lastSummary      *SummaryRecord
+	mutedUntil       time.Time
+	lastSignature    string
+	ackedSignatures  map[string]time.Time // When each alert was acknowledged
+	commandBot       string               // Telegram bot the monitor takes commands from
 }
 
+// finalOnlyBufferLimit is how much raw log content final-summary-only mode keeps
+// before summarizing it incrementally
+const finalOnlyBufferLimit = 32 * 1024
+
 // NewUnifiedMonitor creates a new unified monitor
 func NewUnifiedMonitor(cfg *MonitorConfig) (*UnifiedMonitor, error) {
 	// Create OpenAI client
@@ -135,13 +282,35 @@ func NewUnifiedMonitor(cfg *MonitorConfig) (*UnifiedMonitor, error) {
 	// Create a temporary config object for notifier creation
 	tempConfig := &config.Config{
 		Notifications: cfg.Notifications,
+		DedupWindow:   cfg.DedupWindow,
+	}
+
+	notifiers, err := notifier.CreateNotifiers(tempConfig, cfg.Notifiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifiers: %w", err)
 	}
 
-	notifiers, err := notifier.CreateNotifiers(tempConfig, []string{})
+	return newUnifiedMonitor(cfg, openaiClient, notifiers)
+}
+
+// NewSharedUnifiedMonitor creates a monitor that takes its summarizer and
+// notifiers from resources shared with other monitors in the same process
+func NewSharedUnifiedMonitor(cfg *MonitorConfig, shared *SharedResources) (*UnifiedMonitor, error) {
+	notifiers, err := shared.notifiersFor(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create notifiers: %w", err)
 	}
 
+	m, err := newUnifiedMonitor(cfg, shared.summarizer(cfg.OpenAI), notifiers)
+	if err != nil {
+		return nil, err
+	}
+	m.shared = shared
+	return m, nil
+}
+
+// newUnifiedMonitor creates a monitor and the collector for its source
+func newUnifiedMonitor(cfg *MonitorConfig, openaiClient *summarizer.OpenAIClient, notifiers []notifier.Notifier) (*UnifiedMonitor, error) {
 	// Create appropriate collector based on source type
 	var collector LogCollector
 	identifier := cfg.Source.GetIdentifier()
@@ -160,76 +329,81 @@ func NewUnifiedMonitor(cfg *MonitorConfig) (*UnifiedMonitor, error) {
 		// Create color printer for command output
 		colorPrinter := display.NewColorPrinter(cfg.Display.Colors)
 
-		collector = NewStreamCollector(command, args, cfg.LineThreshold, cfg.CheckInterval, cfg.FinalSummary, colorPrinter)
+		streamCollector := NewStreamCollector(command, args, cfg.LineThreshold, cfg.CheckInterval, cfg.FinalSummary || cfg.FinalSummaryOnly, colorPrinter)
+		streamCollector.SetWorkingDir(cfg.WorkingDir)
+		streamCollector.SetRestartPolicy(cfg.Restart, cfg.RestartDelay)
+		collector = streamCollector
 	} else {
 		// Regular file monitoring
 		collector = New(identifier, cfg.LineThreshold, cfg.CheckInterval)
 	}
 
+	filter, err := NewLineFilter(cfg.Include, cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
 	return &UnifiedMonitor{
-		config:     cfg,
-		collector:  collector,
-		summarizer: openaiClient,
-		notifiers:  notifiers,
+		config:          cfg,
+		collector:       collector,
+		summarizer:      openaiClient,
+		notifiers:       notifiers,
+		notifierKey:     notifierSetKey(cfg),
+		filter:          filter,
+		ackedSignatures: make(map[string]time.Time),
 	}, nil
 }
 
-// Start begins monitoring
+// Start begins monitoring and runs until the source ends or a stop signal
+// is received
 func (m *UnifiedMonitor) Start() error {
-	// Set trigger handler
-	m.collector.SetTriggerHandler(func(newContent string) error {
-		logger.Info("Changes detected, processing...")
-
-		if m.config.ErrorOnlyMode {
-			// Error-only mode: first check if content contains errors
-			var analysis *summarizer.ErrorAnalysisResult
-			var err error
-
-			// Use custom template if available, otherwise use built-in
-			if m.config.PromptTemplates.ErrorAnalysisTemplate != "" {
-				analysis, err = m.summarizer.AnalyzeForErrorsWithTemplate(newContent, m.config.Language, m.config.PromptTemplates.ErrorAnalysisTemplate)
-			} else {
-				analysis, err = m.summarizer.AnalyzeForErrors(newContent, m.config.Language)
-			}
+	// Setup signal handling
+	p := platform.New()
+	sigChan := p.Signal.SetupShutdownSignals()
 
-			if err != nil {
-				return fmt.Errorf("failed to analyze errors: %w", err)
-			}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-sigChan:
+			logger.Info("\nReceived stop signal, shutting down...")
+			cancel(errStopSignal)
+		case <-ctx.Done():
+		}
+	}()
 
-			if !analysis.HasError {
-				logger.Info("No errors detected, skipping notification (error-only mode)")
-				return nil
-			}
+	return m.Run(ctx)
+}
 
-			logger.Infof("Error detected (severity: %s), sending notification", analysis.Severity)
-			if err := m.sendToAllNotifiers(analysis.Summary); err != nil {
-				return fmt.Errorf("failed to send notification: %w", err)
-			}
-		} else {
-			// Normal mode: generate summary and send notification
-			logger.Info("Generating summary...")
-			var summary string
-			var err error
-
-			// Use custom template if available, otherwise use built-in
-			if m.config.PromptTemplates.SummarizeTemplate != "" {
-				summary, err = m.summarizer.SummarizeWithTemplate(newContent, m.config.Language, m.config.PromptTemplates.SummarizeTemplate)
-			} else {
-				summary, err = m.summarizer.Summarize(newContent, m.config.Language)
-			}
+// errStopSignal is the cancel cause when Start receives a stop signal
+var errStopSignal = errors.New("received stop signal")
 
-			if err != nil {
-				return fmt.Errorf("failed to generate summary: %w", err)
-			}
+// Run monitors until the source ends or ctx is cancelled. The cancel cause,
+// if any, is reported in the stopped notification.
+func (m *UnifiedMonitor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 
-			if err := m.sendToAllNotifiers(summary); err != nil {
-				return fmt.Errorf("failed to send notification: %w", err)
-			}
-		}
-
-		// Note: Individual notification status is now logged in sendToAllNotifiers method
-		return nil
-	})
+	// Set trigger handlers
+	startedAt := time.Now()
+	m.stateMutex.Lock()
+	m.startedAt = startedAt
+	m.windowStart = startedAt
+	m.cancel = cancel
+	m.stateMutex.Unlock()
+	if batchCollector, ok := m.collector.(BatchCollector); ok {
+		batchCollector.SetBatchHandler(m.handleBatch)
+	} else {
+		m.collector.SetTriggerHandler(func(newContent string) error {
+			return m.handleBatch(newContent, nil)
+		})
+	}
+	if finalCollector, ok := m.collector.(FinalSummaryCollector); ok {
+		finalCollector.SetFinalSummaryHandler(m.handleFinalSummary)
+	}
+	if streamCollector, ok := m.collector.(*StreamCollector); ok {
+		streamCollector.SetExitHandler(m.handleExit)
+	}
 
 	// Display startup information
 	logger.Infof("Starting monitoring: %s", m.config.Source.GetIdentifier())
@@ -241,10 +415,46 @@ func (m *UnifiedMonitor) Start() error {
 	} else {
 		logger.Info("Error-only mode: DISABLED (will notify on all changes)")
 	}
+	if m.config.FinalSummaryOnly {
+		logger.Info("Final-summary-only mode: ENABLED (batches are held until the source exits or is stopped)")
+	}
+	if len(m.config.Notifiers) > 0 {
+		logger.Infof("Notifiers: %s", strings.Join(m.config.Notifiers, ", "))
+	}
+	if m.config.ExpectActivityWithin > 0 {
+		logger.Infof("Silence detection: alert if no output within %v", m.config.ExpectActivityWithin)
+	}
 
-	// Setup signal handling
-	p := platform.New()
-	sigChan := p.Signal.SetupShutdownSignals()
+	// Flush pending notifications (e.g. repeat summaries) on exit, unless
+	// they are shared with other monitors
+	if m.shared == nil {
+		defer m.closeNotifiers()
+	}
+	// A timed pause must not resume once the monitor is gone
+	defer m.stopPauseTimer()
+
+	// Start silence detection if configured
+	stopSilence := make(chan struct{})
+	defer close(stopSilence)
+	if m.config.ExpectActivityWithin > 0 {
+		detector := NewSilenceDetector(m.config.ExpectActivityWithin, m.collector.LastActivity, m.handleSilent, m.handleRecovered)
+		go detector.Run(stopSilence)
+	}
+
+	// Listen for chat commands if a Telegram bot has them enabled
+	if m.shared != nil {
+		if listener := m.shared.commandListener(m); listener != nil {
+			m.setCommandBot(listener.Bot())
+			listener.Register(m)
+			defer listener.Unregister(m)
+		}
+	} else if listener := m.newCommandListener(); listener != nil {
+		listenerCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m.setCommandBot(listener.Bot())
+		listener.Register(m)
+		go listener.Run(listenerCtx)
+	}
 
 	// Run collector in goroutine
 	errChan := make(chan error, 1)
@@ -252,30 +462,509 @@ func (m *UnifiedMonitor) Start() error {
 		errChan <- m.collector.Start()
 	}()
 
-	// Wait for signal or error
+	m.notifyLifecycle(notifier.LifecycleStarted, notifier.SeverityInfo, "")
+
+	// Wait for cancellation or error
 	select {
-	case <-sigChan:
-		logger.Info("\nReceived stop signal, shutting down...")
+	case <-ctx.Done():
+		m.finish(errChan, startedAt)
+		m.notifyLifecycle(notifier.LifecycleStopped, notifier.SeverityInfo, stopDetail(context.Cause(ctx)))
 		return nil
 	case err := <-errChan:
+		// A failed command is reported by its exit alert or final summary,
+		// so only a failure of the collector itself is a crash
+		var exitErr *ExitError
+		switch {
+		case err == nil:
+			m.notifyLifecycle(notifier.LifecycleStopped, notifier.SeverityInfo, "Source finished")
+		case errors.As(err, &exitErr):
+			m.notifyLifecycle(notifier.LifecycleStopped, notifier.SeverityInfo, stopDetail(exitErr))
+		default:
+			m.notifyLifecycle(notifier.LifecycleCrashed, notifier.SeverityError, err.Error())
+		}
 		return err
 	}
 }
 
+// handleBatch summarizes a batch of new log lines, listing the command
+// restarts it covers. In error-only mode it sends an error alert when the
+// batch contains errors, otherwise a batch summary.
+func (m *UnifiedMonitor) handleBatch(newContent string, restarts []string) error {
+	newContent, held := m.admitBatch(newContent, restarts)
+	if held {
+		return nil
+	}
+	if countLines(newContent) == 0 {
+		logger.Info("No lines left after filtering, skipping batch")
+		return nil
+	}
+
+	if m.config.FinalSummaryOnly {
+		m.bufferBatch(newContent)
+		return nil
+	}
+
+	logger.Info("Changes detected, processing...")
+	windowStart, windowEnd := m.summaryWindow(true)
+
+	if m.config.ErrorOnlyMode {
+		if !m.config.Events.ErrorEnabled() {
+			logger.Info("Error alerts are disabled, skipping batch")
+			return nil
+		}
+
+		// Error-only mode: first check if content contains errors
+		var analysis *summarizer.ErrorAnalysisResult
+		var err error
+
+		// Use custom template if available, otherwise use built-in
+		client, language, templates := m.analysisSettings()
+		if templates.ErrorAnalysisTemplate != "" {
+			analysis, err = client.AnalyzeForErrorsWithTemplate(newContent, language, templates.ErrorAnalysisTemplate)
+		} else {
+			analysis, err = client.AnalyzeForErrors(newContent, language)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to analyze errors: %w", err)
+		}
+
+		if !analysis.HasError {
+			logger.Info("No errors detected, skipping notification (error-only mode)")
+			return nil
+		}
+
+		logger.Infof("Error detected (severity: %s), sending notification", analysis.Severity)
+		msg := newSummaryMessage(notifier.MessageTypeError, m.config.SourceLabel(), newContent, analysis.Summary, analysis.Severity, windowStart, windowEnd)
+		addRestartNotes(msg, restarts)
+		if err := m.sendToAllNotifiers(msg); err != nil {
+			return fmt.Errorf("failed to send notification: %w", err)
+		}
+		return nil
+	}
+
+	if !m.config.Events.SummaryEnabled() {
+		logger.Info("Batch summaries are disabled, skipping batch")
+		return nil
+	}
+
+	// Normal mode: generate summary and send notification
+	logger.Info("Generating summary...")
+	summary, err := m.summarize(newContent)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	msg := newSummaryMessage(notifier.MessageTypeSummary, m.config.SourceLabel(), newContent, summary, batchSeverity(newContent), windowStart, windowEnd)
+	addRestartNotes(msg, restarts)
+	if err := m.sendToAllNotifiers(msg); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	// Note: Individual notification status is now logged in sendToAllNotifiers method
+	return nil
+}
+
+// admitBatch counts a batch of new log lines and returns the lines that pass
+// the filters. While paused those lines and the restarts are held for the
+// maintenance digest instead, and held is set.
+func (m *UnifiedMonitor) admitBatch(newContent string, restarts []string) (filtered string, held bool) {
+	lines := countLines(newContent)
+	filtered = m.filter.Apply(newContent)
+	kept := countLines(filtered)
+	m.stateMutex.Lock()
+	m.lines += lines
+	m.counters.FilteredLines += lines - kept
+	pause := m.pause
+	if pause != nil {
+		// Only what passes the filters would have been summarized
+		m.counters.PausedLines += lines
+		pause.hold(filtered, kept, restarts)
+		// The next summary covers what comes after the pause
+		m.windowStart = time.Now()
+	} else {
+		m.counters.Batches++
+	}
+	m.stateMutex.Unlock()
+
+	if pause == nil {
+		return filtered, false
+	}
+	if pause.drop {
+		logger.Infof("Monitoring is paused, dropping %d lines", lines)
+	} else {
+		logger.Infof("Monitoring is paused, holding %d lines for the maintenance digest", kept)
+	}
+	return "", true
+}
+
+// summaryWindow returns the period the next summary covers, ending now. With
+// advance set the following summary starts where this one ends.
+func (m *UnifiedMonitor) summaryWindow(advance bool) (start, end time.Time) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	start, end = m.windowStart, time.Now()
+	if advance {
+		m.windowStart = end
+	}
+	return start, end
+}
+
+// handleFinalSummary summarizes the exit report of a command and sends it as
+// a final summary
+func (m *UnifiedMonitor) handleFinalSummary(content string) error {
+	windowStart, windowEnd := m.summaryWindow(false)
+	content += m.bufferedContent()
+
+	summary, err := m.summarize(content)
+	if err != nil {
+		return fmt.Errorf("failed to generate final summary: %w", err)
+	}
+
+	msg := newSummaryMessage(notifier.MessageTypeFinalSummary, m.config.SourceLabel(), content, summary, notifier.SeverityInfo, windowStart, windowEnd)
+	if streamCollector, ok := m.collector.(*StreamCollector); ok {
+		info := streamCollector.ExitInfo()
+		msg.Details = exitDetails(info)
+		if info.Failed() {
+			msg.Severity = notifier.SeverityError
+		}
+	}
+	if err := m.sendToAllNotifiers(msg); err != nil {
+		return fmt.Errorf("failed to send final summary: %w", err)
+	}
+	return nil
+}
+
+// handleExit sends an exit alert when a command fails, independently of the final summary
+func (m *UnifiedMonitor) handleExit(info ExitInfo) {
+	if !info.Failed() {
+		return
+	}
+	if m.config.FinalSummaryOnly {
+		// The final summary already reports the failure
+		return
+	}
+	if !m.config.Events.ErrorEnabled() {
+		logger.Infof("Command %s; error alerts are disabled", info.Status())
+		return
+	}
+
+	body := fmt.Sprintf("Command `%s` %s after %v.", info.Command, info.Status(), info.Duration.Round(time.Second))
+	if len(info.LastStderr) > 0 {
+		body += fmt.Sprintf("\n\nLast %d stderr lines:\n```\n%s\n```", len(info.LastStderr), strings.Join(info.LastStderr, "\n"))
+	}
+
+	msg := notifier.NewMessage(notifier.MessageTypeError, m.config.SourceLabel(), body, notifier.SeverityError)
+	msg.Title = "💥 Command Failed"
+	msg.LineCount = info.TotalLines
+	msg.Details = exitDetails(info)
+	m.stateMutex.Lock()
+	m.counters.Alerts++
+	m.stateMutex.Unlock()
+	m.sendMessageToAllNotifiers(msg)
+}
+
+// exitDetails returns the exit metadata shown as message fields
+func exitDetails(info ExitInfo) []notifier.MessageField {
+	var details []notifier.MessageField
+	if info.StoppedByUser {
+		details = append(details, notifier.MessageField{Name: "Exit", Value: "stopped by user"})
+	} else {
+		details = append(details, notifier.MessageField{Name: "Exit code", Value: strconv.Itoa(info.ExitCode)})
+		if info.Signal != "" {
+			details = append(details, notifier.MessageField{Name: "Signal", Value: info.Signal})
+		}
+	}
+	details = append(details,
+		notifier.MessageField{Name: "Duration", Value: info.Duration.Round(time.Second).String()},
+		notifier.MessageField{Name: "Peak rate", Value: fmt.Sprintf("%d lines/s", info.PeakLineRate)},
+	)
+	if info.Restarts > 0 {
+		details = append(details, notifier.MessageField{Name: "Restarts", Value: strconv.Itoa(info.Restarts)})
+	}
+	return details
+}
+
+// bufferBatch holds a batch for the final summary, summarizing the buffer
+// incrementally once it grows too large
+func (m *UnifiedMonitor) bufferBatch(content string) {
+	m.bufferMutex.Lock()
+	defer m.bufferMutex.Unlock()
+
+	m.buffered.WriteString(content)
+	logger.Infof("Final-summary-only mode: buffered %d lines", countLines(content))
+	if m.buffered.Len() < finalOnlyBufferLimit {
+		return
+	}
+
+	summary, err := m.summarize(m.buffered.String())
+	if err != nil {
+		// Keep the most recent lines and try again with the next batch
+		logger.Warnf("Failed to summarize buffered lines: %v", err)
+		recent := tailContent(m.buffered.String(), finalOnlyBufferLimit/2)
+		m.buffered.Reset()
+		m.buffered.WriteString(recent)
+		return
+	}
+
+	m.partialSummaries = append(m.partialSummaries, summary)
+	m.buffered.Reset()
+}
+
+// bufferedContent returns the activity held back in final-summary-only mode,
+// formatted to be appended to a final summary report
+func (m *UnifiedMonitor) bufferedContent() string {
+	m.bufferMutex.Lock()
+	defer m.bufferMutex.Unlock()
+
+	var b strings.Builder
+	if len(m.partialSummaries) > 0 {
+		b.WriteString("\n=== EARLIER ACTIVITY (SUMMARIZED) ===\n")
+		for _, summary := range m.partialSummaries {
+			b.WriteString(summary)
+			b.WriteString("\n")
+		}
+	}
+	if m.buffered.Len() > 0 {
+		b.WriteString("\n=== BUFFERED LOG CONTENT ===\n")
+		b.WriteString(m.buffered.String())
+	}
+	return b.String()
+}
+
+// hasBufferedContent reports whether final-summary-only mode has held back any activity
+func (m *UnifiedMonitor) hasBufferedContent() bool {
+	m.bufferMutex.Lock()
+	defer m.bufferMutex.Unlock()
+	return m.buffered.Len() > 0 || len(m.partialSummaries) > 0
+}
+
+// sendStopSummary sends the final summary of a file monitor when it is stopped
+// in final-summary-only mode
+func (m *UnifiedMonitor) sendStopSummary(startedAt time.Time) {
+	if !m.hasBufferedContent() {
+		msg := notifier.NewMessage(notifier.MessageTypeFinalSummary, m.config.SourceLabel(), "No new log lines since monitoring started.", notifier.SeverityInfo)
+		msg.WindowStart, msg.WindowEnd = startedAt, time.Now()
+		m.sendMessageToAllNotifiers(msg)
+		return
+	}
+
+	report := fmt.Sprintf("=== MONITORING STOP SUMMARY ===\nFile: %s\nDuration: %v\n",
+		m.config.SourceLabel(), time.Since(startedAt).Round(time.Second))
+
+	logger.Info("Generating final summary...")
+	if err := m.handleFinalSummary(report); err != nil {
+		logger.Errorf("Failed to send final summary: %v", err)
+	} else {
+		logger.Info("Final summary sent successfully")
+	}
+}
+
+// summarize generates a summary of log content, using the custom template if one is set
+func (m *UnifiedMonitor) summarize(content string) (string, error) {
+	client, language, templates := m.analysisSettings()
+	if templates.SummarizeTemplate != "" {
+		return client.SummarizeWithTemplate(content, language, templates.SummarizeTemplate)
+	}
+	return client.Summarize(content, language)
+}
+
+// notifyLifecycle sends a monitor started/stopped/crashed event if lifecycle events are enabled
+func (m *UnifiedMonitor) notifyLifecycle(event, severity, detail string) {
+	if !m.config.Events.LifecycleEnabled() {
+		return
+	}
+
+	body := fmt.Sprintf("Monitor: %s\nType: %s", m.config.DisplayName(), m.config.Source.GetType())
+	if detail != "" {
+		body += "\n\n" + detail
+	}
+	msg := notifier.NewMessage(notifier.MessageTypeLifecycle, m.config.SourceLabel(), body, severity)
+	msg.Event = event
+	m.sendMessageToAllNotifiers(msg)
+}
+
+// finish stops the monitor when it is cancelled. A command is stopped and
+// waited for, so that its last batch and exit summary are sent before the
+// notifiers close; a file monitor in final-summary-only mode summarizes
+// everything it buffered.
+func (m *UnifiedMonitor) finish(errChan <-chan error, startedAt time.Time) {
+	if _, ok := m.collector.(*StreamCollector); ok {
+		m.Stop()
+		if err := <-errChan; err != nil {
+			logger.Errorf("Command monitor failed: %v", err)
+		}
+		return
+	}
+	if m.config.FinalSummaryOnly {
+		m.sendStopSummary(startedAt)
+	}
+	m.Stop()
+}
+
 // Stop stops the monitoring
 func (m *UnifiedMonitor) Stop() {
-	if streamCollector, ok := m.collector.(*StreamCollector); ok {
-		streamCollector.Stop()
+	switch c := m.collector.(type) {
+	case *StreamCollector:
+		c.Stop()
+	case *Collector:
+		c.Stop()
+	}
+}
+
+// newSummaryMessage creates a summary or alert describing the log lines it was generated from
+func newSummaryMessage(msgType notifier.MessageType, source, content, summary, severity string, windowStart, windowEnd time.Time) *notifier.Message {
+	msg := notifier.NewMessage(msgType, source, summary, severity)
+	msg.Signature = notifier.ErrorSignature(content)
+	msg.Excerpt = content
+	msg.LineCount = countLines(content)
+	msg.WindowStart = windowStart
+	msg.WindowEnd = windowEnd
+	return msg
+}
+
+// stopDetail describes why a monitor stopped, from the cause its context was
+// cancelled with
+func stopDetail(cause error) string {
+	if cause == nil || cause == context.Canceled {
+		return "Stopped"
+	}
+	message := cause.Error()
+	if message == "" {
+		return "Stopped"
+	}
+	first, size := utf8.DecodeRuneInString(message)
+	return string(unicode.ToUpper(first)) + message[size:]
+}
+
+// batchSeverity rates a batch by its log lines: error when it reports errors
+// or crashes, warning for other failures such as timeouts, info otherwise
+func batchSeverity(content string) string {
+	signature := notifier.ErrorSignature(content)
+	if signature == "" {
+		return notifier.SeverityInfo
+	}
+	for _, word := range []string{"error", "exception", "fatal", "panic", "traceback"} {
+		if strings.Contains(signature, word) {
+			return notifier.SeverityError
+		}
+	}
+	return notifier.SeverityWarning
+}
+
+// addRestartNotes lists the command restarts a batch covers below its summary
+func addRestartNotes(msg *notifier.Message, notes []string) {
+	if len(notes) == 0 {
+		return
+	}
+	msg.Body += "\n\n🔁 Command restarts:\n- " + strings.Join(notes, "\n- ")
+}
+
+// tailContent returns the last whole lines of content that fit in maxBytes
+func tailContent(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+	tail := content[len(content)-maxBytes:]
+	if index := strings.Index(tail, "\n"); index >= 0 {
+		tail = tail[index+1:]
+	}
+	return tail
+}
+
+// countLines returns the number of non-empty lines in content
+func countLines(content string) int {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// handleSilent notifies that the source has produced no output within the expected window
+func (m *UnifiedMonitor) handleSilent(silentFor time.Duration) {
+	logger.Warnf("No output from %s for %v", m.config.DisplayName(), silentFor.Round(time.Second))
+	if !m.config.Events.ErrorEnabled() {
+		logger.Info("Error alerts are disabled, skipping silence alert")
+		return
+	}
+
+	body := fmt.Sprintf("Source: %s\nNo output for %v (expected activity within %v)",
+		m.config.DisplayName(), silentFor.Round(time.Second), m.config.ExpectActivityWithin)
+	msg := notifier.NewMessage(notifier.MessageTypeError, m.config.SourceLabel(), body, notifier.SeverityWarning)
+	msg.Title = "🔇 Source Went Silent"
+	m.sendMessageToAllNotifiers(msg)
+}
+
+// handleRecovered notifies that a previously silent source is producing
+// output again. The recovery closes a silence alert, so it is sent when error
+// alerts are.
+func (m *UnifiedMonitor) handleRecovered(silentFor time.Duration) {
+	logger.Infof("Output from %s resumed after %v", m.config.DisplayName(), silentFor.Round(time.Second))
+	if !m.config.Events.ErrorEnabled() {
+		return
+	}
+
+	body := fmt.Sprintf("Source: %s\nOutput resumed after %v of silence",
+		m.config.DisplayName(), silentFor.Round(time.Second))
+	msg := notifier.NewMessage(notifier.MessageTypeLifecycle, m.config.SourceLabel(), body, notifier.SeverityInfo)
+	msg.Event = notifier.LifecycleRecovered
+	m.sendMessageToAllNotifiers(msg)
+}
+
+// sendMessageToAllNotifiers sends a message to all configured notifiers, logging failures
+func (m *UnifiedMonitor) sendMessageToAllNotifiers(msg *notifier.Message) {
+	msg.Monitor = m.config.DisplayName()
+	if m.suppressed(msg) {
+		return
+	}
+	notifiers := m.currentNotifiers()
+	sent := false
+	for _, n := range notifiers {
+		if err := n.Send(msg); err != nil {
+			logger.Errorf("Failed to send message to %s notifier: %v", n.Name(), err)
+		} else {
+			sent = true
+		}
+	}
+	if sent {
+		m.recordNotification()
+	} else if len(notifiers) > 0 {
+		m.recordFailedNotification()
 	}
 }
 
-// sendToAllNotifiers sends a summary to all configured notifiers
-func (m *UnifiedMonitor) sendToAllNotifiers(summary string) error {
+// closeNotifiers flushes and closes all notifiers
+func (m *UnifiedMonitor) closeNotifiers() {
+	for _, n := range m.currentNotifiers() {
+		if err := n.Close(); err != nil {
+			logger.Errorf("Failed to close %s notifier: %v", n.Name(), err)
+		}
+	}
+}
+
+// sendToAllNotifiers sends a message to all configured notifiers unless the
+// monitor is muted or the alert was acknowledged
+func (m *UnifiedMonitor) sendToAllNotifiers(msg *notifier.Message) error {
+	msg.Monitor = m.config.DisplayName()
+	m.recordSummary(msg)
+	if m.suppressed(msg) {
+		return nil
+	}
+	return m.deliver(msg)
+}
+
+// deliver sends a message to all configured notifiers
+func (m *UnifiedMonitor) deliver(msg *notifier.Message) error {
 	var errors []error
 	var successfulNotifiers []string
 
-	for _, n := range m.notifiers {
-		if err := n.SendLogSummary(m.config.Source.GetIdentifier(), summary); err != nil {
+	msg.Monitor = m.config.DisplayName()
+	for _, n := range m.currentNotifiers() {
+		if err := n.Send(msg); err != nil {
 			errors = append(errors, err)
 			logger.Errorf("Failed to send notification to %s notifier: %v\n", n.Name(), err)
 		} else {
@@ -284,6 +973,12 @@ func (m *UnifiedMonitor) sendToAllNotifiers(summary string) error {
 		}
 	}
 
+	if len(successfulNotifiers) > 0 {
+		m.recordNotification()
+	} else if len(errors) > 0 {
+		m.recordFailedNotification()
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("%d notifier(s) failed: %v", len(errors), errors)
 	}
@@ -0,0 +1,291 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/notifier"
+	"github.com/shiquda/lai/internal/summarizer"
+)
+
+func TestNewSummaryMessage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	content := "ERROR db timeout\n\nWARN retrying\nINFO ok\n"
+
+	msg := newSummaryMessage(notifier.MessageTypeSummary, "/var/log/app.log", content, "summary", notifier.SeverityWarning, start, end)
+
+	if msg.Type != notifier.MessageTypeSummary || msg.Source != "/var/log/app.log" || msg.Severity != notifier.SeverityWarning {
+		t.Errorf("Unexpected message header: %+v", msg)
+	}
+	if msg.LineCount != 3 {
+		t.Errorf("Expected 3 lines, got %d", msg.LineCount)
+	}
+	if !msg.WindowStart.Equal(start) || !msg.WindowEnd.Equal(end) {
+		t.Errorf("Expected window %v - %v, got %v - %v", start, end, msg.WindowStart, msg.WindowEnd)
+	}
+	if msg.Signature == "" {
+		t.Error("Expected an error signature for deduplication")
+	}
+}
+
+func TestFinalSummaryOnlyBuffersBatches(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{FinalSummaryOnly: true}}
+
+	if m.hasBufferedContent() {
+		t.Fatal("Expected an empty buffer")
+	}
+	if err := m.handleBatch("line1\nline2\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	if err := m.handleBatch("line3\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+
+	content := m.bufferedContent()
+	if !strings.Contains(content, "=== BUFFERED LOG CONTENT ===\nline1\nline2\nline3\n") {
+		t.Errorf("Expected buffered batches in final content, got %q", content)
+	}
+	if !m.windowStart.IsZero() {
+		t.Error("Buffered batches should not advance the summary window")
+	}
+}
+
+func TestTailContent(t *testing.T) {
+	if got := tailContent("short\n", 100); got != "short\n" {
+		t.Errorf("Expected content unchanged, got %q", got)
+	}
+	if got := tailContent("first line\nsecond\nthird\n", 12); got != "third\n" {
+		t.Errorf("Expected only whole trailing lines, got %q", got)
+	}
+}
+
+func TestMuteAndAckSuppressNotifications(t *testing.T) {
+	m := &UnifiedMonitor{config: &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log")}}
+	alert := &notifier.Message{Type: notifier.MessageTypeError, Signature: "sig-1"}
+
+	if m.Ack() {
+		t.Error("Expected nothing to acknowledge before the first alert")
+	}
+	if m.suppressed(alert) {
+		t.Fatal("The first alert should be sent")
+	}
+	if !m.Ack() {
+		t.Fatal("Expected the latest alert to be acknowledged")
+	}
+	if !m.suppressed(alert) {
+		t.Error("Repeats of an acknowledged alert should be suppressed")
+	}
+	if m.suppressed(&notifier.Message{Type: notifier.MessageTypeError, Signature: "sig-2"}) {
+		t.Error("A different alert should still be sent")
+	}
+
+	// Acknowledgements expire, so a recurring alert is sent again later
+	m.stateMutex.Lock()
+	m.ackedSignatures["sig-1"] = time.Now().Add(-defaultAckExpiry)
+	m.stateMutex.Unlock()
+	if m.suppressed(alert) {
+		t.Error("Repeats should be sent once the acknowledgement expired")
+	}
+	if _, ok := m.ackedSignatures["sig-1"]; ok {
+		t.Error("Expected the expired acknowledgement to be removed")
+	}
+
+	m.Mute(time.Hour)
+	if !m.suppressed(&notifier.Message{Type: notifier.MessageTypeMessage}) {
+		t.Error("Expected all messages to be suppressed while muted")
+	}
+	if status := m.Status(); status.Name != "api" || status.MutedUntil.IsZero() {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	m.Unmute()
+	if m.suppressed(&notifier.Message{Type: notifier.MessageTypeMessage}) {
+		t.Error("Expected messages to be sent after unmuting")
+	}
+}
+
+func TestBatchSeverity(t *testing.T) {
+	tests := map[string]string{
+		"INFO started\nINFO ready\n":            notifier.SeverityInfo,
+		"INFO request\nupstream timeout\n":      notifier.SeverityWarning,
+		"INFO request\nERROR db unreachable\n":  notifier.SeverityError,
+		"panic: nil map\ngoroutine 1 [running]": notifier.SeverityError,
+	}
+	for content, want := range tests {
+		if got := batchSeverity(content); got != want {
+			t.Errorf("batchSeverity(%q) = %s, want %s", content, got, want)
+		}
+	}
+}
+
+// newTestSummarizer returns a client of a fake OpenAI API that answers every
+// request with "summary"
+func newTestSummarizer(t *testing.T) *summarizer.OpenAIClient {
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summarizer.ChatCompletionResponse{
+			Choices: []summarizer.Choice{{Message: summarizer.Message{Role: "assistant", Content: "summary"}}},
+		})
+	}))
+	t.Cleanup(openai.Close)
+	return summarizer.NewOpenAIClient("test-key", openai.URL, "gpt-4o")
+}
+
+func TestNormalBatchRoutedBySeverity(t *testing.T) {
+	received := make(map[string]int)
+	webhook := func(name string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received[name]++
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+
+	cfg := &MonitorConfig{
+		Name:   "api",
+		Source: NewFileSource("/var/log/app.log"),
+		Notifications: config.NotificationsConfig{
+			Providers: map[string]config.ServiceConfig{
+				"chat":   {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": webhook("chat")}},
+				"oncall": {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": webhook("oncall")}},
+			},
+			Routing: []config.RoutingRule{
+				{Name: "errors", Severity: []string{"error"}, Providers: []string{"oncall"}},
+				{Name: "rest", Providers: []string{"chat"}},
+			},
+		},
+	}
+	notifiers, err := createNotifiers(cfg)
+	if err != nil {
+		t.Fatalf("createNotifiers failed: %v", err)
+	}
+	defer closeNotifierSet(notifiers)
+	m := &UnifiedMonitor{config: cfg, notifiers: notifiers, summarizer: newTestSummarizer(t)}
+
+	if err := m.handleBatch("INFO request served\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	if err := m.handleBatch("ERROR db unreachable\n", nil); err != nil {
+		t.Fatalf("handleBatch failed: %v", err)
+	}
+	if received["chat"] != 1 || received["oncall"] != 1 {
+		t.Errorf("Expected one summary per provider, got %v", received)
+	}
+}
+
+func TestStopDetail(t *testing.T) {
+	tests := []struct {
+		cause error
+		want  string
+	}{
+		{nil, "Stopped"},
+		{context.Canceled, "Stopped"},
+		{errors.New(""), "Stopped"},
+		{errors.New("stopped by lai stop"), "Stopped by lai stop"},
+		{errors.New("ärger"), "Ärger"},
+	}
+	for _, tt := range tests {
+		if got := stopDetail(tt.cause); got != tt.want {
+			t.Errorf("stopDetail(%v) = %q, want %q", tt.cause, got, tt.want)
+		}
+	}
+}
+
+func TestRunWaitsForCommandFinalSummaryOnStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	lifecycle := true
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:     &MonitorConfig{Name: "worker", Source: NewFileSource("worker"), FinalSummary: true, Events: config.EventsConfig{Lifecycle: &lifecycle}},
+		collector:  NewStreamCollector("sh", []string{"-c", "echo hi; sleep 5"}, 1000, 20*time.Millisecond, true, nil),
+		summarizer: newTestSummarizer(t),
+		notifiers:  []notifier.Notifier{counting},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var types []notifier.MessageType
+	for _, msg := range counting.sent {
+		types = append(types, msg.Type)
+	}
+	if len(types) != 3 || types[1] != notifier.MessageTypeFinalSummary || counting.sent[2].Event != notifier.LifecycleStopped {
+		t.Errorf("Expected the final summary before the stopped event, got %v", types)
+	}
+}
+
+func TestRunReportsFailedCommandAsStopped(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	lifecycle := true
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:    &MonitorConfig{Name: "worker", Source: NewFileSource("worker"), Events: config.EventsConfig{Lifecycle: &lifecycle}},
+		collector: NewStreamCollector("sh", []string{"-c", "exit 3"}, 1000, 20*time.Millisecond, false, nil),
+		notifiers: []notifier.Notifier{counting},
+	}
+
+	var exitErr *ExitError
+	if err := m.Run(context.Background()); !errors.As(err, &exitErr) {
+		t.Fatalf("Expected an exit error, got %v", err)
+	}
+
+	var events []string
+	errorMessages := 0
+	for _, msg := range counting.sent {
+		events = append(events, msg.Event)
+		if msg.Severity == notifier.SeverityError {
+			errorMessages++
+		}
+	}
+	last := counting.sent[len(counting.sent)-1]
+	if errorMessages != 1 || last.Event != notifier.LifecycleStopped || !strings.Contains(last.Body, "Command exited with code 3") {
+		t.Errorf("Expected one failure alert and a stopped event, got %v", events)
+	}
+}
+
+func TestSilenceAlertsAreTypedEvents(t *testing.T) {
+	counting := &countingNotifier{}
+	m := &UnifiedMonitor{
+		config:    &MonitorConfig{Name: "api", Source: NewFileSource("/var/log/app.log"), ExpectActivityWithin: time.Minute},
+		notifiers: []notifier.Notifier{counting},
+	}
+
+	m.handleSilent(2 * time.Minute)
+	m.handleRecovered(3 * time.Minute)
+	if len(counting.sent) != 2 {
+		t.Fatalf("Expected a silence and a recovery alert, got %d", len(counting.sent))
+	}
+	if silent := counting.sent[0]; silent.Type != notifier.MessageTypeError || silent.Severity != notifier.SeverityWarning {
+		t.Errorf("Expected the silence alert to be an error warning, got %s/%s", silent.Type, silent.Severity)
+	}
+	if recovered := counting.sent[1]; recovered.Type != notifier.MessageTypeLifecycle || recovered.Event != notifier.LifecycleRecovered {
+		t.Errorf("Expected the recovery to be a recovered lifecycle event, got %s/%s", recovered.Type, recovered.Event)
+	}
+
+	disabled := false
+	m.config.Events.Error = &disabled
+	m.handleSilent(2 * time.Minute)
+	m.handleRecovered(3 * time.Minute)
+	if len(counting.sent) != 2 {
+		t.Errorf("Expected no silence alerts with error alerts disabled, got %d", len(counting.sent)-2)
+	}
+}
@@ -26,6 +26,9 @@ type GlobalConfig struct {
 	PromptTemplates PromptTemplatesConfig `mapstructure:"prompt_templates" yaml:"prompt_templates"`
 	Logging         LoggingConfig         `mapstructure:"logging" yaml:"logging"`
 	Display         DisplayConfig         `mapstructure:"display" yaml:"display"`
+
+	// Monitors declares the monitors 'lai up' keeps running
+	Monitors []MonitorDefinition `mapstructure:"monitors" yaml:"monitors,omitempty"`
 }
 
 // NotificationsConfig contains the new unified notification configuration
@@ -33,6 +36,17 @@ type NotificationsConfig struct {
 	OpenAI    OpenAIConfig             `mapstructure:"openai" yaml:"openai"`
 	Providers map[string]ServiceConfig `mapstructure:"providers" yaml:"providers"`
 	Fallback  *FallbackConfig          `mapstructure:"fallback" yaml:"fallback"`
+	Routing   []RoutingRule            `mapstructure:"routing" yaml:"routing,omitempty"`
+
+	// Escalation re-sends error alerts to other providers when nobody
+	// acknowledges them in time
+	Escalation *EscalationConfig `mapstructure:"escalation" yaml:"escalation,omitempty"`
+}
+
+// EscalationConfig sends unacknowledged error alerts to a secondary provider list
+type EscalationConfig struct {
+	After     time.Duration `mapstructure:"after" yaml:"after"`         // How long an alert may stay unacknowledged
+	Providers []string      `mapstructure:"providers" yaml:"providers"` // Provider keys or types to escalate to
 }
 
 // LoggingConfig contains logging configuration
@@ -60,6 +74,44 @@ type DefaultsConfig struct {
 	FinalSummaryOnly bool          `mapstructure:"final_summary_only" yaml:"final_summary_only"`
 	ErrorOnlyMode    bool          `mapstructure:"error_only_mode" yaml:"error_only_mode"`
 	Language         string        `mapstructure:"language" yaml:"language"`
+
+	// ExpectActivityWithin raises a "source went silent" alert when a monitored
+	// source produces no output for this long. Zero disables silence detection.
+	ExpectActivityWithin time.Duration `mapstructure:"expect_activity_within" yaml:"expect_activity_within"`
+
+	// Notifiers restricts delivery to the named providers. Empty sends to every
+	// enabled provider.
+	Notifiers []string `mapstructure:"notifiers" yaml:"notifiers,omitempty"`
+
+	// DedupWindow suppresses repeated identical notifications for this long and
+	// then sends a single "repeated N times" rollup. Zero disables deduplication.
+	DedupWindow time.Duration `mapstructure:"dedup_window" yaml:"dedup_window"`
+
+	// Events switches notification event types on or off
+	Events EventsConfig `mapstructure:"events" yaml:"events,omitempty"`
+}
+
+// EventsConfig switches notification event types on or off. Unset entries use
+// their default. The final summary is controlled by final_summary.
+type EventsConfig struct {
+	Summary   *bool `mapstructure:"summary" yaml:"summary,omitempty"`     // Periodic batch summaries (default on)
+	Error     *bool `mapstructure:"error" yaml:"error,omitempty"`         // Error, failed-command and silence alerts (default on)
+	Lifecycle *bool `mapstructure:"lifecycle" yaml:"lifecycle,omitempty"` // Monitor started/stopped/crashed (default off)
+}
+
+// SummaryEnabled reports whether periodic batch summaries are sent
+func (e EventsConfig) SummaryEnabled() bool {
+	return e.Summary == nil || *e.Summary
+}
+
+// ErrorEnabled reports whether error alerts are sent
+func (e EventsConfig) ErrorEnabled() bool {
+	return e.Error == nil || *e.Error
+}
+
+// LifecycleEnabled reports whether monitor started/stopped/crashed events are sent
+func (e EventsConfig) LifecycleEnabled() bool {
+	return e.Lifecycle != nil && *e.Lifecycle
 }
 
 // PromptTemplatesConfig contains custom prompt templates for AI summarization
@@ -93,6 +145,9 @@ type Config struct {
 	// Error detection options
 	ErrorOnlyMode bool `mapstructure:"error_only_mode" yaml:"error_only_mode"`
 
+	// Notification deduplication window (0 disables)
+	DedupWindow time.Duration `mapstructure:"dedup_window" yaml:"dedup_window"`
+
 	OpenAI          OpenAIConfig          `mapstructure:"openai" yaml:"openai"`
 	Notifications   NotificationsConfig   `mapstructure:"notifications" yaml:"notifications"`
 	PromptTemplates PromptTemplatesConfig `mapstructure:"prompt_templates" yaml:"prompt_templates"`
@@ -111,6 +166,66 @@ type ServiceConfig struct {
 	Provider string                 `mapstructure:"provider" yaml:"provider"`
 	Config   map[string]interface{} `mapstructure:"config" yaml:"config"`
 	Defaults map[string]interface{} `mapstructure:"defaults" yaml:"defaults"`
+
+	// DedupWindow overrides defaults.dedup_window for this provider (0 disables)
+	DedupWindow *time.Duration `mapstructure:"dedup_window" yaml:"dedup_window,omitempty"`
+
+	// MaxPerMinute rate-limits outgoing messages (0 means unlimited). Messages over
+	// the limit are folded into a digest sent every DigestInterval (default 1m).
+	MaxPerMinute   int           `mapstructure:"max_per_minute" yaml:"max_per_minute,omitempty"`
+	DigestInterval time.Duration `mapstructure:"digest_interval" yaml:"digest_interval,omitempty"`
+
+	// Templates customize the title and text of this provider's messages
+	Templates NotificationTemplates `mapstructure:"templates" yaml:"templates,omitempty"`
+
+	// Attachments attach the raw log lines of a batch as a file, on providers
+	// that support files (telegram, slack with oauth_token, email, discord_webhook)
+	Attachments AttachmentConfig `mapstructure:"attachments" yaml:"attachments,omitempty"`
+
+	// Schedule holds back less severe messages during quiet hours and sends
+	// them as a digest when the quiet period ends
+	Schedule ScheduleConfig `mapstructure:"schedule" yaml:"schedule,omitempty"`
+}
+
+// ScheduleConfig defines a provider's quiet hours
+type ScheduleConfig struct {
+	Timezone      string `mapstructure:"timezone" yaml:"timezone,omitempty"`             // IANA name such as "Europe/Berlin"; default local time
+	QuietHours    string `mapstructure:"quiet_hours" yaml:"quiet_hours,omitempty"`       // Daily window such as "22:00-07:00"
+	QuietWeekends bool   `mapstructure:"quiet_weekends" yaml:"quiet_weekends,omitempty"` // Saturday and Sunday are quiet all day
+	MinSeverity   string `mapstructure:"min_severity" yaml:"min_severity,omitempty"`     // Lowest severity still sent while quiet (default "error")
+}
+
+// Enabled reports whether the schedule defines any quiet time
+func (s ScheduleConfig) Enabled() bool {
+	return s.QuietHours != "" || s.QuietWeekends
+}
+
+// DefaultAttachmentMaxSize is the largest log excerpt attached when max_size is not set
+const DefaultAttachmentMaxSize = 1024 * 1024
+
+// AttachmentConfig controls log excerpt attachments
+type AttachmentConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled,omitempty"`
+	MaxSize int  `mapstructure:"max_size" yaml:"max_size,omitempty"` // Bytes of log content kept (default 1 MiB); older lines are cut first
+	Gzip    bool `mapstructure:"gzip" yaml:"gzip,omitempty"`         // Compress the excerpt as a .log.gz file
+}
+
+// MaxBytes returns the size cap of an attachment
+func (a AttachmentConfig) MaxBytes() int {
+	if a.MaxSize > 0 {
+		return a.MaxSize
+	}
+	return DefaultAttachmentMaxSize
+}
+
+// NotificationTemplates are Go text/template strings rendered with
+// notifier.TemplateData. Empty templates keep the built-in formatting.
+type NotificationTemplates struct {
+	Title        string `mapstructure:"title" yaml:"title,omitempty"`                 // Title of every message
+	Body         string `mapstructure:"body" yaml:"body,omitempty"`                   // Text of summaries and plain messages
+	Error        string `mapstructure:"error" yaml:"error,omitempty"`                 // Text of error alerts
+	FinalSummary string `mapstructure:"final_summary" yaml:"final_summary,omitempty"` // Text of the summary sent when a command exits
+	Lifecycle    string `mapstructure:"lifecycle" yaml:"lifecycle,omitempty"`         // Text of monitor started/stopped/crashed events
 }
 
 // FallbackConfig represents fallback notification configuration
@@ -118,6 +233,22 @@ type FallbackConfig struct {
 	Enabled  bool                   `mapstructure:"enabled" yaml:"enabled"`
 	Provider string                 `mapstructure:"provider" yaml:"provider"`
 	Config   map[string]interface{} `mapstructure:"config" yaml:"config"`
+
+	Templates NotificationTemplates `mapstructure:"templates" yaml:"templates,omitempty"`
+}
+
+// RoutingRule sends matching notifications to a specific set of providers.
+// Rules are evaluated in order and the first matching rule wins. Notifications
+// that match no rule go to every enabled provider.
+type RoutingRule struct {
+	Name      string   `mapstructure:"name" yaml:"name,omitempty"`
+	Severity  []string `mapstructure:"severity" yaml:"severity,omitempty"` // error, warning, info (empty matches any)
+	Sources   []string `mapstructure:"sources" yaml:"sources,omitempty"`   // glob patterns (empty matches any)
+	Types     []string `mapstructure:"types" yaml:"types,omitempty"`       // summary, error, final_summary, lifecycle, message (empty matches any)
+	Providers []string `mapstructure:"providers" yaml:"providers"`
+	Hours     string   `mapstructure:"hours" yaml:"hours,omitempty"`       // e.g. "09:00-18:00", may wrap midnight
+	Days      []string `mapstructure:"days" yaml:"days,omitempty"`         // e.g. ["mon", "tue"] (empty matches any)
+	Timezone  string   `mapstructure:"timezone" yaml:"timezone,omitempty"` // IANA name, defaults to local time
 }
 
 // Legacy configurations for backward compatibility during migration
@@ -1006,6 +1137,7 @@ func BuildRuntimeConfig(logFile string, lineThreshold *int, checkInterval *time.
 		CheckInterval:   globalConfig.Defaults.CheckInterval,
 		Language:        globalConfig.Defaults.Language,
 		ErrorOnlyMode:   globalConfig.Defaults.ErrorOnlyMode,
+		DedupWindow:     globalConfig.Defaults.DedupWindow,
 		OpenAI:          globalConfig.Notifications.OpenAI,
 		Notifications:   globalConfig.Notifications,
 		PromptTemplates: globalConfig.PromptTemplates,
@@ -1050,6 +1182,7 @@ func BuildStreamConfig(command string, args []string, lineThreshold *int, checkI
 		FinalSummary:     globalConfig.Defaults.FinalSummary,
 		FinalSummaryOnly: globalConfig.Defaults.FinalSummaryOnly,
 		ErrorOnlyMode:    globalConfig.Defaults.ErrorOnlyMode,
+		DedupWindow:      globalConfig.Defaults.DedupWindow,
 		OpenAI:           globalConfig.Notifications.OpenAI,
 		Notifications:    globalConfig.Notifications,
 		PromptTemplates:  globalConfig.PromptTemplates,
@@ -155,6 +155,38 @@ func GetConfigMetadata() *ConfigMetadata {
 						DefaultValue: "false",
 						Level:        1,
 					},
+					{
+						Key:          "defaults.expect_activity_within",
+						DisplayName:  "Expect Activity Within",
+						Description:  "Alert when a source produces no output for this long (0 disables silence detection)",
+						Type:         TypeDuration,
+						Category:     CategoryDefaults,
+						Required:     false,
+						DefaultValue: "0s",
+						Examples:     []string{"5m", "30m", "1h"},
+						Level:        1,
+					},
+					{
+						Key:         "defaults.notifiers",
+						DisplayName: "Notifiers",
+						Description: "Providers to send notifications to (empty sends to all enabled providers)",
+						Type:        TypeStringList,
+						Category:    CategoryDefaults,
+						Required:    false,
+						Examples:    []string{"telegram", "pagerduty,email"},
+						Level:       1,
+					},
+					{
+						Key:          "defaults.dedup_window",
+						DisplayName:  "Dedup Window",
+						Description:  "Suppress repeated identical notifications for this long, then send a \"repeated N times\" rollup (0 disables)",
+						Type:         TypeDuration,
+						Category:     CategoryDefaults,
+						Required:     false,
+						DefaultValue: "0s",
+						Examples:     []string{"15m", "1h"},
+						Level:        1,
+					},
 				},
 			},
 			{
@@ -452,6 +484,154 @@ func GetConfigMetadata() *ConfigMetadata {
 					},
 				},
 			},
+			{
+				Name:        "gotify",
+				DisplayName: "Gotify Notifications",
+				Description: "Gotify push notification configuration for self-hosted servers",
+				Category:    CategoryProviders,
+				Level:       0,
+				Fields: []FieldMetadata{
+					{
+						Key:          "notifications.providers.gotify.enabled",
+						DisplayName:  "Enable Gotify",
+						Description:  "Whether to enable Gotify notifications",
+						Type:         TypeBool,
+						Category:     CategoryProviders,
+						Required:     false,
+						DefaultValue: "false",
+						Level:        1,
+					},
+					{
+						Key:         "notifications.providers.gotify.config.server_url",
+						DisplayName: "Server URL",
+						Description: "Gotify server address",
+						Type:        TypeString,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"https://gotify.example.com"},
+						Level:       2,
+					},
+					{
+						Key:         "notifications.providers.gotify.config.app_token",
+						DisplayName: "Application Token",
+						Description: "Token of the Gotify application used to send messages",
+						Type:        TypeSecret,
+						Category:    CategoryProviders,
+						Required:    false,
+						Sensitive:   true,
+						Level:       2,
+					},
+					{
+						Key:          "notifications.providers.gotify.config.priority",
+						DisplayName:  "Priority",
+						Description:  "Message priority (0-10); 0 derives it from the message severity",
+						Type:         TypeInt,
+						Category:     CategoryProviders,
+						Required:     false,
+						DefaultValue: "0",
+						Examples:     []string{"0", "5", "8"},
+						Level:        2,
+					},
+				},
+			},
+			{
+				Name:        "matrix",
+				DisplayName: "Matrix Notifications",
+				Description: "Matrix room notification configuration",
+				Category:    CategoryProviders,
+				Level:       0,
+				Fields: []FieldMetadata{
+					{
+						Key:          "notifications.providers.matrix.enabled",
+						DisplayName:  "Enable Matrix",
+						Description:  "Whether to enable Matrix notifications",
+						Type:         TypeBool,
+						Category:     CategoryProviders,
+						Required:     false,
+						DefaultValue: "false",
+						Level:        1,
+					},
+					{
+						Key:         "notifications.providers.matrix.config.homeserver",
+						DisplayName: "Homeserver",
+						Description: "Matrix homeserver URL",
+						Type:        TypeString,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"https://matrix.org", "https://matrix.example.com"},
+						Level:       2,
+					},
+					{
+						Key:         "notifications.providers.matrix.config.access_token",
+						DisplayName: "Access Token",
+						Description: "Access token of the account that posts messages",
+						Type:        TypeSecret,
+						Category:    CategoryProviders,
+						Required:    false,
+						Sensitive:   true,
+						Level:       2,
+					},
+					{
+						Key:         "notifications.providers.matrix.config.room_id",
+						DisplayName: "Room ID",
+						Description: "Internal ID of the room to send messages to",
+						Type:        TypeString,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"!abcdefghijkl:matrix.org"},
+						Level:       2,
+					},
+				},
+			},
+			{
+				Name:        "signal",
+				DisplayName: "Signal Notifications",
+				Description: "Signal notification configuration via a signal-cli REST API server",
+				Category:    CategoryProviders,
+				Level:       0,
+				Fields: []FieldMetadata{
+					{
+						Key:          "notifications.providers.signal.enabled",
+						DisplayName:  "Enable Signal",
+						Description:  "Whether to enable Signal notifications",
+						Type:         TypeBool,
+						Category:     CategoryProviders,
+						Required:     false,
+						DefaultValue: "false",
+						Level:        1,
+					},
+					{
+						Key:         "notifications.providers.signal.config.api_url",
+						DisplayName: "API URL",
+						Description: "signal-cli REST API server address",
+						Type:        TypeString,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"http://localhost:8080"},
+						Level:       2,
+					},
+					{
+						Key:         "notifications.providers.signal.config.number",
+						DisplayName: "Sender Number",
+						Description: "Phone number registered with signal-cli",
+						Type:        TypeString,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"+15551234567"},
+						Level:       2,
+					},
+					{
+						Key:         "notifications.providers.signal.config.recipients",
+						DisplayName: "Recipients",
+						Description: "Phone numbers or group IDs to send messages to",
+						Type:        TypeStringList,
+						Category:    CategoryProviders,
+						Required:    false,
+						Examples:    []string{"+15557654321,+15559876543"},
+						Level:       2,
+					},
+				},
+			},
 			{
 				Name:        "logging",
 				DisplayName: "Logging Configuration",
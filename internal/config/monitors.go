@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shiquda/lai/internal/daemon"
+)
+
+// MonitorDefinition declares a monitor that 'lai up' keeps running as a daemon.
+// Unset options fall back to the defaults section.
+type MonitorDefinition struct {
+	Name       string       `mapstructure:"name" yaml:"name"`
+	Type       string       `mapstructure:"type" yaml:"type"`                         // file or exec
+	Source     string       `mapstructure:"source" yaml:"source"`                     // Log file path or command line
+	WorkingDir string       `mapstructure:"working_dir" yaml:"working_dir,omitempty"` // Directory commands run in
+	Template   string       `mapstructure:"template" yaml:"template,omitempty"`       // Summarize prompt template
+	Notifiers  []string     `mapstructure:"notifiers" yaml:"notifiers,omitempty"`     // Providers to send to
+	Filters    FilterConfig `mapstructure:"filters" yaml:"filters,omitempty"`         // Lines to keep or drop before summarizing
+
+	LineThreshold        *int           `mapstructure:"line_threshold" yaml:"line_threshold,omitempty"`
+	CheckInterval        *time.Duration `mapstructure:"check_interval" yaml:"check_interval,omitempty"`
+	ErrorOnlyMode        *bool          `mapstructure:"error_only_mode" yaml:"error_only_mode,omitempty"`
+	FinalSummary         *bool          `mapstructure:"final_summary" yaml:"final_summary,omitempty"`
+	FinalSummaryOnly     *bool          `mapstructure:"final_summary_only" yaml:"final_summary_only,omitempty"`
+	ExpectActivityWithin *time.Duration `mapstructure:"expect_activity_within" yaml:"expect_activity_within,omitempty"`
+
+	// Restart policy: no, always or on-failure
+	Restart      string         `mapstructure:"restart" yaml:"restart,omitempty"`
+	RestartDelay *time.Duration `mapstructure:"restart_delay" yaml:"restart_delay,omitempty"`
+}
+
+// FilterConfig selects the log lines that are summarized. Patterns are
+// regular expressions; with include patterns only matching lines are kept.
+type FilterConfig struct {
+	Include []string `mapstructure:"include" yaml:"include,omitempty"`
+	Exclude []string `mapstructure:"exclude" yaml:"exclude,omitempty"`
+}
+
+// monitorNamePattern restricts monitor names to ones usable as process IDs
+var monitorNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// MonitorsFile is a standalone file declaring monitors, such as lai.yaml
+type MonitorsFile struct {
+	Monitors []MonitorDefinition `mapstructure:"monitors" yaml:"monitors"`
+}
+
+// LoadMonitorsFile reads the monitors declared in a standalone file
+func LoadMonitorsFile(path string) ([]MonitorDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read monitors file: %w", err)
+	}
+
+	var rawConfig map[string]interface{}
+	if err := yaml.Unmarshal(data, &rawConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse monitors file yaml: %w", err)
+	}
+
+	var file MonitorsFile
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+		),
+		Result: &file,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decoder: %w", err)
+	}
+	if err := decoder.Decode(rawConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode monitors file: %w", err)
+	}
+
+	return file.Monitors, nil
+}
+
+// ValidateMonitors checks a list of monitor definitions, including that
+// their names are unique
+func ValidateMonitors(monitors []MonitorDefinition, templates PromptTemplatesConfig) error {
+	seen := make(map[string]bool)
+	for i, monitor := range monitors {
+		if err := monitor.Validate(templates); err != nil {
+			if monitor.Name == "" {
+				return fmt.Errorf("monitors[%d]: %w", i, err)
+			}
+			return fmt.Errorf("monitor %s: %w", monitor.Name, err)
+		}
+		if seen[monitor.Name] {
+			return fmt.Errorf("monitor %s is declared more than once", monitor.Name)
+		}
+		seen[monitor.Name] = true
+	}
+	return nil
+}
+
+// Validate checks a monitor definition. The template may use the custom
+// variables of the global prompt templates.
+func (m MonitorDefinition) Validate(templates PromptTemplatesConfig) error {
+	if !monitorNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("name is required and may only contain letters, digits, '.', '_' and '-'")
+	}
+	switch m.Type {
+	case "file", "exec":
+	default:
+		return fmt.Errorf("type must be file or exec, got %q", m.Type)
+	}
+	if m.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	if m.LineThreshold != nil && *m.LineThreshold <= 0 {
+		return fmt.Errorf("line_threshold must be positive")
+	}
+	if m.CheckInterval != nil && *m.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be positive")
+	}
+	if err := daemon.ValidateRestartPolicy(m.Restart); err != nil {
+		return err
+	}
+	if m.RestartDelay != nil {
+		// Only exec monitors relaunch a command; lai file has no --restart-delay
+		if m.Type != "exec" {
+			return fmt.Errorf("restart_delay is only supported for exec monitors")
+		}
+		// Shorter delays relaunch a command that exits at once in a tight loop
+		if *m.RestartDelay < time.Second {
+			return fmt.Errorf("restart_delay must be at least 1s")
+		}
+	}
+	for _, pattern := range append(append([]string{}, m.Filters.Include...), m.Filters.Exclude...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid filter %q: %w", pattern, err)
+		}
+	}
+	if m.Template != "" {
+		templates.SummarizeTemplate = m.Template
+		templates.ErrorAnalysisTemplate = ""
+		if err := (&Config{PromptTemplates: templates}).validatePromptTemplates(); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMonitorsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lai.yaml")
+	content := `monitors:
+  - name: nginx
+    type: file
+    source: /var/log/nginx/error.log
+    line_threshold: 5
+    check_interval: 15s
+    notifiers: [slack]
+    filters:
+      include: ["error", "crit"]
+      exclude: ["healthcheck"]
+  - name: worker
+    type: exec
+    source: python worker.py
+    working_dir: ./app
+    restart: on-failure
+    restart_delay: 10s
+    template: "Summarize {{log_content}} in {{language}}"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	monitors, err := LoadMonitorsFile(path)
+	require.NoError(t, err)
+	require.Len(t, monitors, 2)
+
+	nginx := monitors[0]
+	assert.Equal(t, "nginx", nginx.Name)
+	assert.Equal(t, "file", nginx.Type)
+	require.NotNil(t, nginx.LineThreshold)
+	assert.Equal(t, 5, *nginx.LineThreshold)
+	require.NotNil(t, nginx.CheckInterval)
+	assert.Equal(t, 15*time.Second, *nginx.CheckInterval)
+	assert.Equal(t, []string{"slack"}, nginx.Notifiers)
+	assert.Equal(t, []string{"error", "crit"}, nginx.Filters.Include)
+	assert.Equal(t, []string{"healthcheck"}, nginx.Filters.Exclude)
+	assert.Nil(t, nginx.ErrorOnlyMode)
+
+	worker := monitors[1]
+	assert.Equal(t, "./app", worker.WorkingDir)
+	assert.Equal(t, "on-failure", worker.Restart)
+	require.NotNil(t, worker.RestartDelay)
+	assert.Equal(t, 10*time.Second, *worker.RestartDelay)
+	assert.Equal(t, "Summarize {{log_content}} in {{language}}", worker.Template)
+
+	assert.NoError(t, ValidateMonitors(monitors, PromptTemplatesConfig{}))
+}
+
+func TestLoadMonitorsFile_Missing(t *testing.T) {
+	_, err := LoadMonitorsFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestValidateMonitors(t *testing.T) {
+	zero := 0
+	delay := 10 * time.Second
+	noDelay := time.Duration(0)
+	valid := func() MonitorDefinition {
+		return MonitorDefinition{Name: "app", Type: "file", Source: "/var/log/app.log"}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(m *MonitorDefinition)
+		wantErr string
+	}{
+		{"valid", func(m *MonitorDefinition) {}, ""},
+		{"missing name", func(m *MonitorDefinition) { m.Name = "" }, "name is required"},
+		{"name with slash", func(m *MonitorDefinition) { m.Name = "a/b" }, "name is required"},
+		{"bad type", func(m *MonitorDefinition) { m.Type = "socket" }, "type must be file or exec"},
+		{"missing source", func(m *MonitorDefinition) { m.Source = "" }, "source is required"},
+		{"zero threshold", func(m *MonitorDefinition) { m.LineThreshold = &zero }, "line_threshold must be positive"},
+		{"bad restart", func(m *MonitorDefinition) { m.Restart = "sometimes" }, "invalid restart policy"},
+		{"restart_delay on file", func(m *MonitorDefinition) { m.RestartDelay = &delay }, "only supported for exec monitors"},
+		{"restart_delay on exec", func(m *MonitorDefinition) { m.Type = "exec"; m.RestartDelay = &delay }, ""},
+		{"zero restart_delay", func(m *MonitorDefinition) { m.Type = "exec"; m.RestartDelay = &noDelay }, "restart_delay must be at least 1s"},
+		{"bad filter", func(m *MonitorDefinition) { m.Filters.Exclude = []string{"("} }, "invalid filter"},
+		{"bad template", func(m *MonitorDefinition) { m.Template = "Analyze {{unknown_variable}}" }, "invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := valid()
+			tt.modify(&monitor)
+			err := ValidateMonitors([]MonitorDefinition{monitor}, PromptTemplatesConfig{})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateMonitors_DuplicateNames(t *testing.T) {
+	monitors := []MonitorDefinition{
+		{Name: "app", Type: "file", Source: "/a.log"},
+		{Name: "app", Type: "exec", Source: "tail -f /b.log"},
+	}
+	err := ValidateMonitors(monitors, PromptTemplatesConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "declared more than once")
+}
@@ -0,0 +1,185 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shiquda/lai/internal/logger"
+)
+
+// DefaultTimeout bounds a whole request, from connecting to reading the response
+const DefaultTimeout = 30 * time.Second
+
+// Commands understood by the control sockets of daemons and the agent
+const (
+	CommandStatus      = "status"       // State and counters of a monitor (all monitors of the agent)
+	CommandCounters    = "counters"     // Counters of a monitor
+	CommandPause       = "pause"        // Stop summarizing new lines, for Request.Duration if set
+	CommandResume      = "resume"       // Summarize new lines again, sending the maintenance digest
+	CommandFlush       = "flush"        // Summarize pending lines now
+	CommandReload      = "reload"       // Re-read the configuration
+	CommandLastSummary = "last-summary" // Latest summary or alert
+	CommandAck         = "ack"          // Acknowledge the latest alert
+	CommandMute        = "mute"         // Mute notifications for Request.Duration
+	CommandUnmute      = "unmute"       // Lift a mute
+	CommandStop        = "stop"         // Stop a daemon, or one monitor of the agent
+	CommandShutdown    = "shutdown"     // Stop the agent
+)
+
+// ErrUnavailable is returned by Call when nothing listens on the socket
+var ErrUnavailable = errors.New("control socket unavailable")
+
+// Request is a command sent to a control socket
+type Request struct {
+	Command string `json:"command"`
+	// Monitor selects a monitor of a process hosting several
+	Monitor string `json:"monitor,omitempty"`
+
+	// Duration limits a pause (zero pauses until resumed) or sets the length
+	// of a mute, and Drop discards the lines collected while paused instead of
+	// summarizing them on resume
+	Duration time.Duration `json:"duration,omitempty"`
+	Drop     bool          `json:"drop,omitempty"`
+}
+
+// Response is the answer to a request. Data holds the command's result.
+type Response struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Handler executes a request and returns its result, which is encoded as JSON
+type Handler func(req Request) (interface{}, error)
+
+// Server serves a control socket: a local Unix socket that accepts one JSON
+// request per connection and answers with one JSON response
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Listen creates the control socket at path. A socket left behind by a
+// process that died is replaced; one that still answers is an error.
+func Listen(path string, handler Handler) (*Server, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket %s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale control socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict control socket: %w", err)
+	}
+
+	return &Server{path: path, listener: listener, handler: handler}, nil
+}
+
+// Path returns the socket path
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Serve accepts connections until the server is closed
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Errorf("Control socket accept failed: %v", err)
+			}
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn answers the single request of a connection
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(DefaultTimeout))
+
+	var req Request
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else if result, err := s.handler(req); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.OK = true
+		if result != nil {
+			if resp.Data, err = json.Marshal(result); err != nil {
+				resp.OK = false
+				resp.Error = fmt.Sprintf("failed to encode result: %v", err)
+			}
+		}
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		logger.Warnf("Failed to write control response: %v", err)
+	}
+}
+
+// Close stops accepting requests, waits for the ones in progress and removes
+// the socket
+func (s *Server) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.listener.Close()
+		s.wg.Wait()
+		os.Remove(s.path)
+	})
+	return err
+}
+
+// Call sends a request to the control socket at path and decodes the result
+// into result, which may be nil
+func Call(path string, req Request, result interface{}) error {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(DefaultTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	if result != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package control
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCallRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	server, err := Listen(path, func(req Request) (interface{}, error) {
+		switch req.Command {
+		case "echo":
+			return map[string]string{"monitor": req.Monitor}, nil
+		case "empty":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unknown command %q", req.Command)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+	defer server.Close()
+
+	var result map[string]string
+	if err := Call(path, Request{Command: "echo", Monitor: "api"}, &result); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if result["monitor"] != "api" {
+		t.Errorf("Expected monitor api, got %v", result)
+	}
+
+	if err := Call(path, Request{Command: "empty"}, nil); err != nil {
+		t.Errorf("Expected empty result to succeed, got %v", err)
+	}
+
+	err = Call(path, Request{Command: "bogus"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown command "bogus"`) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+}
+
+func TestListenRejectsSocketInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	handler := func(req Request) (interface{}, error) { return nil, nil }
+
+	server, err := Listen(path, handler)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+
+	if _, err := Listen(path, handler); err == nil {
+		t.Error("Expected a second server on the same socket to fail")
+	}
+
+	server.Close()
+	if err := Call(path, Request{Command: "status"}, nil); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable after close, got %v", err)
+	}
+
+	// The socket can be reused once the first server is gone
+	server, err = Listen(path, handler)
+	if err != nil {
+		t.Fatalf("Expected socket to be reusable: %v", err)
+	}
+	server.Close()
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/shiquda/lai/internal/control"
 	"github.com/shiquda/lai/internal/logger"
 	"github.com/shiquda/lai/internal/platform"
 )
@@ -19,6 +20,65 @@ type ProcessInfo struct {
 	LogFile   string    `json:"log_file"`
 	StartTime time.Time `json:"start_time"`
 	Status    string    `json:"status"`
+
+	// Launch records how the monitor was started so it can be resumed with the
+	// same source and settings. Records from older versions have none.
+	Launch *LaunchSpec `json:"launch,omitempty"`
+
+	// Exit records how the daemon last exited on its own: ExitClean or
+	// ExitFailed. It is empty while running and after a crash or kill.
+	Exit string `json:"exit,omitempty"`
+
+	// StopRequested is set by 'lai stop' so the supervisor leaves the daemon stopped
+	StopRequested bool `json:"stop_requested,omitempty"`
+
+	// Restarts counts the supervisor's restarts since the daemon was last
+	// started or had been running steadily
+	Restarts int `json:"restarts,omitempty"`
+}
+
+// How a daemon exited, recorded in ProcessInfo.Exit
+const (
+	ExitClean  = "clean"
+	ExitFailed = "failed"
+)
+
+// Monitor types of a launch spec
+const (
+	MonitorTypeFile = "file"
+	MonitorTypeExec = "exec"
+)
+
+// LaunchSpec describes the monitor a daemon process runs
+type LaunchSpec struct {
+	Type    string        `json:"type"`    // MonitorTypeFile or MonitorTypeExec
+	Source  string        `json:"source"`  // Log file path or command line
+	Args    []string      `json:"args"`    // Positional arguments of the file or exec command
+	Options LaunchOptions `json:"options"` // Resolved command-line options
+
+	// Config is the monitors file that declares this monitor, empty for
+	// monitors started from the command line
+	Config string `json:"config,omitempty"`
+}
+
+// LaunchOptions are the command-line options a monitor was started with.
+// Unset options fall back to the global config.
+type LaunchOptions struct {
+	Name                 string         `json:"name,omitempty"`
+	LineThreshold        *int           `json:"line_threshold,omitempty"`
+	CheckInterval        *time.Duration `json:"check_interval,omitempty"`
+	ChatID               *string        `json:"chat_id,omitempty"`
+	WorkingDir           string         `json:"working_dir,omitempty"`
+	FinalSummary         *bool          `json:"final_summary,omitempty"`
+	ErrorOnlyMode        *bool          `json:"error_only_mode,omitempty"`
+	FinalSummaryOnly     *bool          `json:"final_summary_only,omitempty"`
+	Notifiers            []string       `json:"notifiers,omitempty"`
+	ExpectActivityWithin *time.Duration `json:"expect_activity_within,omitempty"`
+	Restart              string         `json:"restart,omitempty"` // Restart policy, see RestartAlways
+	RestartDelay         *time.Duration `json:"restart_delay,omitempty"`
+	Include              []string       `json:"include,omitempty"`
+	Exclude              []string       `json:"exclude,omitempty"`
+	Template             string         `json:"template,omitempty"`
 }
 
 // Manager handles daemon process management
@@ -161,6 +221,8 @@ func (m *Manager) RemoveProcessInfo(processID string) error {
 	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove process info: %w", err)
 	}
+	// A daemon that crashed leaves its control socket behind
+	os.Remove(m.ControlSocketPath(processID))
 	return nil
 }
 
@@ -169,6 +231,26 @@ func (m *Manager) GetProcessLogPath(processID string) string {
 	return filepath.Join(m.logDir, processID+".log")
 }
 
+// ControlSocketPath returns the control socket of a daemon
+func (m *Manager) ControlSocketPath(processID string) string {
+	return filepath.Join(m.processDir, processID+".sock")
+}
+
+// AgentSocketPath returns the control socket of the agent
+func (m *Manager) AgentSocketPath() string {
+	return filepath.Join(filepath.Dir(m.processDir), "agent.sock")
+}
+
+// AgentPidPath returns the PID file of the agent
+func (m *Manager) AgentPidPath() string {
+	return filepath.Join(filepath.Dir(m.processDir), "agent.pid")
+}
+
+// AgentLogPath returns the log file of an agent running in the background
+func (m *Manager) AgentLogPath() string {
+	return filepath.Join(filepath.Dir(m.processDir), "agent.log")
+}
+
 // StopProcess stops a daemon process
 func (m *Manager) StopProcess(processID string) error {
 	info, err := m.LoadProcessInfo(processID)
@@ -176,6 +258,10 @@ func (m *Manager) StopProcess(processID string) error {
 		return fmt.Errorf("failed to load process info: %w", err)
 	}
 
+	// Record the request first so the daemon keeps it when it saves its exit
+	// and the supervisor does not restart it
+	info.StopRequested = true
+
 	// Check if process is still running
 	if !m.isProcessRunning(info.PID) {
 		// Process already stopped, just update status
@@ -183,6 +269,20 @@ func (m *Manager) StopProcess(processID string) error {
 		return m.SaveProcessInfo(info)
 	}
 
+	if err := m.SaveProcessInfo(info); err != nil {
+		return err
+	}
+
+	// Ask the daemon to stop through its control API. It sends what it still
+	// has to send, such as the final summary of a command, and records its
+	// own exit.
+	if err := control.Call(m.ControlSocketPath(processID), control.Request{Command: control.CommandStop}, nil); err == nil {
+		if m.waitForExit(info.PID, controlStopTimeout) {
+			return m.markStopped(processID)
+		}
+		logger.Warnf("Process %s did not stop within %v, sending a signal", processID, controlStopTimeout)
+	}
+
 	// Try graceful termination first
 	if err := m.platform.Process.TerminateProcess(info.PID); err != nil {
 		// If graceful termination fails, try force kill
@@ -196,6 +296,33 @@ func (m *Manager) StopProcess(processID string) error {
 	return m.SaveProcessInfo(info)
 }
 
+// controlStopTimeout is how long StopProcess waits for a daemon asked to stop
+// through its control API before falling back to signals
+const controlStopTimeout = 30 * time.Second
+
+// waitForExit waits until a process exits, up to timeout
+func (m *Manager) waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for m.isProcessRunning(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// markStopped records a daemon that exited as stopped, keeping the exit it saved
+func (m *Manager) markStopped(processID string) error {
+	info, err := m.LoadProcessInfo(processID)
+	if err != nil {
+		return fmt.Errorf("failed to load process info: %w", err)
+	}
+	info.Status = "stopped"
+	info.StopRequested = true
+	return m.SaveProcessInfo(info)
+}
+
 // StopAllProcesses stops all daemon processes
 func (m *Manager) StopAllProcesses() error {
 	processes, err := m.ListProcesses()
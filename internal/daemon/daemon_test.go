@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/shiquda/lai/internal/control"
 )
 
 func TestNewManager(t *testing.T) {
@@ -403,3 +406,109 @@ func TestProcessInfoJSONMarshaling(t *testing.T) {
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && s[len(s)-len(substr):] != substr && s[:len(substr)] != substr || s == substr || (len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr))
 }
+
+func TestLaunchSpecRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	threshold := 20
+	interval := 2 * time.Minute
+	errorOnly := true
+	info := &ProcessInfo{
+		ID:      "worker",
+		PID:     12345,
+		LogFile: "COMMAND_SOURCE:python worker.py",
+		Status:  "running",
+		Launch: &LaunchSpec{
+			Type:   MonitorTypeExec,
+			Source: "python worker.py",
+			Args:   []string{"python", "worker.py"},
+			Options: LaunchOptions{
+				Name:          "worker",
+				LineThreshold: &threshold,
+				CheckInterval: &interval,
+				ErrorOnlyMode: &errorOnly,
+				WorkingDir:    "/srv/app",
+				Notifiers:     []string{"slack"},
+			},
+		},
+	}
+	if err := manager.SaveProcessInfo(info); err != nil {
+		t.Fatalf("Failed to save process info: %v", err)
+	}
+
+	loaded, err := manager.LoadProcessInfo("worker")
+	if err != nil {
+		t.Fatalf("Failed to load process info: %v", err)
+	}
+	if loaded.Launch == nil {
+		t.Fatal("Launch spec was not saved")
+	}
+	if loaded.Launch.Type != MonitorTypeExec || len(loaded.Launch.Args) != 2 {
+		t.Errorf("Launch spec mismatch: %+v", loaded.Launch)
+	}
+	options := loaded.Launch.Options
+	if *options.LineThreshold != 20 || *options.CheckInterval != interval || !*options.ErrorOnlyMode {
+		t.Errorf("Launch options mismatch: %+v", options)
+	}
+	if options.FinalSummary != nil {
+		t.Error("Unset options should stay unset")
+	}
+}
+
+func TestStopProcessUsesControlAPI(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("NewManagerWithDirs failed: %v", err)
+	}
+
+	child := exec.Command("sleep", "30")
+	if err := child.Start(); err != nil {
+		t.Skipf("Cannot start a test process: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		child.Wait()
+		close(exited)
+	}()
+	defer child.Process.Kill()
+
+	if err := manager.SaveProcessInfo(&ProcessInfo{ID: "api", PID: child.Process.Pid, Status: "running"}); err != nil {
+		t.Fatalf("SaveProcessInfo failed: %v", err)
+	}
+
+	// The fake daemon exits when asked through its control socket
+	var commands []string
+	server, err := control.Listen(manager.ControlSocketPath("api"), func(req control.Request) (interface{}, error) {
+		commands = append(commands, req.Command)
+		if req.Command == control.CommandStop {
+			child.Process.Kill()
+			<-exited
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve()
+	defer server.Close()
+
+	if err := manager.StopProcess("api"); err != nil {
+		t.Fatalf("StopProcess failed: %v", err)
+	}
+	if len(commands) != 1 || commands[0] != control.CommandStop {
+		t.Errorf("Expected a stop request, got %v", commands)
+	}
+
+	info, err := manager.LoadProcessInfo("api")
+	if err != nil {
+		t.Fatalf("LoadProcessInfo failed: %v", err)
+	}
+	if info.Status != "stopped" || !info.StopRequested {
+		t.Errorf("Expected a stopped record with the request kept, got %+v", info)
+	}
+}
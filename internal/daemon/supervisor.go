@@ -0,0 +1,211 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/shiquda/lai/internal/logger"
+)
+
+// Restart policies of a daemon process, and of the command of a lai exec
+// monitor, as accepted by --restart
+const (
+	RestartNo        = "no"
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+)
+
+// ValidateRestartPolicy checks a restart policy. An empty policy means no.
+func ValidateRestartPolicy(policy string) error {
+	switch policy {
+	case "", RestartNo, RestartAlways, RestartOnFailure:
+		return nil
+	default:
+		return fmt.Errorf("invalid restart policy %q (valid: no, always, on-failure)", policy)
+	}
+}
+
+// NeedsRestart reports whether a daemon that is no longer running should be
+// restarted under its restart policy. Daemons stopped with 'lai stop' are
+// never restarted, and on-failure skips daemons that exited cleanly.
+func NeedsRestart(info *ProcessInfo) bool {
+	if info.StopRequested || info.Launch == nil {
+		return false
+	}
+	switch info.Launch.Options.Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return info.Exit != ExitClean
+	default:
+		return false
+	}
+}
+
+// SupervisorConfig controls how the supervisor restarts daemons
+type SupervisorConfig struct {
+	Interval    time.Duration // How often daemons are checked
+	Backoff     time.Duration // Delay before the first restart, doubled for each further one
+	MaxBackoff  time.Duration // Longest delay between restarts
+	MaxRestarts int           // Restarts before giving up, 0 for no limit
+	ResetAfter  time.Duration // Uptime after which the restart count is reset
+}
+
+// DefaultSupervisorConfig returns the default supervisor settings
+func DefaultSupervisorConfig() SupervisorConfig {
+	return SupervisorConfig{
+		Interval:    10 * time.Second,
+		Backoff:     5 * time.Second,
+		MaxBackoff:  5 * time.Minute,
+		MaxRestarts: 5,
+		ResetAfter:  10 * time.Minute,
+	}
+}
+
+// Supervisor watches registered daemons and restarts the ones that died,
+// following their restart policy
+type Supervisor struct {
+	manager  *Manager
+	config   SupervisorConfig
+	restart  func(info *ProcessInfo) error
+	onGiveUp func(info *ProcessInfo, reason string)
+	now      func() time.Time
+
+	pending map[string]time.Time // Scheduled restart time by process ID
+	gaveUp  map[string]bool
+}
+
+// NewSupervisor creates a supervisor. restart relaunches a daemon from its
+// process info and saves the new PID; onGiveUp is called once when a daemon
+// reaches the restart limit.
+func NewSupervisor(manager *Manager, cfg SupervisorConfig, restart func(info *ProcessInfo) error, onGiveUp func(info *ProcessInfo, reason string)) *Supervisor {
+	return &Supervisor{
+		manager:  manager,
+		config:   cfg,
+		restart:  restart,
+		onGiveUp: onGiveUp,
+		now:      time.Now,
+		pending:  make(map[string]time.Time),
+		gaveUp:   make(map[string]bool),
+	}
+}
+
+// Run checks the daemons every interval until the context is cancelled
+func (s *Supervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check makes one pass over the registered daemons, scheduling restarts for
+// the ones that died and performing the restarts that are due
+func (s *Supervisor) Check() {
+	processes, err := s.manager.ListProcesses()
+	if err != nil {
+		logger.Errorf("Supervisor failed to list processes: %v", err)
+		return
+	}
+
+	now := s.now()
+	for _, info := range processes {
+		if info.Status == "running" {
+			s.checkRunning(info, now)
+			continue
+		}
+		if !NeedsRestart(info) {
+			delete(s.pending, info.ID)
+			delete(s.gaveUp, info.ID)
+			continue
+		}
+		if s.gaveUp[info.ID] {
+			continue
+		}
+
+		if s.config.MaxRestarts > 0 && info.Restarts >= s.config.MaxRestarts {
+			s.gaveUp[info.ID] = true
+			delete(s.pending, info.ID)
+			reason := fmt.Sprintf("Gave up after %d restarts (%s)", info.Restarts, exitDescription(info))
+			logger.Errorf("Supervisor: %s: %s", info.ID, reason)
+			if s.onGiveUp != nil {
+				s.onGiveUp(info, reason)
+			}
+			continue
+		}
+
+		due, scheduled := s.pending[info.ID]
+		if !scheduled {
+			delay := s.backoff(info.Restarts)
+			s.pending[info.ID] = now.Add(delay)
+			logger.Warnf("Supervisor: %s is down (%s), restarting in %v", info.ID, exitDescription(info), delay)
+			continue
+		}
+		if now.Before(due) {
+			continue
+		}
+
+		delete(s.pending, info.ID)
+		info.Restarts++
+		if err := s.restart(info); err != nil {
+			logger.Errorf("Supervisor: failed to restart %s: %v", info.ID, err)
+			// A failed attempt counts towards the limit
+			info.Status = "stopped"
+			if saveErr := s.manager.SaveProcessInfo(info); saveErr != nil {
+				logger.Errorf("Supervisor: failed to save process info: %v", saveErr)
+			}
+			continue
+		}
+		logger.Infof("Supervisor: restarted %s (restart %d, PID %d)", info.ID, info.Restarts, info.PID)
+	}
+}
+
+// checkRunning resets the restart count of a daemon that has stayed up
+func (s *Supervisor) checkRunning(info *ProcessInfo, now time.Time) {
+	delete(s.pending, info.ID)
+	delete(s.gaveUp, info.ID)
+	if info.Restarts == 0 || now.Sub(info.StartTime) < s.config.ResetAfter {
+		return
+	}
+	info.Restarts = 0
+	if err := s.manager.SaveProcessInfo(info); err != nil {
+		logger.Errorf("Supervisor: failed to save process info: %v", err)
+	}
+}
+
+// backoff returns the delay before a daemon's next restart
+func (s *Supervisor) backoff(restarts int) time.Duration {
+	delay := s.config.Backoff
+	for i := 0; i < restarts && delay < s.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if s.config.MaxBackoff > 0 && delay > s.config.MaxBackoff {
+		delay = s.config.MaxBackoff
+	}
+	return delay
+}
+
+// exitDescription describes how a daemon stopped
+func exitDescription(info *ProcessInfo) string {
+	switch info.Exit {
+	case ExitClean:
+		return "exited"
+	case ExitFailed:
+		return "failed"
+	default:
+		return "crashed"
+	}
+}
+
+// SupervisorPidPath returns the PID file that keeps a single supervisor running
+func (m *Manager) SupervisorPidPath() string {
+	return filepath.Join(filepath.Dir(m.processDir), "supervisor.pid")
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNeedsRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     ProcessInfo
+		expected bool
+	}{
+		{"no policy", ProcessInfo{Launch: &LaunchSpec{}}, false},
+		{"legacy record", ProcessInfo{}, false},
+		{"always after clean exit", ProcessInfo{Exit: ExitClean, Launch: &LaunchSpec{Options: LaunchOptions{Restart: RestartAlways}}}, true},
+		{"on-failure after clean exit", ProcessInfo{Exit: ExitClean, Launch: &LaunchSpec{Options: LaunchOptions{Restart: RestartOnFailure}}}, false},
+		{"on-failure after failure", ProcessInfo{Exit: ExitFailed, Launch: &LaunchSpec{Options: LaunchOptions{Restart: RestartOnFailure}}}, true},
+		{"on-failure after crash", ProcessInfo{Launch: &LaunchSpec{Options: LaunchOptions{Restart: RestartOnFailure}}}, true},
+		{"stopped by user", ProcessInfo{StopRequested: true, Launch: &LaunchSpec{Options: LaunchOptions{Restart: RestartAlways}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRestart(&tt.info); got != tt.expected {
+				t.Errorf("NeedsRestart() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateRestartPolicy(t *testing.T) {
+	for _, policy := range []string{"", "no", "always", "on-failure"} {
+		if err := ValidateRestartPolicy(policy); err != nil {
+			t.Errorf("Policy %q should be valid: %v", policy, err)
+		}
+	}
+	if err := ValidateRestartPolicy("sometimes"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestSupervisorRestartsWithBackoffAndGivesUp(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	crashed := &ProcessInfo{
+		ID:     "worker",
+		PID:    99999999, // Non-existent PID, died without recording an exit
+		Status: "running",
+		Launch: &LaunchSpec{Type: MonitorTypeExec, Options: LaunchOptions{Restart: RestartOnFailure}},
+	}
+	if err := manager.SaveProcessInfo(crashed); err != nil {
+		t.Fatalf("Failed to save process info: %v", err)
+	}
+
+	var restarts []int
+	var gaveUp string
+	restart := func(info *ProcessInfo) error {
+		restarts = append(restarts, info.Restarts)
+		// The relaunched daemon crashes again right away
+		info.Status = "stopped"
+		return manager.SaveProcessInfo(info)
+	}
+	cfg := SupervisorConfig{Backoff: time.Second, MaxBackoff: 3 * time.Second, MaxRestarts: 2, ResetAfter: time.Minute}
+	supervisor := NewSupervisor(manager, cfg, restart, func(info *ProcessInfo, reason string) { gaveUp = reason })
+
+	now := time.Now()
+	supervisor.now = func() time.Time { return now }
+
+	supervisor.Check()
+	if len(restarts) != 0 {
+		t.Fatal("The first check should only schedule the restart")
+	}
+
+	now = now.Add(time.Second)
+	supervisor.Check()
+	if len(restarts) != 1 || restarts[0] != 1 {
+		t.Fatalf("Expected first restart after the backoff, got %v", restarts)
+	}
+
+	// The second restart waits twice as long
+	supervisor.Check()
+	now = now.Add(time.Second)
+	supervisor.Check()
+	if len(restarts) != 1 {
+		t.Fatalf("Second restart should wait for the doubled backoff, got %v", restarts)
+	}
+	now = now.Add(time.Second)
+	supervisor.Check()
+	if len(restarts) != 2 {
+		t.Fatalf("Expected second restart, got %v", restarts)
+	}
+
+	supervisor.Check()
+	if gaveUp == "" {
+		t.Fatal("Supervisor should give up after max restarts")
+	}
+	gaveUp = ""
+	supervisor.Check()
+	if gaveUp != "" || len(restarts) != 2 {
+		t.Error("Supervisor should give up only once and stop restarting")
+	}
+
+	info, err := manager.LoadProcessInfo("worker")
+	if err != nil {
+		t.Fatalf("Failed to load process info: %v", err)
+	}
+	if info.Restarts != 2 {
+		t.Errorf("Expected 2 recorded restarts, got %d", info.Restarts)
+	}
+}
+
+func TestSupervisorLeavesStoppedAndHealthyDaemons(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	always := &LaunchSpec{Options: LaunchOptions{Restart: RestartAlways}}
+	processes := []*ProcessInfo{
+		{ID: "stopped", PID: 99999999, Status: "stopped", StopRequested: true, Launch: always},
+		{ID: "healthy", PID: os.Getpid(), Status: "running", StartTime: time.Now().Add(-time.Hour), Restarts: 3, Launch: always},
+	}
+	for _, info := range processes {
+		if err := manager.SaveProcessInfo(info); err != nil {
+			t.Fatalf("Failed to save process info: %v", err)
+		}
+	}
+
+	restarted := false
+	cfg := SupervisorConfig{MaxRestarts: 5, ResetAfter: 10 * time.Minute}
+	supervisor := NewSupervisor(manager, cfg, func(*ProcessInfo) error { restarted = true; return nil }, nil)
+	supervisor.Check()
+	supervisor.Check()
+
+	if restarted {
+		t.Error("Daemons stopped with 'lai stop' or still running must not be restarted")
+	}
+	info, _ := manager.LoadProcessInfo("healthy")
+	if info.Restarts != 0 {
+		t.Errorf("Restart count of a daemon that stayed up should be reset, got %d", info.Restarts)
+	}
+}
+
+func TestStopProcessRecordsRequest(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManagerWithDirs(filepath.Join(tempDir, "processes"), filepath.Join(tempDir, "logs"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.SaveProcessInfo(&ProcessInfo{ID: "dead", PID: 99999999, Status: "running"}); err != nil {
+		t.Fatalf("Failed to save process info: %v", err)
+	}
+
+	if err := manager.StopProcess("dead"); err != nil {
+		t.Fatalf("StopProcess failed: %v", err)
+	}
+	info, _ := manager.LoadProcessInfo("dead")
+	if !info.StopRequested || info.Status != "stopped" {
+		t.Errorf("Expected stop to be recorded, got %+v", info)
+	}
+}
@@ -0,0 +1,170 @@
+package notifier
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// unsafeFileNameChars matches characters replaced in attachment file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Attachment is a log excerpt sent as a file alongside a notification
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// supportsAttachments reports whether a provider can send log excerpts as
+// files. Slack needs an OAuth token, since incoming webhooks cannot upload.
+func supportsAttachments(serviceConfig config.ServiceConfig) bool {
+	switch serviceConfig.Provider {
+	case "telegram", "discord_webhook":
+		return true
+	case "slack":
+		return configString(serviceConfig.Config, "webhook_url") == ""
+	default:
+		return isEmailProvider(serviceConfig.Provider)
+	}
+}
+
+// newAttachment builds the log excerpt attachment of a message, or returns nil
+// when attachments are disabled or the message carries no log lines. Excerpts
+// over the size cap keep their most recent lines.
+func newAttachment(msg *Message, cfg config.AttachmentConfig) (*Attachment, error) {
+	if !cfg.Enabled || strings.TrimSpace(msg.Excerpt) == "" {
+		return nil, nil
+	}
+
+	content := msg.Excerpt
+	if len(content) > cfg.MaxBytes() {
+		tail := tailLines(content, cfg.MaxBytes())
+		content = fmt.Sprintf("[... %d earlier bytes omitted ...]\n%s", len(content)-len(tail), tail)
+	}
+
+	attachment := &Attachment{
+		Name:        attachmentName(msg),
+		ContentType: "text/plain; charset=utf-8",
+		Data:        []byte(content),
+	}
+	if !cfg.Gzip {
+		return attachment, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Name = attachment.Name
+	if _, err := writer.Write(attachment.Data); err != nil {
+		return nil, fmt.Errorf("failed to compress attachment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress attachment: %w", err)
+	}
+
+	attachment.Name += ".gz"
+	attachment.ContentType = "application/gzip"
+	attachment.Data = buf.Bytes()
+	return attachment, nil
+}
+
+// attachmentName returns a file name such as lai-api-20240101-120000.log
+func attachmentName(msg *Message) string {
+	name := msg.Monitor
+	if name == "" {
+		name = msg.Source
+	}
+	name = strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "_.")
+	if len(name) > 40 {
+		name = name[len(name)-40:]
+	}
+	if name == "" {
+		name = "log"
+	}
+
+	at := msg.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return fmt.Sprintf("lai-%s-%s.log", name, at.Format("20060102-150405"))
+}
+
+// tailLines returns the last whole lines of content that fit in maxBytes
+func tailLines(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+	tail := content[len(content)-maxBytes:]
+	if index := strings.Index(tail, "\n"); index >= 0 && index < len(tail)-1 {
+		tail = tail[index+1:]
+	}
+	return tail
+}
+
+// postMultipart uploads a file with the given form fields and returns an
+// error for non-2xx responses
+func postMultipart(ctx context.Context, client *http.Client, url string, fields map[string]string, fileField string, attachment *Attachment) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return fmt.Errorf("failed to build form: %w", err)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fileField, attachment.Name))
+	header.Set("Content-Type", attachment.ContentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to build form: %w", err)
+	}
+	if _, err := part.Write(attachment.Data); err != nil {
+		return fmt.Errorf("failed to build form: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to build form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", "Lai-Notifier/1.0")
+	return doRequest(client, req)
+}
+
+// telegramCaptionLimit is the longest caption Telegram accepts on a document
+const telegramCaptionLimit = 1024
+
+// sendTelegramDocument sends a log excerpt as a document to each chat of a
+// Telegram provider
+func sendTelegramDocument(ctx context.Context, serviceConfig config.ServiceConfig, msg *Message, attachment *Attachment) error {
+	token := configString(serviceConfig.Config, "bot_token")
+	client := &http.Client{Timeout: defaultHTTPTimeout}
+
+	var errs []error
+	for _, chatID := range configStringList(serviceConfig.Config, "chat_id") {
+		fields := map[string]string{
+			"chat_id": chatID,
+			"caption": truncateText("Log excerpt: "+msg.DisplayTitle(), telegramCaptionLimit-1),
+		}
+		if err := postMultipart(ctx, client, telegramAPIURL+"/bot"+token+"/sendDocument", fields, "document", attachment); err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chatID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("telegram: %w", combineErrors(errs))
+	}
+	return nil
+}
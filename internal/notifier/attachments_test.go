@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func excerptMessage(excerpt string) *Message {
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "summary", SeverityInfo)
+	msg.Monitor = "api server"
+	msg.Excerpt = excerpt
+	msg.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return msg
+}
+
+func TestNewAttachment(t *testing.T) {
+	attachment, err := newAttachment(excerptMessage("line\n"), config.AttachmentConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, attachment, "attachments are off by default")
+
+	attachment, err = newAttachment(excerptMessage(""), config.AttachmentConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Nil(t, attachment, "messages without log lines get no attachment")
+
+	attachment, err = newAttachment(excerptMessage("first line\nsecond\nthird\n"), config.AttachmentConfig{Enabled: true, MaxSize: 14})
+	require.NoError(t, err)
+	assert.Equal(t, "lai-api_server-20240102-030405.log", attachment.Name)
+	assert.Equal(t, "[... 11 earlier bytes omitted ...]\nsecond\nthird\n", string(attachment.Data))
+}
+
+func TestNewAttachmentGzip(t *testing.T) {
+	attachment, err := newAttachment(excerptMessage("ERROR boom\n"), config.AttachmentConfig{Enabled: true, Gzip: true})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(attachment.Name, ".log.gz"))
+	assert.Equal(t, "application/gzip", attachment.ContentType)
+
+	reader, err := gzip.NewReader(bytes.NewReader(attachment.Data))
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "ERROR boom\n", string(content))
+}
+
+func TestSupportsAttachments(t *testing.T) {
+	assert.True(t, supportsAttachments(config.ServiceConfig{Provider: "telegram"}))
+	assert.True(t, supportsAttachments(config.ServiceConfig{Provider: "gmail"}))
+	assert.True(t, supportsAttachments(config.ServiceConfig{Provider: "slack", Config: map[string]interface{}{"oauth_token": "x"}}))
+	assert.False(t, supportsAttachments(config.ServiceConfig{Provider: "slack", Config: map[string]interface{}{"webhook_url": "http://x"}}))
+	assert.False(t, supportsAttachments(config.ServiceConfig{Provider: "ntfy"}))
+}
+
+// multipartFile returns a form field and the uploaded file of a multipart request
+func multipartFile(t *testing.T, r *http.Request, field, fileField string) (string, string, []byte) {
+	require.NoError(t, r.ParseMultipartForm(1<<20))
+	file, header, err := r.FormFile(fileField)
+	require.NoError(t, err)
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return r.FormValue(field), header.Filename, data
+}
+
+func TestDiscordWebhookAttachment(t *testing.T) {
+	var payload, filename string
+	var data []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, filename, data = multipartFile(t, r, "payload_json", "files[0]")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"discord": {
+				Enabled:     true,
+				Provider:    "discord_webhook",
+				Config:      map[string]interface{}{"webhook_url": server.URL},
+				Attachments: config.AttachmentConfig{Enabled: true},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, nn.Send(context.Background(), excerptMessage("ERROR boom\n")))
+
+	var decoded DiscordWebhookPayload
+	require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+	require.Len(t, decoded.Embeds, 1)
+	assert.Equal(t, "summary", decoded.Embeds[0].Description)
+	assert.Equal(t, "lai-api_server-20240102-030405.log", filename)
+	assert.Equal(t, "ERROR boom\n", string(data))
+}
+
+func TestSendTelegramDocument(t *testing.T) {
+	var paths, chats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chat, _, data := multipartFile(t, r, "chat_id", "document")
+		assert.Equal(t, "ERROR boom\n", string(data))
+		paths = append(paths, r.URL.Path)
+		chats = append(chats, chat)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	original := telegramAPIURL
+	telegramAPIURL = server.URL
+	defer func() { telegramAPIURL = original }()
+
+	msg := excerptMessage("ERROR boom\n")
+	attachment, err := newAttachment(msg, config.AttachmentConfig{Enabled: true})
+	require.NoError(t, err)
+
+	serviceConfig := config.ServiceConfig{Provider: "telegram", Config: map[string]interface{}{"bot_token": "123:abc", "chat_id": "1, 2"}}
+	require.NoError(t, sendTelegramDocument(context.Background(), serviceConfig, msg, attachment))
+	assert.Equal(t, []string{"/bot123:abc/sendDocument", "/bot123:abc/sendDocument"}, paths)
+	assert.Equal(t, []string{"1", "2"}, chats)
+}
+
+func TestSlackUploadAttachment(t *testing.T) {
+	var uploaded []byte
+	var completed map[string]interface{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files.getUploadURLExternal":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+			assert.Equal(t, "11", r.Form.Get("length"))
+			w.Write([]byte(`{"ok":true,"upload_url":"` + server.URL + `/upload","file_id":"F1"}`))
+		case "/upload":
+			uploaded, _ = io.ReadAll(r.Body)
+		case "/files.completeUploadExternal":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&completed))
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackService(config.ServiceConfig{Provider: "slack", Config: map[string]interface{}{
+		"oauth_token": "xoxb-token",
+		"channel_ids": []interface{}{"C1", "C2"},
+	}})
+	require.NoError(t, err)
+	slack.filesURL = server.URL + "/"
+
+	msg := excerptMessage("ERROR boom\n")
+	attachment, err := newAttachment(msg, config.AttachmentConfig{Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, slack.UploadAttachment(context.Background(), msg, attachment))
+
+	assert.Equal(t, "ERROR boom\n", string(uploaded))
+	assert.Equal(t, "C1,C2", completed["channels"])
+	assert.Equal(t, "F1", completed["files"].([]interface{})[0].(map[string]interface{})["id"])
+}
@@ -0,0 +1,210 @@
+package notifier
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// Volatile tokens that differ between otherwise identical notifications
+	fingerprintTimestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[t ]\d{2}:\d{2}:\d{2}(\.\d+)?(z|[+-]\d{2}:?\d{2})?`)
+	fingerprintHexPattern       = regexp.MustCompile(`\b(0x)?[0-9a-f]{8,}\b`)
+	fingerprintNumberPattern    = regexp.MustCompile(`\d+`)
+	fingerprintSpacePattern     = regexp.MustCompile(`\s+`)
+
+	// Lines that describe a failure in raw log output
+	errorLinePattern = regexp.MustCompile(`(?i)(error|exception|fail|fatal|panic|refused|timeout|denied|traceback)`)
+)
+
+// Deduplicator suppresses repeated notifications within a window and reports
+// how often each one was repeated once the window closes
+type Deduplicator struct {
+	window   time.Duration
+	onRollup func(rollup *Message)
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// dedupEntry tracks one fingerprint within the current window
+type dedupEntry struct {
+	first      time.Time
+	msg        *Message
+	suppressed int
+	timer      *time.Timer
+}
+
+// NewDeduplicator creates a deduplicator. onRollup is called when a window
+// closes after at least one repeat was suppressed.
+func NewDeduplicator(window time.Duration, onRollup func(rollup *Message)) *Deduplicator {
+	return &Deduplicator{
+		window:   window,
+		onRollup: onRollup,
+		now:      time.Now,
+		entries:  make(map[string]*dedupEntry),
+	}
+}
+
+// Allow reports whether the message should be delivered now. Repeats of a
+// message already delivered within the window are counted and suppressed.
+func (d *Deduplicator) Allow(msg *Message) bool {
+	fingerprint := Fingerprint(msg)
+	now := d.now()
+
+	d.mutex.Lock()
+	d.pruneLocked(now)
+
+	entry, exists := d.entries[fingerprint]
+	if exists && now.Sub(entry.first) < d.window {
+		entry.suppressed++
+		if entry.timer == nil {
+			entry.timer = time.AfterFunc(entry.first.Add(d.window).Sub(now), func() {
+				d.flush(fingerprint, entry)
+			})
+		}
+		d.mutex.Unlock()
+		return false
+	}
+
+	// An expired window whose timer has not fired yet is closed before the
+	// new one starts, so its repeats are still rolled up
+	d.entries[fingerprint] = &dedupEntry{first: now, msg: msg}
+	if exists && entry.timer != nil {
+		entry.timer.Stop()
+	}
+	d.mutex.Unlock()
+
+	if exists {
+		d.rollup(entry)
+	}
+	return true
+}
+
+// Flush sends rollups for all suppressed repeats immediately, e.g. on shutdown
+func (d *Deduplicator) Flush() {
+	d.mutex.Lock()
+	var fingerprints []string
+	for fingerprint, entry := range d.entries {
+		if entry.suppressed > 0 {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, fingerprint := range fingerprints {
+		d.flush(fingerprint, nil)
+	}
+}
+
+// flush closes the window for a fingerprint and emits a rollup if needed.
+// With expected set, only that window is closed; a timer of a window that
+// was replaced must not close its successor.
+func (d *Deduplicator) flush(fingerprint string, expected *dedupEntry) {
+	d.mutex.Lock()
+	entry, exists := d.entries[fingerprint]
+	if !exists || (expected != nil && entry != expected) {
+		d.mutex.Unlock()
+		return
+	}
+	delete(d.entries, fingerprint)
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	d.mutex.Unlock()
+
+	d.rollup(entry)
+}
+
+// rollup emits the "repeated N times" notification of a closed window
+func (d *Deduplicator) rollup(entry *dedupEntry) {
+	if entry.suppressed > 0 && d.onRollup != nil {
+		d.onRollup(newRollupMessage(entry.msg, entry.suppressed, d.window))
+	}
+}
+
+// pruneLocked drops expired entries that have nothing to roll up
+func (d *Deduplicator) pruneLocked(now time.Time) {
+	for fingerprint, entry := range d.entries {
+		if entry.suppressed == 0 && now.Sub(entry.first) >= d.window {
+			delete(d.entries, fingerprint)
+		}
+	}
+}
+
+// newRollupMessage builds the "repeated N times" notification for a suppressed message
+func newRollupMessage(original *Message, repeats int, window time.Duration) *Message {
+	rollup := *original
+	rollup.Time = time.Now()
+
+	times := "times"
+	if repeats == 1 {
+		times = "time"
+	}
+	rollup.Body = fmt.Sprintf("🔁 Repeated %d %s in the last %s\n\n%s", repeats, times, formatWindow(window), original.Body)
+	return &rollup
+}
+
+// formatWindow renders a duration compactly, e.g. "hour", "30m" or "1h30m"
+func formatWindow(window time.Duration) string {
+	if window == time.Hour {
+		return "hour"
+	}
+	text := window.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// Fingerprint identifies notifications that describe the same event. It uses
+// the message signature when present (derived from raw log lines), otherwise
+// the text of the message, with numbers, timestamps and IDs normalized away.
+func Fingerprint(msg *Message) string {
+	content := msg.Signature
+	if content == "" {
+		content = normalizeForFingerprint(msg.Body)
+	}
+
+	hash := sha1.Sum([]byte(strings.Join([]string{string(msg.Type), msg.Source, msg.Severity, content}, "\x00")))
+	return hex.EncodeToString(hash[:8])
+}
+
+// ErrorSignature extracts a normalized signature of the failure lines in raw
+// log content, so repeats of the same error match even when the generated
+// summary wording differs. It returns an empty string when no error lines are found.
+func ErrorSignature(content string) string {
+	seen := make(map[string]bool)
+	var lines []string
+
+	for _, line := range strings.Split(content, "\n") {
+		if !errorLinePattern.MatchString(line) {
+			continue
+		}
+		normalized := normalizeForFingerprint(line)
+		if normalized != "" && !seen[normalized] {
+			seen[normalized] = true
+			lines = append(lines, normalized)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// normalizeForFingerprint strips volatile tokens from text
+func normalizeForFingerprint(text string) string {
+	text = strings.ToLower(text)
+	text = fingerprintTimestampPattern.ReplaceAllString(text, "<ts>")
+	text = fingerprintHexPattern.ReplaceAllString(text, "<hex>")
+	text = fingerprintNumberPattern.ReplaceAllString(text, "<n>")
+	text = fingerprintSpacePattern.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduplicatorSuppressesRepeats(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var rollups []*Message
+
+	d := NewDeduplicator(time.Hour, func(rollup *Message) { rollups = append(rollups, rollup) })
+	d.now = func() time.Time { return now }
+
+	msg := &Message{Type: MessageTypeSummary, Source: "/var/log/app.log", Body: "connection refused to 10.0.0.5:5432", Severity: "error"}
+
+	assert.True(t, d.Allow(msg), "first occurrence should be delivered")
+
+	now = now.Add(5 * time.Minute)
+	assert.False(t, d.Allow(&Message{Type: msg.Type, Source: msg.Source, Body: "Connection refused to 10.0.0.7:5432", Severity: "error"}))
+	now = now.Add(5 * time.Minute)
+	assert.False(t, d.Allow(msg))
+
+	// A different message is not affected
+	assert.True(t, d.Allow(&Message{Type: MessageTypeSummary, Source: msg.Source, Body: "disk full", Severity: "error"}))
+
+	d.Flush()
+	if assert.Len(t, rollups, 1) {
+		assert.True(t, strings.HasPrefix(rollups[0].Body, "🔁 Repeated 2 times in the last hour"), rollups[0].Body)
+		assert.Contains(t, rollups[0].Body, "connection refused")
+	}
+
+	// After the rollup the next occurrence starts a new window
+	assert.True(t, d.Allow(msg))
+}
+
+func TestDeduplicatorWindowExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := NewDeduplicator(10*time.Minute, nil)
+	d.now = func() time.Time { return now }
+
+	msg := &Message{Type: MessageTypeSummary, Body: "same"}
+	assert.True(t, d.Allow(msg))
+
+	now = now.Add(11 * time.Minute)
+	assert.True(t, d.Allow(msg), "message should be delivered again after the window")
+}
+
+func TestDeduplicatorRollsUpExpiredWindowBeforeReplacingIt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var rollups []*Message
+	d := NewDeduplicator(time.Hour, func(rollup *Message) { rollups = append(rollups, rollup) })
+	d.now = func() time.Time { return now }
+
+	msg := &Message{Type: MessageTypeSummary, Body: "flapping"}
+	assert.True(t, d.Allow(msg))
+	assert.False(t, d.Allow(msg))
+
+	// The window expired but its rollup timer has not fired yet
+	now = now.Add(61 * time.Minute)
+	assert.True(t, d.Allow(msg))
+	if assert.Len(t, rollups, 1) {
+		assert.Contains(t, rollups[0].Body, "Repeated 1 time")
+	}
+
+	// The new window keeps counting on its own
+	assert.False(t, d.Allow(msg))
+	d.Flush()
+	assert.Len(t, rollups, 2)
+}
+
+func TestDeduplicatorRollupTimer(t *testing.T) {
+	rollupCh := make(chan *Message, 1)
+	d := NewDeduplicator(50*time.Millisecond, func(rollup *Message) { rollupCh <- rollup })
+
+	msg := &Message{Type: MessageTypeSummary, Body: "flapping"}
+	assert.True(t, d.Allow(msg))
+	assert.False(t, d.Allow(msg))
+
+	select {
+	case rollup := <-rollupCh:
+		assert.Contains(t, rollup.Body, "Repeated 1 time in the last 50ms")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected rollup after the window closed")
+	}
+}
+
+func TestFingerprintUsesSignature(t *testing.T) {
+	a := &Message{Type: MessageTypeSummary, Body: "The database is refusing connections", Signature: "conn refused"}
+	b := &Message{Type: MessageTypeSummary, Body: "Postgres rejected connection attempts", Signature: "conn refused"}
+	assert.Equal(t, Fingerprint(a), Fingerprint(b))
+
+	c := &Message{Type: MessageTypeSummary, Body: "The database is refusing connections"}
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(c))
+}
+
+func TestErrorSignature(t *testing.T) {
+	first := "2024-01-01 12:00:01 INFO started\n2024-01-01 12:00:02 ERROR dial tcp 10.0.0.5:5432: connection refused\n"
+	second := "2024-01-01 13:15:44 ERROR dial tcp 10.0.0.5:5433: connection refused\n2024-01-01 13:15:45 INFO retrying\n"
+
+	assert.NotEmpty(t, ErrorSignature(first))
+	assert.Equal(t, ErrorSignature(first), ErrorSignature(second))
+	assert.Empty(t, ErrorSignature("all good\nnothing to see"))
+}
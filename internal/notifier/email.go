@@ -1,7 +1,10 @@
 package notifier
 
 import (
+	"bytes"
 	"fmt"
+	"html/template"
+	"io"
 	"strings"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -85,6 +88,50 @@ func (e *EmailNotifier) SendMessage(message string) error {
 	m.SetHeader("Subject", e.subject)
 	m.SetBody("text/html", message)
 
+	return e.send(m)
+}
+
+// SendMultipart sends an email with a plain text body and an HTML alternative,
+// so clients without HTML support still get a readable message.
+//
+// Parameters:
+//   - subject: Subject line (empty uses the configured subject)
+//   - plainText: Plain text version of the message
+//   - htmlBody: HTML version of the message
+//   - attachments: Optional files attached to the email
+//
+// Returns:
+//   - Error if email sending fails
+func (e *EmailNotifier) SendMultipart(subject, plainText, htmlBody string, attachments ...*Attachment) error {
+	if len(e.toEmails) == 0 {
+		return fmt.Errorf("no recipient email addresses provided")
+	}
+	if subject == "" {
+		subject = e.subject
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", e.fromEmail)
+	m.SetHeader("To", e.toEmails...)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", plainText)
+	m.AddAlternative("text/html", htmlBody)
+	for _, attachment := range attachments {
+		data := attachment.Data
+		m.Attach(attachment.Name,
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}),
+		)
+	}
+
+	return e.send(m)
+}
+
+// send delivers a prepared message over SMTP
+func (e *EmailNotifier) send(m *gomail.Message) error {
 	d := gomail.NewDialer(e.smtpHost, e.smtpPort, e.username, e.password)
 
 	// For Gmail port 587, TLS should be enabled by default
@@ -258,3 +305,70 @@ func getDefaultEmailTemplates() map[string]string {
 </html>`,
 	}
 }
+
+// emailMessageTemplate renders a Message as HTML with its metadata as a table
+var emailMessageTemplate = template.Must(template.New("email").Parse(`<html>
+<head>
+	<meta charset="utf-8">
+	<style>
+		body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Arial, sans-serif; padding: 20px; line-height: 1.6; }
+		.header { background-color: #f5f5f5; padding: 15px; border-radius: 5px; margin-bottom: 20px; border-left: 6px solid {{.Color}}; }
+		.title { color: {{.Color}}; margin: 0 0 10px 0; }
+		.meta { border-collapse: collapse; font-size: 14px; }
+		.meta th { color: #666; text-align: left; padding: 2px 15px 2px 0; font-weight: 600; vertical-align: top; }
+		.meta td { color: #333; padding: 2px 0; }
+		.content { border-left: 4px solid {{.Color}}; padding-left: 15px; margin: 20px 0; }
+		h1, h2, h3, h4, h5, h6 { color: #333; margin-top: 20px; }
+		code { background-color: #f4f4f4; padding: 2px 4px; border-radius: 3px; font-family: 'Courier New', monospace; }
+		pre { background-color: #f4f4f4; padding: 10px; border-radius: 5px; overflow-x: auto; }
+		blockquote { border-left: 4px solid #ddd; margin: 0; padding-left: 15px; color: #666; }
+	</style>
+</head>
+<body>
+	<div class="header">
+		<h2 class="title">{{.Title}}</h2>
+		<table class="meta">
+		{{- range .Fields}}
+			<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+		{{- end}}
+		</table>
+	</div>
+	<div class="content">
+		{{.Body}}
+	</div>
+	<hr style="margin-top: 30px;">
+	<p style="color: #999; font-size: 12px;">Generated by Lai - AI Log Monitoring Tool</p>
+</body>
+</html>`))
+
+// renderEmailHTML renders a message as an HTML email, converting the markdown body
+func renderEmailHTML(msg *Message) (string, error) {
+	data := struct {
+		Title  string
+		Color  template.CSS
+		Fields []MessageField
+		Body   template.HTML
+	}{
+		Title:  msg.DisplayTitle(),
+		Color:  template.CSS(severityColor(msg.Severity)),
+		Fields: msg.Fields(),
+		Body:   template.HTML(ConvertMarkdownToHTML(msg.Body)), // Sanitized by ConvertMarkdownToHTML
+	}
+
+	var buf bytes.Buffer
+	if err := emailMessageTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render email: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// renderEmailText renders a message as the plain text part of an email
+func renderEmailText(msg *Message) string {
+	var b strings.Builder
+	b.WriteString(msg.DisplayTitle() + "\n\n")
+	for _, field := range msg.Fields() {
+		fmt.Fprintf(&b, "%s: %s\n", field.Name, field.Value)
+	}
+	b.WriteString("\n" + msg.Body + "\n\n--\nGenerated by Lai - AI Log Monitoring Tool\n")
+	return b.String()
+}
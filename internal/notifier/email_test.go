@@ -88,6 +88,31 @@ func TestGetDefaultEmailTemplates(t *testing.T) {
 	assert.Contains(t, templates["log_summary"], "{{.Summary}}")
 }
 
+func TestRenderEmail(t *testing.T) {
+	msg := NewMessage(MessageTypeSummary, "/var/log/<app>.log", "## Findings\n\n- disk full", SeverityError)
+	msg.Host = "web-01"
+
+	htmlBody, err := renderEmailHTML(msg)
+	assert.NoError(t, err)
+	assert.Contains(t, htmlBody, "<h2 class=\"title\">🚨 Log Summary Notification</h2>")
+	assert.Contains(t, htmlBody, "<tr><th>Source</th><td>/var/log/&lt;app&gt;.log</td></tr>")
+	assert.Contains(t, htmlBody, "<tr><th>Host</th><td>web-01</td></tr>")
+	assert.Contains(t, htmlBody, "<li>disk full</li>")
+	assert.Contains(t, htmlBody, "#E74C3C")
+
+	text := renderEmailText(msg)
+	assert.Contains(t, text, "🚨 Log Summary Notification\n\nSource: /var/log/<app>.log\n")
+	assert.Contains(t, text, "Severity: error\n")
+	assert.Contains(t, text, "## Findings\n\n- disk full")
+}
+
+func TestEmailNotifier_SendMultipart_NoRecipients(t *testing.T) {
+	notifier := NewEmailNotifier("smtp.gmail.com", 587, "user", "pass", "from@gmail.com", []string{}, "Test", true, nil)
+	err := notifier.SendMultipart("", "plain", "<p>html</p>")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no recipient email addresses provided")
+}
+
 // TestEmailNotifier_SendMessage_WithMockClient is removed because the current implementation
 // uses gomail library which doesn't support easy mocking without interfaces
 // This would require refactoring the EmailNotifier to use dependency injection for the mailer
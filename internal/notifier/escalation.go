@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// Acknowledger is implemented by notifiers that track alerts until they are
+// acknowledged, such as for escalation
+type Acknowledger interface {
+	// Acknowledge marks the pending alerts of a monitor as handled and
+	// returns how many there were
+	Acknowledge(monitor string) int
+}
+
+// Escalator re-sends error alerts that are not acknowledged within a delay
+type Escalator struct {
+	after      time.Duration
+	onEscalate func(msg *Message)
+
+	mutex   sync.Mutex
+	pending map[*Message]*time.Timer
+}
+
+// NewEscalator creates an escalator that passes alerts still unacknowledged
+// after the delay to onEscalate
+func NewEscalator(after time.Duration, onEscalate func(msg *Message)) *Escalator {
+	return &Escalator{
+		after:      after,
+		onEscalate: onEscalate,
+		pending:    make(map[*Message]*time.Timer),
+	}
+}
+
+// Track starts the acknowledgement timer of an alert
+func (e *Escalator) Track(msg *Message) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.pending[msg] = time.AfterFunc(e.after, func() {
+		e.mutex.Lock()
+		_, pending := e.pending[msg]
+		delete(e.pending, msg)
+		e.mutex.Unlock()
+
+		if pending && e.onEscalate != nil {
+			e.onEscalate(newEscalationMessage(msg, e.after))
+		}
+	})
+}
+
+// Acknowledge stops the timers of a monitor's pending alerts. An empty
+// monitor name acknowledges every pending alert.
+func (e *Escalator) Acknowledge(monitor string) int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	count := 0
+	for msg, timer := range e.pending {
+		if monitor != "" && msg.Monitor != monitor {
+			continue
+		}
+		timer.Stop()
+		delete(e.pending, msg)
+		count++
+	}
+	return count
+}
+
+// Pending returns the number of alerts waiting for acknowledgement
+func (e *Escalator) Pending() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return len(e.pending)
+}
+
+// Stop cancels all timers without escalating
+func (e *Escalator) Stop() {
+	e.Acknowledge("")
+}
+
+// newEscalationMessage marks a copy of an alert as escalated
+func newEscalationMessage(msg *Message, after time.Duration) *Message {
+	escalated := *msg
+	escalated.Title = "⏫ Escalated: " + strings.TrimSpace(msg.DisplayTitle())
+	escalated.Body = fmt.Sprintf("Not acknowledged within %v.\n\n%s", after, msg.Body)
+	escalated.Time = time.Now()
+	return &escalated
+}
+
+// validateEscalation checks the escalation settings
+func validateEscalation(escalation *config.EscalationConfig) error {
+	if escalation.After <= 0 {
+		return fmt.Errorf("escalation after must be positive")
+	}
+	if len(escalation.Providers) == 0 {
+		return fmt.Errorf("escalation providers are required")
+	}
+	return nil
+}
+
+// isEscalationProvider reports whether a provider is on the escalation list
+func isEscalationProvider(escalation *config.EscalationConfig, key string, serviceConfig config.ServiceConfig) bool {
+	if escalation == nil {
+		return false
+	}
+	for _, name := range escalation.Providers {
+		if matchesProvider(name, key, serviceConfig) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesProvider reports whether a name refers to a provider by key or type
+func matchesProvider(name, key string, serviceConfig config.ServiceConfig) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return name != "" && (strings.ToLower(key) == name || strings.ToLower(serviceConfig.Provider) == name)
+}
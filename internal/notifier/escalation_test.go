@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscalatorEscalatesUnacknowledgedAlerts(t *testing.T) {
+	escalated := make(chan *Message, 2)
+	e := NewEscalator(20*time.Millisecond, func(msg *Message) { escalated <- msg })
+
+	e.Track(&Message{Monitor: "api", Title: "DB down", Body: "timeout"})
+	e.Track(&Message{Monitor: "worker", Body: "crash"})
+	assert.Equal(t, 1, e.Acknowledge("worker"))
+
+	select {
+	case msg := <-escalated:
+		assert.Equal(t, "⏫ Escalated: DB down", msg.Title)
+		assert.Contains(t, msg.Body, "Not acknowledged within 20ms")
+		assert.Contains(t, msg.Body, "timeout")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the unacknowledged alert to escalate")
+	}
+
+	select {
+	case msg := <-escalated:
+		t.Fatalf("acknowledged alert escalated: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Zero(t, e.Pending())
+}
+
+func TestValidateEscalation(t *testing.T) {
+	assert.Error(t, validateEscalation(&config.EscalationConfig{Providers: []string{"pagerduty"}}))
+	assert.Error(t, validateEscalation(&config.EscalationConfig{After: time.Minute}))
+	assert.NoError(t, validateEscalation(&config.EscalationConfig{After: time.Minute, Providers: []string{"pagerduty"}}))
+}
+
+func TestNotifyNotifierEscalation(t *testing.T) {
+	primary, _ := newCaptureServer(t, http.StatusOK)
+	oncall, escalated := newCaptureServer(t, http.StatusOK)
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"chat":   {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": primary.URL}},
+			"oncall": {Enabled: false, Provider: "webhook", Config: map[string]interface{}{"url": oncall.URL}},
+		},
+		Escalation: &config.EscalationConfig{After: time.Hour, Providers: []string{"oncall"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chat"}, nn.GetEnabledChannels(), "escalation-only providers get no regular messages")
+	require.NotNil(t, nn.escalator)
+
+	alert := NewMessage(MessageTypeError, "app.log", "db down", SeverityError)
+	alert.Monitor = "api"
+	require.NoError(t, nn.Send(context.Background(), alert))
+	require.NoError(t, nn.Send(context.Background(), NewMessage(MessageTypeSummary, "app.log", "ok", SeverityInfo)))
+	assert.Equal(t, 1, nn.escalator.Pending(), "only error alerts escalate")
+	assert.Nil(t, escalated.Body)
+
+	assert.Equal(t, 1, nn.Acknowledge("api"))
+	assert.Zero(t, nn.escalator.Pending())
+}
+
+func TestRetryOutboxIncludesEscalationTargets(t *testing.T) {
+	primary, _ := newCaptureServer(t, http.StatusOK)
+	oncall, escalated := newCaptureServer(t, http.StatusOK)
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"chat":   {Enabled: true, Provider: "webhook", Config: map[string]interface{}{"url": primary.URL}},
+			"oncall": {Enabled: false, Provider: "webhook", Config: map[string]interface{}{"url": oncall.URL}},
+		},
+		Escalation: &config.EscalationConfig{After: time.Hour, Providers: []string{"oncall"}},
+	})
+	require.NoError(t, err)
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+	nn.SetOutbox(outbox)
+
+	// An escalation that failed to deliver
+	_, err = outbox.Add("oncall", NewMessage(MessageTypeError, "app.log", "db down", SeverityError), nil)
+	require.NoError(t, err)
+
+	sent, failed := nn.RetryOutbox(context.Background(), true, nil)
+	assert.Equal(t, 1, sent)
+	assert.Zero(t, failed)
+	assert.NotNil(t, escalated.Body)
+}
+
+func TestSelectProvidersKeepsEscalationTargets(t *testing.T) {
+	notifications := config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"chat":   {Enabled: true, Provider: "slack"},
+			"oncall": {Enabled: true, Provider: "pagerduty"},
+		},
+		Escalation: &config.EscalationConfig{After: time.Minute, Providers: []string{"pagerduty"}},
+	}
+
+	selected, err := SelectProviders(notifications, []string{"chat"})
+	require.NoError(t, err)
+	assert.True(t, selected.Providers["chat"].Enabled)
+	require.Contains(t, selected.Providers, "oncall")
+	assert.False(t, selected.Providers["oncall"].Enabled, "kept for escalations only")
+}
@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// GotifyService pushes messages to a Gotify server using an application token
+type GotifyService struct {
+	serverURL string
+	appToken  string
+	priority  int // 0 derives the priority from the message severity
+	client    *http.Client
+}
+
+// NewGotifyService creates a Gotify service from provider configuration
+func NewGotifyService(serviceConfig config.ServiceConfig) (*GotifyService, error) {
+	serverURL := strings.TrimRight(configString(serviceConfig.Config, "server_url"), "/")
+	if serverURL == "" {
+		return nil, fmt.Errorf("gotify server_url is required")
+	}
+
+	appToken := configString(serviceConfig.Config, "app_token")
+	if appToken == "" {
+		return nil, fmt.Errorf("gotify app_token is required")
+	}
+
+	priority, _, err := configInt(serviceConfig.Config, "priority")
+	if err != nil {
+		return nil, err
+	}
+	if priority < 0 || priority > 10 {
+		return nil, fmt.Errorf("gotify priority must be between 0 and 10")
+	}
+
+	return &GotifyService{
+		serverURL: serverURL,
+		appToken:  appToken,
+		priority:  priority,
+		client:    &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage pushes the message, rendering the body as markdown in Gotify clients
+func (g *GotifyService) SendMessage(ctx context.Context, msg *Message) error {
+	priority := g.priority
+	if priority == 0 {
+		priority = gotifySeverityPriority(msg.Severity)
+	}
+
+	body := msg.Body
+	if msg.Source != "" {
+		body = fmt.Sprintf("**Source:** %s\n\n%s", msg.Source, msg.Body)
+	}
+
+	payload := map[string]interface{}{
+		"title":    msg.DisplayTitle(),
+		"message":  body,
+		"priority": priority,
+		"extras": map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		},
+	}
+
+	headers := map[string]string{"X-Gotify-Key": g.appToken}
+	if err := postJSON(ctx, g.client, g.serverURL+"/message", payload, headers); err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	return nil
+}
+
+// gotifySeverityPriority maps a message severity to a Gotify priority (0-10)
+func gotifySeverityPriority(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 8
+	case SeverityWarning:
+		return 5
+	default:
+		return 2
+	}
+}
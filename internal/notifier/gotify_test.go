@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGotifySendMessage(t *testing.T) {
+	var path, token string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		token = r.Header.Get("X-Gotify-Key")
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+	}))
+	defer server.Close()
+
+	gotify, err := NewGotifyService(config.ServiceConfig{Config: map[string]interface{}{
+		"server_url": server.URL + "/",
+		"app_token":  "AbCdEf",
+	}})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "Service restarted", SeverityWarning)
+	require.NoError(t, gotify.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "/message", path)
+	assert.Equal(t, "AbCdEf", token)
+	assert.Equal(t, "🚨 Log Summary Notification", body["title"])
+	assert.Equal(t, "**Source:** /var/log/app.log\n\nService restarted", body["message"])
+	assert.Equal(t, float64(5), body["priority"])
+}
+
+func TestNewGotifyServiceValidation(t *testing.T) {
+	gotify, err := NewGotifyService(config.ServiceConfig{Config: map[string]interface{}{
+		"server_url": "https://gotify.example.com", "app_token": "x", "priority": "7",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 7, gotify.priority)
+
+	for _, settings := range []map[string]interface{}{
+		{"app_token": "x"},
+		{"server_url": "https://gotify.example.com"},
+		{"server_url": "https://gotify.example.com", "app_token": "x", "priority": 11},
+	} {
+		_, err := NewGotifyService(config.ServiceConfig{Config: settings})
+		assert.Error(t, err, "settings %v", settings)
+	}
+}
@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout is the request timeout for HTTP-based providers
+const defaultHTTPTimeout = 30 * time.Second
+
+// messageService is a provider that delivers a Message over its own API
+// instead of through the notify library
+type messageService interface {
+	SendMessage(ctx context.Context, msg *Message) error
+}
+
+// postJSON sends a JSON payload and returns an error for non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) error {
+	return sendJSON(ctx, client, http.MethodPost, url, payload, headers)
+}
+
+// sendJSON sends a JSON payload with the given method and returns an error for non-2xx responses
+func sendJSON(ctx context.Context, client *http.Client, method, url string, payload interface{}, headers map[string]string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Lai-Notifier/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return doRequest(client, req)
+}
+
+// doRequest performs a request and turns non-2xx responses into errors that
+// include the start of the response body
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// severityColor returns the hex accent color used for a severity
+func severityColor(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "#E74C3C"
+	case SeverityWarning:
+		return "#F39C12"
+	default:
+		return "#3498DB"
+	}
+}
+
+// severityEmoji returns the colored marker shown for a severity
+func severityEmoji(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "🔴"
+	case SeverityWarning:
+		return "🟠"
+	default:
+		return "🔵"
+	}
+}
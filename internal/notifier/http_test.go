@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// capturedRequest records the last request received by a capture server
+type capturedRequest struct {
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// newCaptureServer starts a server that decodes JSON request bodies and
+// responds with the given status
+func newCaptureServer(t *testing.T, status int) (*httptest.Server, *capturedRequest) {
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured.Header = r.Header
+		captured.Body = nil
+		require.NoError(t, json.Unmarshal(body, &captured.Body))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
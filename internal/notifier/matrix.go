@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// MatrixService sends messages to a Matrix room through the client-server API
+type MatrixService struct {
+	homeserver  string
+	accessToken string
+	roomID      string
+	client      *http.Client
+}
+
+// NewMatrixService creates a Matrix service from provider configuration
+func NewMatrixService(serviceConfig config.ServiceConfig) (*MatrixService, error) {
+	homeserver := strings.TrimRight(configString(serviceConfig.Config, "homeserver"), "/")
+	if homeserver == "" {
+		return nil, fmt.Errorf("matrix homeserver is required")
+	}
+
+	accessToken := configString(serviceConfig.Config, "access_token")
+	if accessToken == "" {
+		return nil, fmt.Errorf("matrix access_token is required")
+	}
+
+	roomID := configString(serviceConfig.Config, "room_id")
+	if roomID == "" {
+		return nil, fmt.Errorf("matrix room_id is required")
+	}
+
+	return &MatrixService{
+		homeserver:  homeserver,
+		accessToken: accessToken,
+		roomID:      roomID,
+		client:      &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage sends the message as an m.text event with an HTML rendering
+func (m *MatrixService) SendMessage(ctx context.Context, msg *Message) error {
+	var plain, formatted strings.Builder
+	plain.WriteString(msg.DisplayTitle() + "\n\n")
+	formatted.WriteString("<strong>" + html.EscapeString(msg.DisplayTitle()) + "</strong><br>")
+	for _, field := range msg.Fields() {
+		plain.WriteString(fmt.Sprintf("%s: %s\n", field.Name, field.Value))
+		formatted.WriteString(fmt.Sprintf("<b>%s:</b> %s<br>", field.Name, html.EscapeString(field.Value)))
+	}
+	plain.WriteString("\n" + msg.Body)
+	formatted.WriteString("<br>" + strings.ReplaceAll(html.EscapeString(msg.Body), "\n", "<br>"))
+
+	payload := map[string]string{
+		"msgtype":        "m.text",
+		"body":           plain.String(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	}
+
+	// The transaction ID is derived from the message, so the homeserver
+	// ignores a retry of a message it already posted, e.g. from the outbox
+	hash := sha1.Sum([]byte(msg.Time.UTC().Format(time.RFC3339Nano) + "\x00" + plain.String()))
+	txnID := "lai-" + hex.EncodeToString(hash[:8])
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver, url.PathEscape(m.roomID), txnID)
+
+	headers := map[string]string{"Authorization": "Bearer " + m.accessToken}
+	if err := sendJSON(ctx, m.client, http.MethodPut, endpoint, payload, headers); err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	return nil
+}
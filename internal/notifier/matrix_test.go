@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixSendMessage(t *testing.T) {
+	var paths []string
+	var auth, method string
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		auth = r.Header.Get("Authorization")
+		method = r.Method
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer server.Close()
+
+	matrix, err := NewMatrixService(config.ServiceConfig{Config: map[string]interface{}{
+		"homeserver":   server.URL,
+		"access_token": "syt_token",
+		"room_id":      "!room:example.com",
+	}})
+	require.NoError(t, err)
+
+	other := NewMessage(MessageTypeError, "api", "disk full", SeverityError)
+	require.NoError(t, matrix.SendMessage(context.Background(), other))
+	msg := NewMessage(MessageTypeError, "api", "<panic> in handler\nstack trace", SeverityError)
+	require.NoError(t, matrix.SendMessage(context.Background(), msg))
+	require.NoError(t, matrix.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "Bearer syt_token", auth)
+	require.Len(t, paths, 3)
+	assert.True(t, strings.HasPrefix(paths[0], "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/lai-"), paths[0])
+	assert.NotEqual(t, paths[0], paths[1], "each message needs its own transaction ID")
+	assert.Equal(t, paths[1], paths[2], "a retry of a message reuses its transaction ID")
+
+	assert.Equal(t, "m.text", body["msgtype"])
+	assert.Contains(t, body["body"], "<panic> in handler")
+	assert.Contains(t, body["formatted_body"], "&lt;panic&gt; in handler<br>stack trace")
+	assert.Contains(t, body["formatted_body"], "<b>Source:</b> api")
+}
+
+func TestNewMatrixServiceValidation(t *testing.T) {
+	for _, settings := range []map[string]interface{}{
+		{"access_token": "x", "room_id": "!r:x"},
+		{"homeserver": "https://matrix.org", "room_id": "!r:x"},
+		{"homeserver": "https://matrix.org", "access_token": "x"},
+	} {
+		_, err := NewMatrixService(config.ServiceConfig{Config: settings})
+		assert.Error(t, err, "settings %v", settings)
+	}
+}
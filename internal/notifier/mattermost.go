@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// chatAttachment is a Slack-style message attachment, the format shared by
+// Mattermost and Rocket.Chat incoming webhooks
+type chatAttachment struct {
+	Fallback string                `json:"fallback,omitempty"`
+	Color    string                `json:"color,omitempty"`
+	Title    string                `json:"title,omitempty"`
+	Text     string                `json:"text,omitempty"`
+	Fields   []chatAttachmentField `json:"fields,omitempty"`
+}
+
+// chatAttachmentField is a short labelled value shown in an attachment
+type chatAttachmentField struct {
+	Short bool   `json:"short"`
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// newChatAttachment builds an attachment colored by severity with the message metadata as fields
+func newChatAttachment(msg *Message) chatAttachment {
+	attachment := chatAttachment{
+		Fallback: fmt.Sprintf("%s: %s", msg.DisplayTitle(), truncateText(msg.Body, 200)),
+		Color:    severityColor(msg.Severity),
+		Title:    msg.DisplayTitle(),
+		Text:     msg.Body,
+	}
+	for _, field := range msg.Fields() {
+		attachment.Fields = append(attachment.Fields, chatAttachmentField{Short: true, Title: field.Name, Value: field.Value})
+	}
+	return attachment
+}
+
+// MattermostService posts message attachments to a Mattermost incoming webhook
+type MattermostService struct {
+	webhookURL string
+	channel    string
+	username   string
+	iconURL    string
+	client     *http.Client
+}
+
+// NewMattermostService creates a Mattermost service from provider configuration
+func NewMattermostService(serviceConfig config.ServiceConfig) (*MattermostService, error) {
+	webhookURL := configString(serviceConfig.Config, "webhook_url")
+	if webhookURL == "" {
+		return nil, fmt.Errorf("mattermost webhook_url is required")
+	}
+
+	username := configString(serviceConfig.Config, "username")
+	if username == "" {
+		username = "Lai Bot"
+	}
+
+	return &MattermostService{
+		webhookURL: webhookURL,
+		channel:    configString(serviceConfig.Config, "channel"),
+		username:   username,
+		iconURL:    configString(serviceConfig.Config, "icon_url"),
+		client:     &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage posts the message as an attachment
+func (m *MattermostService) SendMessage(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"username":    m.username,
+		"attachments": []chatAttachment{newChatAttachment(msg)},
+	}
+	if m.channel != "" {
+		payload["channel"] = m.channel
+	}
+	if m.iconURL != "" {
+		payload["icon_url"] = m.iconURL
+	}
+
+	if err := postJSON(ctx, m.client, m.webhookURL, payload, nil); err != nil {
+		return fmt.Errorf("mattermost: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMattermostAttachment(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	mattermost, err := NewMattermostService(config.ServiceConfig{Config: map[string]interface{}{
+		"webhook_url": server.URL,
+		"channel":     "ops-alerts",
+	}})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "Disk almost full", SeverityWarning)
+	require.NoError(t, mattermost.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "ops-alerts", captured.Body["channel"])
+	assert.Equal(t, "Lai Bot", captured.Body["username"])
+	assert.NotContains(t, captured.Body, "icon_url")
+
+	attachments := captured.Body["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+	attachment := attachments[0].(map[string]interface{})
+	assert.Equal(t, severityColor(SeverityWarning), attachment["color"])
+	assert.Equal(t, "Disk almost full", attachment["text"])
+	assert.Equal(t, "🚨 Log Summary Notification", attachment["title"])
+
+	fields := attachment["fields"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"short": true, "title": "Source", "value": "/var/log/app.log"}, fields[0])
+}
+
+func TestMattermostRequiresWebhookURL(t *testing.T) {
+	_, err := NewMattermostService(config.ServiceConfig{Config: map[string]interface{}{"channel": "ops"}})
+	assert.Error(t, err)
+}
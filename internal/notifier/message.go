@@ -0,0 +1,190 @@
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MessageType identifies what kind of notification a Message carries
+type MessageType string
+
+const (
+	MessageTypeSummary      MessageType = "summary"
+	MessageTypeFinalSummary MessageType = "final_summary"
+	MessageTypeError        MessageType = "error"
+	MessageTypeLifecycle    MessageType = "lifecycle"
+	MessageTypeMessage      MessageType = "message"
+)
+
+// Lifecycle events carried by MessageTypeLifecycle messages
+const (
+	LifecycleStarted   = "started"
+	LifecycleStopped   = "stopped"
+	LifecycleCrashed   = "crashed"
+	LifecycleGaveUp    = "gave_up"   // The supervisor stopped restarting a daemon
+	LifecycleRecovered = "recovered" // A silent source produces output again
+)
+
+// validMessageType reports whether t is a known message type
+func validMessageType(t MessageType) bool {
+	switch t {
+	case MessageTypeSummary, MessageTypeFinalSummary, MessageTypeError, MessageTypeLifecycle, MessageTypeMessage:
+		return true
+	default:
+		return false
+	}
+}
+
+// Severity levels, matching summarizer.ErrorAnalysisResult.Severity
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Message is a single notification passed from the monitor to the notifier layer.
+// Carrying severity and source alongside the text lets the notifier route and
+// format it per provider.
+type Message struct {
+	Type     MessageType `json:"type"`
+	Source   string      `json:"source,omitempty"`
+	Monitor  string      `json:"monitor,omitempty"`
+	Host     string      `json:"host,omitempty"`
+	Title    string      `json:"title,omitempty"`
+	Body     string      `json:"body"`
+	Severity string      `json:"severity,omitempty"`
+	Time     time.Time   `json:"time"`
+
+	// Event names the lifecycle event of a MessageTypeLifecycle message
+	Event string `json:"event,omitempty"`
+
+	// Details are extra fields shown after the standard metadata, such as the
+	// exit code of a monitored command
+	Details []MessageField `json:"details,omitempty"`
+
+	// LineCount and the window describe the log lines a summary was generated from
+	LineCount   int       `json:"line_count,omitempty"`
+	WindowStart time.Time `json:"window_start,omitempty"`
+	WindowEnd   time.Time `json:"window_end,omitempty"`
+
+	// Excerpt holds the raw log lines the message was generated from, sent as
+	// a file attachment by providers that have attachments enabled
+	Excerpt string `json:"excerpt,omitempty"`
+
+	// Signature optionally identifies the underlying event for deduplication,
+	// e.g. the normalized error lines the summary was generated from
+	Signature string `json:"signature,omitempty"`
+}
+
+// NewMessage creates a message stamped with the current time
+func NewMessage(msgType MessageType, source, body, severity string) *Message {
+	return &Message{
+		Type:     msgType,
+		Source:   source,
+		Body:     body,
+		Severity: severity,
+		Host:     localHostname(),
+		Time:     time.Now(),
+	}
+}
+
+// MessageField is a labelled piece of message metadata shown as a structured field
+type MessageField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Fields returns the message metadata that providers show as structured fields
+func (m *Message) Fields() []MessageField {
+	var fields []MessageField
+	if m.Source != "" {
+		fields = append(fields, MessageField{"Source", m.Source})
+	}
+	if m.Monitor != "" && m.Monitor != m.Source {
+		fields = append(fields, MessageField{"Monitor", m.Monitor})
+	}
+	if m.Host != "" {
+		fields = append(fields, MessageField{"Host", m.Host})
+	}
+	if m.Severity != "" {
+		fields = append(fields, MessageField{"Severity", m.Severity})
+	}
+	if m.LineCount > 0 {
+		fields = append(fields, MessageField{"Lines", strconv.Itoa(m.LineCount)})
+	}
+	if window := m.formattedWindow(); window != "" {
+		fields = append(fields, MessageField{"Window", window})
+	}
+	fields = append(fields, m.Details...)
+	fields = append(fields, MessageField{"Time", m.formattedTime()})
+	return fields
+}
+
+// formattedWindow returns the time range the message covers, or "" if unknown
+func (m *Message) formattedWindow() string {
+	if m.WindowStart.IsZero() || m.WindowEnd.IsZero() {
+		return ""
+	}
+
+	duration := m.WindowEnd.Sub(m.WindowStart).Round(time.Second)
+	layout := "15:04:05"
+	if m.WindowStart.YearDay() != m.WindowEnd.YearDay() || m.WindowStart.Year() != m.WindowEnd.Year() {
+		layout = "2006-01-02 15:04:05"
+	}
+	return fmt.Sprintf("%s - %s (%v)", m.WindowStart.Format(layout), m.WindowEnd.Format(layout), duration)
+}
+
+// DisplayTitle returns the title shown by providers that support one
+func (m *Message) DisplayTitle() string {
+	if m.Title != "" {
+		return m.Title
+	}
+	switch m.Type {
+	case MessageTypeSummary:
+		return "🚨 Log Summary Notification"
+	case MessageTypeFinalSummary:
+		return "📋 Final Summary"
+	case MessageTypeError:
+		return "🚨 Critical Error Alert"
+	case MessageTypeLifecycle:
+		switch m.Event {
+		case LifecycleStarted:
+			return "▶️ Monitoring Started"
+		case LifecycleStopped:
+			return "⏹️ Monitoring Stopped"
+		case LifecycleCrashed:
+			return "💥 Monitoring Crashed"
+		case LifecycleGaveUp:
+			return "🛑 Restarts Exhausted"
+		case LifecycleRecovered:
+			return "🔊 Source Recovered"
+		}
+		return "🔄 Monitor Status"
+	default:
+		return "📢 Lai Notification"
+	}
+}
+
+// formattedTime returns the message time in the format used across notifications
+func (m *Message) formattedTime() string {
+	if m.Time.IsZero() {
+		return getCurrentTimeNotify()
+	}
+	return m.Time.Format("2006-01-02 15:04:05")
+}
+
+var (
+	hostnameOnce sync.Once
+	hostname     string
+)
+
+// localHostname returns the machine's hostname, looked up once
+func localHostname() string {
+	hostnameOnce.Do(func() {
+		hostname, _ = os.Hostname()
+	})
+	return hostname
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageFields(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := &Message{
+		Type:        MessageTypeSummary,
+		Source:      "/var/log/app.log",
+		Monitor:     "api",
+		Host:        "web-01",
+		Severity:    SeverityWarning,
+		Time:        start.Add(30 * time.Second),
+		LineCount:   42,
+		WindowStart: start,
+		WindowEnd:   start.Add(30 * time.Second),
+	}
+
+	assert.Equal(t, []MessageField{
+		{"Source", "/var/log/app.log"},
+		{"Monitor", "api"},
+		{"Host", "web-01"},
+		{"Severity", "warning"},
+		{"Lines", "42"},
+		{"Window", "12:00:00 - 12:00:30 (30s)"},
+		{"Time", "2024-01-01 12:00:30"},
+	}, msg.Fields())
+}
+
+func TestMessageFieldsOmitsUnknownMetadata(t *testing.T) {
+	msg := &Message{Source: "npm start", Monitor: "npm start", Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	// Monitor is omitted when it only repeats the source
+	assert.Equal(t, []MessageField{
+		{"Source", "npm start"},
+		{"Time", "2024-01-01 12:00:00"},
+	}, msg.Fields())
+}
+
+func TestMessageWindowAcrossDays(t *testing.T) {
+	start := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	msg := &Message{WindowStart: start, WindowEnd: start.Add(2 * time.Minute)}
+	assert.Equal(t, "2024-01-01 23:59:00 - 2024-01-02 00:01:00 (2m0s)", msg.formattedWindow())
+}
+
+func TestLifecycleDisplayTitle(t *testing.T) {
+	msg := NewMessage(MessageTypeLifecycle, "app.log", "", SeverityError)
+	msg.Event = LifecycleCrashed
+	assert.Equal(t, "💥 Monitoring Crashed", msg.DisplayTitle())
+
+	msg.Event = LifecycleStarted
+	assert.Equal(t, "▶️ Monitoring Started", msg.DisplayTitle())
+}
+
+func TestMessageFieldsIncludeDetails(t *testing.T) {
+	msg := &Message{
+		Source:  "make build",
+		Details: []MessageField{{"Exit code", "2"}, {"Duration", "3s"}},
+		Time:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	fields := msg.Fields()
+	assert.Equal(t, []MessageField{
+		{"Source", "make build"},
+		{"Exit code", "2"},
+		{"Duration", "3s"},
+		{"Time", "2024-01-01 12:00:00"},
+	}, fields)
+}
@@ -5,12 +5,17 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/shiquda/lai/internal/config"
 )
 
+// OutboxRetryInterval is how often a process using an outbox retries queued
+// notifications
+const OutboxRetryInterval = 30 * time.Second
+
 // Notifier defines the interface for all notification implementations.
 // Any notifier must implement these methods to be compatible with the system.
 type Notifier interface {
@@ -23,6 +28,17 @@ type Notifier interface {
 	// SendLogSummary sends a formatted log summary to the notification channel
 	// This method typically uses templates to format the message consistently
 	SendLogSummary(filePath, summary string) error
+
+	// Send delivers a structured message, letting the notifier route it by severity and source
+	Send(msg *Message) error
+
+	// Close flushes pending notifications such as repeat summaries
+	Close() error
+}
+
+// MessageSender is implemented by unified notifiers that can route structured messages
+type MessageSender interface {
+	Send(ctx context.Context, msg *Message) error
 }
 
 // UnifiedNotifier defines the interface for the new unified notification system
@@ -68,6 +84,7 @@ func CreateUnifiedNotifier(cfg *config.Config) (UnifiedNotifier, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create notify notifier: %w", err)
 	}
+	notifyNotifier.SetDedupWindow(cfg.DedupWindow)
 
 	return notifyNotifier, nil
 }
@@ -88,11 +105,17 @@ func CreateUnifiedNotifierForMonitor(monitorConfig interface{}) (UnifiedNotifier
 // TemplateData represents the data available in message templates.
 // These fields can be used in custom notification templates.
 type TemplateData struct {
-	FilePath    string // Path to the log file being monitored
-	Time        string // Timestamp when the notification was sent
-	Summary     string // AI-generated log summary content
-	ProcessName string // Name of the monitoring process (if set)
-	LineCount   int    // Number of lines that triggered the notification
+	FilePath    string            // Path to the log file being monitored
+	Time        string            // Timestamp when the notification was sent
+	Summary     string            // AI-generated log summary content
+	ProcessName string            // Name of the monitoring process (if set)
+	LineCount   int               // Number of lines that triggered the notification
+	Severity    string            // error, warning or info
+	Host        string            // Hostname of the machine running lai
+	Type        string            // summary, final_summary, error, lifecycle or message
+	Event       string            // started, stopped, crashed or gave_up for lifecycle messages
+	Window      string            // Time range the summary covers (if known)
+	Details     map[string]string // Extra fields by name, e.g. "Exit code"
 }
 
 // getCurrentTime returns the current time in a standardized format.
@@ -118,6 +141,9 @@ func SendLogSummary(notifier Notifier, filePath, summary string, messageTemplate
 		FilePath: filePath,
 		Time:     getCurrentTime(),
 		Summary:  summary,
+		Severity: SeverityInfo,
+		Host:     localHostname(),
+		Type:     string(MessageTypeSummary),
 	}
 
 	message, err := RenderTemplate("log_summary", data, messageTemplates, getDefaultTemplates)
@@ -172,17 +198,38 @@ func RenderTemplate(templateName string, data TemplateData, messageTemplates map
 //
 // Priority order for determining which notifiers to enable:
 // 1. Command line specifications (--notifiers telegram,email)
-// 2. Provider configuration (notifications.providers.*.enabled)
-// 3. All configured notifiers (if no specification exists)
+// 2. Per-monitor selection from config (defaults.notifiers)
+// 3. Provider configuration (notifications.providers.*.enabled)
 //
 // Parameters:
 //   - cfg: Configuration containing notifier settings
-//   - enabledNotifiers: List of notifiers to enable (from command line)
+//   - enabledNotifiers: List of notifiers to enable (empty means all enabled providers)
 //
 // Returns:
 //   - Slice of configured notifier instances
 //   - Error if no valid notifiers can be created
 func CreateNotifiers(cfg *config.Config, enabledNotifiers []string) ([]Notifier, error) {
+	// Restrict delivery to the requested providers when a selection is given
+	if len(enabledNotifiers) > 0 {
+		// Work on a copy so the caller's configuration is left untouched
+		selectedConfig := *cfg
+		if len(selectedConfig.Notifications.Providers) == 0 {
+			// The migration fills in a new map rather than the caller's empty one
+			selectedConfig.Notifications.Providers = nil
+			migratedConfig := config.MigrateToNewProviderConfig(&config.GlobalConfig{
+				Notifications: selectedConfig.Notifications,
+			})
+			selectedConfig.Notifications = migratedConfig.Notifications
+		}
+
+		selected, err := SelectProviders(selectedConfig.Notifications, enabledNotifiers)
+		if err != nil {
+			return nil, err
+		}
+		selectedConfig.Notifications = selected
+		cfg = &selectedConfig
+	}
+
 	// For backward compatibility, create a unified notifier
 	// This will automatically migrate legacy configuration if needed
 	unifiedNotifier, err := CreateUnifiedNotifier(cfg)
@@ -195,11 +242,63 @@ func CreateNotifiers(cfg *config.Config, enabledNotifiers []string) ([]Notifier,
 		return nil, fmt.Errorf("no notification channels enabled")
 	}
 
+	// Keep undelivered notifications on disk if the process uses an outbox
+	if notifyNotifier, ok := unifiedNotifier.(*NotifyNotifier); ok {
+		attachProcessOutbox(notifyNotifier)
+	}
+
 	// Return a slice containing just the unified notifier
 	// This maintains compatibility with the existing interface
 	return []Notifier{&UniversalNotifier{unified: unifiedNotifier}}, nil
 }
 
+// SelectProviders returns a copy of the notifications config that only contains
+// the named providers. A name matches a provider key (e.g. "oncall") or a provider
+// type (e.g. "pagerduty"). Explicitly selected providers are enabled even if they
+// are disabled in the global config, so a monitor can opt into a channel that
+// other monitors do not use.
+func SelectProviders(notifications config.NotificationsConfig, names []string) (config.NotificationsConfig, error) {
+	selected := make(map[string]config.ServiceConfig)
+	var unknown []string
+
+	for _, rawName := range names {
+		name := strings.ToLower(strings.TrimSpace(rawName))
+		if name == "" {
+			continue
+		}
+
+		matched := false
+		for key, serviceConfig := range notifications.Providers {
+			if strings.ToLower(key) == name || strings.ToLower(serviceConfig.Provider) == name {
+				serviceConfig.Enabled = true
+				selected[key] = serviceConfig
+				matched = true
+			}
+		}
+		if !matched {
+			unknown = append(unknown, name)
+		}
+	}
+
+	// Escalation targets stay available so the monitor's alerts can escalate
+	for key, serviceConfig := range notifications.Providers {
+		if _, ok := selected[key]; !ok && isEscalationProvider(notifications.Escalation, key, serviceConfig) {
+			serviceConfig.Enabled = false
+			selected[key] = serviceConfig
+		}
+	}
+
+	if len(unknown) > 0 {
+		return notifications, fmt.Errorf("unknown notifier(s): %s (not configured under notifications.providers)", strings.Join(unknown, ", "))
+	}
+	if len(selected) == 0 {
+		return notifications, fmt.Errorf("no notifiers selected")
+	}
+
+	notifications.Providers = selected
+	return notifications, nil
+}
+
 // UniversalNotifier is a wrapper that implements the legacy Notifier interface
 // but uses the new UnifiedNotifier internally for backward compatibility
 type UniversalNotifier struct {
@@ -226,3 +325,37 @@ func (un *UniversalNotifier) SendLogSummary(filePath, summary string) error {
 	ctx := context.Background()
 	return un.unified.SendLogSummary(ctx, filePath, summary)
 }
+
+// Send delivers a structured message. Unified notifiers that cannot route
+// messages receive it through the matching legacy method instead.
+func (un *UniversalNotifier) Send(msg *Message) error {
+	ctx := context.Background()
+	if sender, ok := un.unified.(MessageSender); ok {
+		return sender.Send(ctx, msg)
+	}
+
+	switch msg.Type {
+	case MessageTypeSummary, MessageTypeFinalSummary:
+		return un.unified.SendLogSummary(ctx, msg.Source, msg.Body)
+	case MessageTypeError:
+		return un.unified.SendError(ctx, msg.Source, msg.Body)
+	default:
+		return un.unified.SendMessage(ctx, msg.Body)
+	}
+}
+
+// Acknowledge stops the escalation of a monitor's pending alerts
+func (un *UniversalNotifier) Acknowledge(monitor string) int {
+	if acknowledger, ok := un.unified.(Acknowledger); ok {
+		return acknowledger.Acknowledge(monitor)
+	}
+	return 0
+}
+
+// Close flushes pending notifications of the underlying notifier
+func (un *UniversalNotifier) Close() error {
+	if closer, ok := un.unified.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
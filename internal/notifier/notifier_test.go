@@ -2,12 +2,17 @@ package notifier
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/nikoksr/notify"
 	"github.com/shiquda/lai/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -233,6 +238,246 @@ func TestGetCurrentTimeNotify(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSelectProviders(t *testing.T) {
+	notifications := config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"telegram": {Enabled: true, Provider: "telegram"},
+			"email":    {Enabled: true, Provider: "smtp"},
+			"oncall":   {Enabled: false, Provider: "pagerduty"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		selection   []string
+		expected    []string
+		expectError bool
+	}{
+		{name: "By provider key", selection: []string{"email"}, expected: []string{"email"}},
+		{name: "By provider type", selection: []string{"pagerduty"}, expected: []string{"oncall"}},
+		{name: "Multiple with spacing and case", selection: []string{" Telegram", "EMAIL "}, expected: []string{"email", "telegram"}},
+		{name: "Unknown provider", selection: []string{"slack"}, expectError: true},
+		{name: "Only blanks", selection: []string{" "}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := SelectProviders(notifications, tt.selection)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var keys []string
+			for key, serviceConfig := range selected.Providers {
+				keys = append(keys, key)
+				assert.True(t, serviceConfig.Enabled, "selected provider %s should be enabled", key)
+			}
+			assert.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+
+	// The original configuration must not be modified
+	assert.Len(t, notifications.Providers, 3)
+	assert.False(t, notifications.Providers["oncall"].Enabled)
+}
+
+func TestFallbackOnlyWhenAllPrimariesFail(t *testing.T) {
+	var primaryUp bool
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if primaryUp {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+
+	var fallbackBodies []string
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fallbackBodies = append(fallbackBodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer fallback.Close()
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"discord": {
+				Enabled:  true,
+				Provider: "discord_webhook",
+				Config:   map[string]interface{}{"webhook_url": primary.URL},
+			},
+		},
+		Fallback: &config.FallbackConfig{
+			Enabled:  true,
+			Provider: "discord_webhook",
+			Config:   map[string]interface{}{"webhook_url": fallback.URL},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"discord"}, nn.GetEnabledChannels(), "fallback must not be a primary channel")
+
+	// Primary works: the fallback is not used
+	primaryUp = true
+	assert.NoError(t, nn.SendMessage(context.Background(), "all good"))
+	assert.Empty(t, fallbackBodies)
+
+	// Primary fails: the message goes through the fallback with a note
+	primaryUp = false
+	assert.NoError(t, nn.SendMessage(context.Background(), "disk full"))
+	if assert.Len(t, fallbackBodies, 1) {
+		assert.Contains(t, fallbackBodies[0], "Primary delivery failed")
+		assert.Contains(t, fallbackBodies[0], "disk full")
+	}
+}
+
+func TestFallbackSkippedWhenPrimaryIsQueued(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+
+	fallbackCalls := 0
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer fallback.Close()
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"discord": {
+				Enabled:  true,
+				Provider: "discord_webhook",
+				Config:   map[string]interface{}{"webhook_url": primary.URL},
+			},
+		},
+		Fallback: &config.FallbackConfig{
+			Enabled:  true,
+			Provider: "discord_webhook",
+			Config:   map[string]interface{}{"webhook_url": fallback.URL},
+		},
+	})
+	require.NoError(t, err)
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+	nn.SetOutbox(outbox)
+
+	// The failed message is retried from the outbox, so a fallback copy would be a duplicate
+	assert.NoError(t, nn.SendMessage(context.Background(), "disk full"))
+	assert.Zero(t, fallbackCalls)
+	entries, err := outbox.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCreateNotifiersLeavesCallerConfigAlone(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Notifications.OpenAI.APIKey = "test-key"
+	cfg.Notifications.Providers = map[string]config.ServiceConfig{}
+
+	// Legacy configuration is migrated to providers before the selection
+	_, _ = CreateNotifiers(cfg, []string{"telegram"})
+	assert.Empty(t, cfg.Notifications.Providers)
+}
+
+func TestCreateNotifiersOutboxIsOptIn(t *testing.T) {
+	cfg := &config.Config{Notifications: config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"discord_webhook": {
+				Enabled:  true,
+				Provider: "discord_webhook",
+				Config:   map[string]interface{}{"webhook_url": "http://127.0.0.1:1/webhook"},
+			},
+		},
+	}}
+	notifyNotifier := func(notifiers []Notifier) *NotifyNotifier {
+		require.Len(t, notifiers, 1)
+		return notifiers[0].(*UniversalNotifier).unified.(*NotifyNotifier)
+	}
+
+	notifiers, err := CreateNotifiers(cfg, nil)
+	require.NoError(t, err)
+	assert.Nil(t, notifyNotifier(notifiers).outbox, "no outbox unless the process asks for one")
+
+	// Once the process uses an outbox, open notifiers are retried through it
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+	processOutbox.outbox, processOutbox.notifiers = outbox, make(map[*NotifyNotifier]bool)
+	t.Cleanup(func() { processOutbox.outbox, processOutbox.notifiers = nil, nil })
+
+	notifiers, err = CreateNotifiers(cfg, nil)
+	require.NoError(t, err)
+	nn := notifyNotifier(notifiers)
+	assert.Same(t, outbox, nn.outbox)
+	assert.True(t, processOutbox.notifiers[nn])
+	require.NoError(t, nn.Close())
+	assert.False(t, processOutbox.notifiers[nn])
+}
+
+func TestDiscordEmbed(t *testing.T) {
+	tests := []struct {
+		severity string
+		color    int
+	}{
+		{SeverityError, 0xE74C3C},
+		{SeverityWarning, 0xF39C12},
+		{SeverityInfo, 0x3498DB},
+	}
+
+	for _, tt := range tests {
+		msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "summary text", tt.severity)
+		msg.Monitor = "api"
+		msg.LineCount = 7
+
+		embed := discordEmbed(msg)
+		assert.Equal(t, tt.color, embed.Color, "severity %s", tt.severity)
+		assert.Equal(t, "summary text", embed.Description)
+		assert.NotEmpty(t, embed.Timestamp)
+
+		names := make([]string, 0, len(embed.Fields))
+		for _, field := range embed.Fields {
+			names = append(names, field.Name)
+		}
+		assert.Equal(t, []string{"Source", "Monitor", "Host", "Severity", "Lines"}, names)
+		assert.False(t, embed.Fields[0].Inline, "source should use the full width")
+		assert.True(t, embed.Fields[1].Inline)
+	}
+}
+
+func TestFormatTelegramMessage(t *testing.T) {
+	nn := &NotifyNotifier{}
+
+	short := NewMessage(MessageTypeSummary, "/var/log/app.log", "**2** errors <found>", SeverityError)
+	formatted := nn.formatTelegramMessage(short)
+	assert.Contains(t, formatted, "📁 <b>Source:</b> /var/log/app.log")
+	assert.Contains(t, formatted, "🔴 <b>Severity:</b> error")
+	assert.Contains(t, formatted, "<b>2</b> errors &lt;found&gt;")
+	assert.NotContains(t, formatted, "blockquote")
+
+	lead := "Database connections are failing."
+	details := strings.Repeat("Connection refused from pool worker\n", 30)
+	long := NewMessage(MessageTypeSummary, "/var/log/app.log", lead+"\n\n"+details, SeverityError)
+	formatted = nn.formatTelegramMessage(long)
+	assert.Contains(t, formatted, lead+"\n<blockquote expandable>Connection refused")
+	assert.True(t, strings.HasSuffix(formatted, "</blockquote>"))
+}
+
+func TestSplitLeadParagraph(t *testing.T) {
+	lead, rest := splitLeadParagraph("first\n\nsecond\n\nthird")
+	assert.Equal(t, "first", lead)
+	assert.Equal(t, "second\n\nthird", rest)
+
+	lead, rest = splitLeadParagraph("single paragraph")
+	assert.Empty(t, lead)
+	assert.Equal(t, "single paragraph", rest)
+
+	lead, _ = splitLeadParagraph("```\ncode\n\nmore\n```")
+	assert.Empty(t, lead, "code blocks must not be split")
+}
+
 func TestNotifierTestSuite(t *testing.T) {
 	suite.Run(t, new(NotifyNotifierTestSuite))
 }
@@ -4,16 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/nikoksr/notify"
 	"github.com/nikoksr/notify/service/discord"
-	"github.com/nikoksr/notify/service/slack"
 	"github.com/nikoksr/notify/service/telegram"
 	"github.com/shiquda/lai/internal/config"
 	"github.com/shiquda/lai/internal/logger"
@@ -35,12 +38,32 @@ type DiscordWebhookPayload struct {
 
 // Embed represents a Discord embed object
 type Embed struct {
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	Color       int    `json:"color,omitempty"`
-	Timestamp   string `json:"timestamp,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
 }
 
+// EmbedField is a labelled value shown in a Discord embed
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// EmbedFooter is the small text shown at the bottom of a Discord embed
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Discord embed limits
+const (
+	discordDescriptionLimit = 4096
+	discordFieldValueLimit  = 1024
+)
+
 // Send sends a message via Discord webhook
 func (d *DiscordWebhookService) Send(message string) error {
 	payload := DiscordWebhookPayload{
@@ -68,6 +91,26 @@ func (d *DiscordWebhookService) SendEmbed(title, description string, color int)
 	return d.sendPayload(payload)
 }
 
+// sendEmbeds sends fully built embeds via Discord webhook
+func (d *DiscordWebhookService) sendEmbeds(embeds ...Embed) error {
+	return d.sendPayload(DiscordWebhookPayload{Embeds: embeds, Username: d.username})
+}
+
+// sendEmbedsWithFile sends embeds with a file attached via Discord webhook
+func (d *DiscordWebhookService) sendEmbedsWithFile(attachment *Attachment, embeds ...Embed) error {
+	payload, err := json.Marshal(DiscordWebhookPayload{Embeds: embeds, Username: d.username})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	fields := map[string]string{"payload_json": string(payload)}
+	if err := postMultipart(context.Background(), client, d.webhookURL, fields, "files[0]", attachment); err != nil {
+		return fmt.Errorf("discord webhook: %w", err)
+	}
+	return nil
+}
+
 // sendPayload sends the actual HTTP request to Discord webhook
 func (d *DiscordWebhookService) sendPayload(payload DiscordWebhookPayload) error {
 	jsonData, err := json.Marshal(payload)
@@ -104,7 +147,43 @@ type NotifyNotifier struct {
 	config          *config.NotificationsConfig
 	enabledServices map[string]bool
 	serviceConfigs  map[string]config.ServiceConfig
-	discordWebhook  *DiscordWebhookService
+	services        map[string]notify.Notifier
+	discordWebhooks map[string]*DiscordWebhookService
+	messageServices map[string]messageService
+	templates       map[string]*messageTemplates
+	router          *Router
+	dedupers        map[string]*Deduplicator
+	limiters        map[string]*RateLimiter
+	schedules       map[string]*QuietSchedule
+	escalator       *Escalator
+	escalateTo      []string
+	outbox          *Outbox
+	hasFallback     bool
+	closing         atomic.Bool // Set by Close; digests flushed on close skip quiet hours
+}
+
+// fallbackProviderName is the internal name of the fallback provider, which is
+// only used when every primary provider fails to deliver a message
+const fallbackProviderName = "fallback"
+
+// queuedError is a delivery failure whose message was stored in the outbox for retry
+type queuedError struct {
+	entryID string
+	err     error
+}
+
+func (e *queuedError) Error() string {
+	return fmt.Sprintf("%v (queued for retry as %s)", e.err, e.entryID)
+}
+
+func (e *queuedError) Unwrap() error {
+	return e.err
+}
+
+// isQueued reports whether a delivery error was stored in the outbox for retry
+func isQueued(err error) bool {
+	var queued *queuedError
+	return errors.As(err, &queued)
 }
 
 // NewNotifyNotifier creates a new notify notifier
@@ -114,6 +193,23 @@ func NewNotifyNotifier(cfg *config.NotificationsConfig) (*NotifyNotifier, error)
 		config:          cfg,
 		enabledServices: make(map[string]bool),
 		serviceConfigs:  make(map[string]config.ServiceConfig),
+		services:        make(map[string]notify.Notifier),
+		discordWebhooks: make(map[string]*DiscordWebhookService),
+		messageServices: make(map[string]messageService),
+		templates:       make(map[string]*messageTemplates),
+		schedules:       make(map[string]*QuietSchedule),
+	}
+
+	router, err := NewRouter(cfg.Routing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid routing configuration: %w", err)
+	}
+	nn.router = router
+
+	if cfg.Escalation != nil {
+		if err := validateEscalation(cfg.Escalation); err != nil {
+			return nil, fmt.Errorf("invalid escalation configuration: %w", err)
+		}
 	}
 
 	// Setup all enabled notification services
@@ -121,6 +217,9 @@ func NewNotifyNotifier(cfg *config.NotificationsConfig) (*NotifyNotifier, error)
 		return nil, fmt.Errorf("failed to setup notification services: %w", err)
 	}
 
+	nn.setupRateLimits()
+	nn.setupEscalation()
+
 	return nn, nil
 }
 
@@ -131,7 +230,10 @@ func (nn *NotifyNotifier) setupServices() error {
 	}
 
 	for providerName, serviceConfig := range nn.config.Providers {
-		if !serviceConfig.Enabled {
+		// Escalation targets are set up even when disabled, but only receive
+		// escalated alerts
+		escalation := isEscalationProvider(nn.config.Escalation, providerName, serviceConfig)
+		if !serviceConfig.Enabled && !escalation {
 			logger.Infof("Provider %s is disabled, skipping", providerName)
 			continue
 		}
@@ -144,9 +246,18 @@ func (nn *NotifyNotifier) setupServices() error {
 			continue
 		}
 
+		if escalation {
+			nn.escalateTo = append(nn.escalateTo, providerName)
+		}
+		if !serviceConfig.Enabled {
+			logger.Infof("Provider %s is set up for escalations only", providerName)
+			continue
+		}
+
 		nn.enabledServices[providerName] = true
 		logger.Infof("Successfully enabled %s service", providerName)
 	}
+	sort.Strings(nn.escalateTo)
 
 	// Setup fallback service
 	if nn.config.Fallback != nil && nn.config.Fallback.Enabled {
@@ -164,13 +275,36 @@ func (nn *NotifyNotifier) setupServices() error {
 
 // setupProvider sets up a single notification service
 func (nn *NotifyNotifier) setupProvider(providerName string, serviceConfig config.ServiceConfig) error {
+	templates, err := parseMessageTemplates(serviceConfig.Templates)
+	if err != nil {
+		return err
+	}
+	if templates != nil {
+		nn.templates[providerName] = templates
+	}
+
+	schedule, err := NewQuietSchedule(serviceConfig.Schedule, func(digest *Message) {
+		if err := nn.dispatch(context.Background(), providerName, digest); err != nil && !isQueued(err) {
+			logger.Errorf("Failed to send quiet hours digest to %s: %v", providerName, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if schedule != nil {
+		nn.schedules[providerName] = schedule
+	}
+	if serviceConfig.Attachments.Enabled && !supportsAttachments(serviceConfig) {
+		logger.Warnf("Provider %s (%s) cannot send files, log excerpt attachments are ignored", providerName, serviceConfig.Provider)
+	}
+
 	switch serviceConfig.Provider {
 	case "telegram":
-		return nn.setupTelegramService(serviceConfig)
+		return nn.setupTelegramService(providerName, serviceConfig)
 	case "slack", "slack_webhook":
-		return nn.setupSlackService(serviceConfig)
+		return nn.setupSlackService(providerName, serviceConfig)
 	case "discord", "discord_webhook":
-		return nn.setupDiscordService(serviceConfig)
+		return nn.setupDiscordService(providerName, serviceConfig)
 	case "email", "smtp", "gmail", "sendgrid", "mailgun":
 		return nn.setupEmailService(serviceConfig)
 	case "pushover":
@@ -183,6 +317,8 @@ func (nn *NotifyNotifier) setupProvider(providerName string, serviceConfig confi
 		return nn.setupDingTalkService(serviceConfig)
 	case "wechat":
 		return nn.setupWeChatService(serviceConfig)
+	case "webhook", "teams", "mattermost", "rocketchat", "ntfy", "gotify", "matrix", "signal":
+		return nn.setupMessageService(providerName, serviceConfig)
 	default:
 		// Try to dynamically import other services
 		return nn.setupGenericService(providerName, serviceConfig)
@@ -190,7 +326,7 @@ func (nn *NotifyNotifier) setupProvider(providerName string, serviceConfig confi
 }
 
 // setupTelegramService sets up Telegram service using notify library
-func (nn *NotifyNotifier) setupTelegramService(serviceConfig config.ServiceConfig) error {
+func (nn *NotifyNotifier) setupTelegramService(providerName string, serviceConfig config.ServiceConfig) error {
 	token, ok := serviceConfig.Config["bot_token"].(string)
 	if !ok || token == "" {
 		return fmt.Errorf("telegram bot_token is required")
@@ -226,48 +362,24 @@ func (nn *NotifyNotifier) setupTelegramService(serviceConfig config.ServiceConfi
 		telegramService.AddReceivers(chatIDInt)
 	}
 
-	// Add the service to notifyClient
-	nn.notifyClient.UseServices(telegramService)
+	nn.useService(providerName, telegramService)
 
 	return nil
 }
 
 // setupSlackService sets up Slack service
-func (nn *NotifyNotifier) setupSlackService(serviceConfig config.ServiceConfig) error {
-	if serviceConfig.Provider == "slack_webhook" {
-		webhookURL, ok := serviceConfig.Config["webhook_url"].(string)
-		if !ok || webhookURL == "" {
-			return fmt.Errorf("slack webhook_url is required")
-		}
-
-		slackService := slack.New(webhookURL)
-		nn.notifyClient.UseServices(slackService)
-		return nil
-	}
-
-	// OAuth token method
-	oauthToken, ok := serviceConfig.Config["oauth_token"].(string)
-	if !ok || oauthToken == "" {
-		return fmt.Errorf("slack oauth_token is required")
-	}
-
-	slackService := slack.New(oauthToken)
-
-	// Add channels
-	if channelIDs, ok := serviceConfig.Config["channel_ids"].([]interface{}); ok {
-		for _, channelID := range channelIDs {
-			if idStr, ok := channelID.(string); ok && idStr != "" {
-				slackService.AddReceivers(idStr)
-			}
-		}
+func (nn *NotifyNotifier) setupSlackService(providerName string, serviceConfig config.ServiceConfig) error {
+	slackService, err := NewSlackService(serviceConfig)
+	if err != nil {
+		return err
 	}
 
-	nn.notifyClient.UseServices(slackService)
+	nn.messageServices[providerName] = slackService
 	return nil
 }
 
 // setupDiscordService sets up Discord service
-func (nn *NotifyNotifier) setupDiscordService(serviceConfig config.ServiceConfig) error {
+func (nn *NotifyNotifier) setupDiscordService(providerName string, serviceConfig config.ServiceConfig) error {
 	if serviceConfig.Provider == "discord_webhook" {
 		webhookURL, ok := serviceConfig.Config["webhook_url"].(string)
 		if !ok || webhookURL == "" {
@@ -281,14 +393,11 @@ func (nn *NotifyNotifier) setupDiscordService(serviceConfig config.ServiceConfig
 		}
 
 		// Create a custom webhook service since notify library doesn't support it
-		nn.discordWebhook = &DiscordWebhookService{
+		// We'll handle webhook sending separately in deliver
+		nn.discordWebhooks[providerName] = &DiscordWebhookService{
 			webhookURL: webhookURL,
 			username:  username,
 		}
-
-		// We'll handle webhook sending separately in the send methods
-		nn.enabledServices["discord_webhook"] = true
-		nn.serviceConfigs["discord_webhook"] = serviceConfig
 		return nil
 	}
 
@@ -312,7 +421,7 @@ func (nn *NotifyNotifier) setupDiscordService(serviceConfig config.ServiceConfig
 		}
 	}
 
-	nn.notifyClient.UseServices(discordService)
+	nn.useService(providerName, discordService)
 	return nil
 }
 
@@ -323,7 +432,7 @@ func (nn *NotifyNotifier) setupEmailService(serviceConfig config.ServiceConfig)
 		return nn.setupSendGridService(serviceConfig)
 	case "mailgun":
 		return nn.setupMailgunService(serviceConfig)
-	case "smtp", "gmail":
+	case "email", "smtp", "gmail":
 		return nn.setupSMTPService(serviceConfig)
 	default:
 		return fmt.Errorf("unsupported email provider: %s", serviceConfig.Provider)
@@ -454,13 +563,9 @@ func (nn *NotifyNotifier) setupSMTPService(serviceConfig config.ServiceConfig) e
 	// Suppress unused variable warnings (these are validated above)
 	_, _, _, _ = host, port, subject, useTLS
 
-	// Note: We don't need to create the email notifier here since we handle email specially in TestProvider
+	// Note: We don't need to create the email notifier here since email is handled specially in deliver
 	// The email notifier will be created on-demand when needed
 
-	// Add to enabled services
-	nn.enabledServices["email"] = true
-	nn.serviceConfigs["email"] = serviceConfig
-
 	return nil
 }
 
@@ -559,6 +664,39 @@ func (nn *NotifyNotifier) setupWeChatService(serviceConfig config.ServiceConfig)
 	return fmt.Errorf("wechat service requires additional dependency: github.com/nikoksr/notify/service/wechat")
 }
 
+// setupMessageService sets up a provider implemented directly over its HTTP API
+func (nn *NotifyNotifier) setupMessageService(providerName string, serviceConfig config.ServiceConfig) error {
+	var service messageService
+	var err error
+
+	switch serviceConfig.Provider {
+	case "webhook":
+		service, err = NewWebhookService(serviceConfig)
+	case "teams":
+		service, err = NewTeamsService(serviceConfig)
+	case "mattermost":
+		service, err = NewMattermostService(serviceConfig)
+	case "rocketchat":
+		service, err = NewRocketChatService(serviceConfig)
+	case "ntfy":
+		service, err = NewNtfyService(serviceConfig)
+	case "gotify":
+		service, err = NewGotifyService(serviceConfig)
+	case "matrix":
+		service, err = NewMatrixService(serviceConfig)
+	case "signal":
+		service, err = NewSignalService(serviceConfig)
+	default:
+		return fmt.Errorf("unsupported provider: %s", serviceConfig.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	nn.messageServices[providerName] = service
+	return nil
+}
+
 // setupGenericService sets up generic service (dynamic import)
 func (nn *NotifyNotifier) setupGenericService(providerName string, serviceConfig config.ServiceConfig) error {
 	// Dynamic service import logic can be implemented here
@@ -566,228 +704,531 @@ func (nn *NotifyNotifier) setupGenericService(providerName string, serviceConfig
 	return fmt.Errorf("provider '%s' is not supported. Please check if the service is available in the notify library", providerName)
 }
 
-// setupFallback sets up fallback service
+// setupFallback sets up the fallback service. It is kept out of the enabled
+// services so it is never routed to directly, only used when all primaries fail.
 func (nn *NotifyNotifier) setupFallback() error {
 	if nn.config.Fallback == nil || !nn.config.Fallback.Enabled {
 		return nil
 	}
 
 	fallbackConfig := config.ServiceConfig{
-		Enabled:  true,
-		Provider: nn.config.Fallback.Provider,
-		Config:   nn.config.Fallback.Config,
-		Defaults: make(map[string]interface{}),
+		Enabled:   true,
+		Provider:  nn.config.Fallback.Provider,
+		Config:    nn.config.Fallback.Config,
+		Defaults:  make(map[string]interface{}),
+		Templates: nn.config.Fallback.Templates,
+	}
+
+	if err := nn.setupProvider(fallbackProviderName, fallbackConfig); err != nil {
+		return err
 	}
 
-	return nn.setupProvider("fallback", fallbackConfig)
+	nn.serviceConfigs[fallbackProviderName] = fallbackConfig
+	nn.hasFallback = true
+	return nil
+}
+
+// useService registers a notify library service for the given provider
+func (nn *NotifyNotifier) useService(providerName string, service notify.Notifier) {
+	nn.services[providerName] = service
+}
+
+// serviceFor returns the notify library service registered for a provider.
+// It falls back to the shared client for notifiers built without per-provider services.
+func (nn *NotifyNotifier) serviceFor(providerName string) notify.Notifier {
+	if service, ok := nn.services[providerName]; ok {
+		return service
+	}
+	return nn.notifyClient
 }
 
 // SendLogSummary sends a log summary
 func (nn *NotifyNotifier) SendLogSummary(ctx context.Context, filePath, summary string) error {
-	if !nn.IsEnabled() {
-		return fmt.Errorf("no notification channels enabled")
-	}
+	return nn.Send(ctx, NewMessage(MessageTypeSummary, filePath, summary, SeverityInfo))
+}
 
-	var errors []error
+// SendMessage sends a plain message
+func (nn *NotifyNotifier) SendMessage(ctx context.Context, message string) error {
+	return nn.Send(ctx, NewMessage(MessageTypeMessage, "", message, SeverityInfo))
+}
+
+// SendError sends an error message
+func (nn *NotifyNotifier) SendError(ctx context.Context, filePath, errorMsg string) error {
+	return nn.Send(ctx, NewMessage(MessageTypeError, filePath, errorMsg, SeverityError))
+}
 
-	// Special handling for Discord webhook service
-	if nn.enabledServices["discord_webhook"] && nn.discordWebhook != nil {
-		// Create a nicely formatted embed for Discord
-		title := "🚨 Log Summary Notification"
-		description := fmt.Sprintf("**File:** %s\n**Time:** %s\n\n**Summary:**\n%s",
-			filePath, getCurrentTimeNotify(), summary)
+// SetDedupWindow enables deduplication of repeated notifications. Each provider
+// uses its own dedup_window when set, otherwise the given default window.
+func (nn *NotifyNotifier) SetDedupWindow(defaultWindow time.Duration) {
+	nn.dedupers = make(map[string]*Deduplicator)
 
-		if err := nn.discordWebhook.SendEmbed(title, description, 15158332); // Red color for alerts
-		err != nil {
-			errors = append(errors, fmt.Errorf("failed to send discord webhook notification: %w", err))
+	for providerName := range nn.enabledServices {
+		window := defaultWindow
+		if override := nn.serviceConfigs[providerName].DedupWindow; override != nil {
+			window = *override
+		}
+		if window <= 0 {
+			continue
 		}
-	}
 
-	// Special handling for email service since it's not using the notify library
-	if nn.enabledServices["email"] {
-		serviceConfig, exists := nn.serviceConfigs["email"]
-		if !exists {
-			errors = append(errors, fmt.Errorf("email service configuration not found"))
-		} else {
-			// Create email notifier for sending
-			emailNotifier, err := nn.createEmailNotifier(serviceConfig)
-			if err != nil {
-				errors = append(errors, fmt.Errorf("failed to create email notifier: %w", err))
-			} else {
-				// Use the original EmailNotifier's SendLogSummary method for proper HTML rendering
-				if err := emailNotifier.SendLogSummary(filePath, summary); err != nil {
-					errors = append(errors, fmt.Errorf("failed to send email notification: %w", err))
-				}
+		name := providerName
+		nn.dedupers[name] = NewDeduplicator(window, func(rollup *Message) {
+			if err := nn.dispatch(context.Background(), name, rollup); err != nil && !isQueued(err) {
+				logger.Errorf("Failed to send repeat summary to %s: %v", name, err)
 			}
+		})
+	}
+}
+
+// setupRateLimits creates a limiter for every provider that sets max_per_minute
+func (nn *NotifyNotifier) setupRateLimits() {
+	nn.limiters = make(map[string]*RateLimiter)
+
+	for providerName := range nn.enabledServices {
+		serviceConfig := nn.serviceConfigs[providerName]
+		if serviceConfig.MaxPerMinute <= 0 {
+			continue
 		}
+
+		name := providerName
+		nn.limiters[name] = NewRateLimiter(serviceConfig.MaxPerMinute, serviceConfig.DigestInterval, func(digest *Message) {
+			if err := nn.deliverOrQueue(context.Background(), name, digest); err != nil && !isQueued(err) {
+				logger.Errorf("Failed to send notification digest to %s: %v", name, err)
+			}
+		})
+	}
+}
+
+// setupEscalation starts tracking error alerts when escalation is configured
+func (nn *NotifyNotifier) setupEscalation() {
+	escalation := nn.config.Escalation
+	if escalation == nil {
+		return
+	}
+	if len(nn.escalateTo) == 0 {
+		logger.Warnf("Escalation disabled: none of the providers %s could be set up", strings.Join(escalation.Providers, ", "))
+		return
 	}
 
-	// Check if we have any services that use the notify library (including Telegram now)
-	hasNotifyServices := false
-	for service := range nn.enabledServices {
-		if service != "email" && service != "discord_webhook" { // Skip email and discord webhook
-			hasNotifyServices = true
-			break
+	nn.escalator = NewEscalator(escalation.After, func(msg *Message) {
+		logger.Warnf("Alert from %s not acknowledged within %v, escalating to %s", msg.Monitor, escalation.After, strings.Join(nn.escalateTo, ", "))
+		for _, providerName := range nn.escalateTo {
+			if err := nn.deliverOrQueue(context.Background(), providerName, msg); err != nil && !isQueued(err) {
+				logger.Errorf("Failed to escalate alert to %s: %v", providerName, err)
+			}
 		}
+	})
+}
+
+// Acknowledge stops the escalation of a monitor's pending error alerts
+func (nn *NotifyNotifier) Acknowledge(monitor string) int {
+	if nn.escalator == nil {
+		return 0
 	}
+	return nn.escalator.Acknowledge(monitor)
+}
 
-	if hasNotifyServices {
-		// Format message for notify library services
-		// For Telegram, we need to convert Markdown to a format it can handle
-		var message string
+// SetOutbox stores undelivered notifications in the outbox without retrying them automatically
+func (nn *NotifyNotifier) SetOutbox(outbox *Outbox) {
+	nn.outbox = outbox
+}
 
-		if nn.enabledServices["telegram"] {
-			// Convert Markdown to HTML format for Telegram
-			htmlFormattedSummary := nn.convertMarkdownToTelegramHTML(summary)
-			htmlSafeFilePath := nn.makeHTMLSafe(filePath)
+// RetryOutbox attempts delivery of queued notifications for the providers
+// enabled in this notifier or escalated to, optionally limited to the given entry IDs. Entries
+// that are not yet due are skipped unless force is set. It returns how many
+// entries were delivered and how many failed.
+func (nn *NotifyNotifier) RetryOutbox(ctx context.Context, force bool, ids []string) (sent, failed int) {
+	if nn.outbox == nil {
+		return 0, 0
+	}
 
-			message = fmt.Sprintf("🚨 <b>Log Summary Notification</b>\n\n📁 <b>File:</b> %s\n⏰ <b>Time:</b> %s\n\n📋 <b>Summary:</b>\n%s",
-				htmlSafeFilePath, getCurrentTimeNotify(), htmlFormattedSummary)
-		} else {
-			// Use simple plain text format for other services
-			plainSummary := nn.stripMarkdownFormatting(summary)
-			message = fmt.Sprintf("Log Summary: %s", plainSummary)
+	entries, err := nn.outbox.List()
+	if err != nil {
+		logger.Errorf("Failed to read outbox: %v", err)
+		return 0, 0
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	for _, queued := range entries {
+		if !nn.enabledServices[queued.Provider] && !slices.Contains(nn.escalateTo, queued.Provider) {
+			continue
+		}
+		if len(selected) > 0 && !selected[queued.ID] {
+			continue
 		}
 
-		// Send to all notify library services
-		if err := nn.notifyClient.Send(ctx, "Log Summary Notification", message); err != nil {
-			errors = append(errors, fmt.Errorf("failed to send notify library notification: %w", err))
+		entry, claimed := nn.outbox.Claim(queued.ID, force)
+		if !claimed {
+			continue
 		}
-	}
 
-	// Return combined errors if any
-	if len(errors) > 0 {
-		var errorMessages []string
-		for _, err := range errors {
-			errorMessages = append(errorMessages, err.Error())
+		if err := nn.deliver(ctx, entry.Provider, entry.Message); err != nil {
+			failed++
+			logger.Warnf("Retry %d of queued %s notification failed: %v", entry.Attempts, entry.Provider, err)
+			if err := nn.outbox.Release(entry, err); err != nil {
+				logger.Errorf("Failed to update outbox entry %s: %v", entry.ID, err)
+			}
+			continue
+		}
+
+		sent++
+		logger.Infof("Delivered queued %s notification after %d attempt(s)", entry.Provider, entry.Attempts+1)
+		if err := nn.outbox.Complete(entry); err != nil {
+			logger.Errorf("Failed to remove outbox entry %s: %v", entry.ID, err)
 		}
-		return fmt.Errorf("notification errors: %s", strings.Join(errorMessages, "; "))
 	}
 
+	return sent, failed
+}
+
+// Close flushes pending repeat summaries, quiet hours and rate-limit digests,
+// cancels pending escalations and stops outbox retries. Held messages are only
+// kept in memory, so the digests are delivered even during quiet hours.
+func (nn *NotifyNotifier) Close() error {
+	nn.closing.Store(true)
+	if nn.escalator != nil {
+		nn.escalator.Stop()
+	}
+	for _, deduplicator := range nn.dedupers {
+		deduplicator.Flush()
+	}
+	for _, schedule := range nn.schedules {
+		schedule.Flush()
+	}
+	for _, limiter := range nn.limiters {
+		limiter.Flush()
+	}
+	detachProcessOutbox(nn)
 	return nil
 }
 
-// SendMessage sends a plain message
-func (nn *NotifyNotifier) SendMessage(ctx context.Context, message string) error {
+// deliverOrQueue delivers a message and stores it in the outbox if delivery fails.
+// A message that was queued successfully is reported as a queuedError.
+func (nn *NotifyNotifier) deliverOrQueue(ctx context.Context, providerName string, msg *Message) error {
+	err := nn.deliver(ctx, providerName, msg)
+	if err == nil || nn.outbox == nil {
+		return err
+	}
+
+	entry, queueErr := nn.outbox.Add(providerName, msg, err)
+	if queueErr != nil {
+		return fmt.Errorf("%w (and failed to queue for retry: %v)", err, queueErr)
+	}
+
+	logger.Warnf("Failed to send %s notification, queued for retry as %s: %v", providerName, entry.ID, err)
+	return &queuedError{entryID: entry.ID, err: err}
+}
+
+// dispatch delivers a message to a provider, holding it for a digest during
+// the provider's quiet hours or when its rate limit is exhausted
+func (nn *NotifyNotifier) dispatch(ctx context.Context, providerName string, msg *Message) error {
+	if schedule := nn.schedules[providerName]; schedule != nil && !nn.closing.Load() && !schedule.Allow(msg) {
+		logger.Infof("Quiet hours for %s, message held for the digest", providerName)
+		return nil
+	}
+	if limiter := nn.limiters[providerName]; limiter != nil && !limiter.Allow(msg) {
+		logger.Infof("Rate limit reached for %s, message added to digest", providerName)
+		return nil
+	}
+	return nn.deliverOrQueue(ctx, providerName, msg)
+}
+
+// Send routes a message to the matching providers and delivers it to each of them.
+// If every provider it was sent to fails, the message goes to the fallback provider.
+// A provider whose message was queued in the outbox will still deliver it, so it
+// does not count as failed and the fallback is not used.
+func (nn *NotifyNotifier) Send(ctx context.Context, msg *Message) error {
 	if !nn.IsEnabled() {
 		return fmt.Errorf("no notification channels enabled")
 	}
 
-	// Special handling for Discord webhook service
-	if nn.enabledServices["discord_webhook"] && nn.discordWebhook != nil {
-		if err := nn.discordWebhook.Send(message); err != nil {
-			return fmt.Errorf("failed to send discord webhook notification: %w", err)
+	var sendErrors, failures []error
+	attempted := 0
+	for _, providerName := range nn.router.Route(msg, nn.GetEnabledChannels()) {
+		if deduplicator := nn.dedupers[providerName]; deduplicator != nil && !deduplicator.Allow(msg) {
+			logger.Infof("Suppressed repeated notification for %s", providerName)
+			continue
 		}
-	}
 
-	// Special handling for email service since it's not using the notify library
-	if nn.enabledServices["email"] {
-		serviceConfig, exists := nn.serviceConfigs["email"]
-		if !exists {
-			return fmt.Errorf("email service configuration not found")
+		attempted++
+		err := nn.dispatch(ctx, providerName, msg)
+		if err == nil {
+			continue
 		}
 
-		// Create email notifier for sending
-		emailNotifier, err := nn.createEmailNotifier(serviceConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create email notifier: %w", err)
+		// Queued messages are retried from the outbox and are not reported as errors
+		if isQueued(err) {
+			continue
 		}
+		failures = append(failures, fmt.Errorf("%s: %w", providerName, err))
+		sendErrors = append(sendErrors, fmt.Errorf("failed to send %s notification: %w", providerName, err))
+	}
 
-		// Convert the plain message to HTML email format
-		// Replace newlines with <br> for better email formatting
-		htmlMessage := strings.ReplaceAll(message, "\n", "<br>")
-		emailMessage := fmt.Sprintf("<html><body><h2>📢 Lai Notification</h2><p>%s</p></body></html>", htmlMessage)
-		return emailNotifier.SendMessage(emailMessage)
+	// Error alerts escalate unless they are acknowledged in time
+	if nn.escalator != nil && msg.Type == MessageTypeError && attempted > 0 {
+		tracked := *msg
+		nn.escalator.Track(&tracked)
 	}
 
-	return nn.notifyClient.Send(ctx, "📢 Lai Notification", message)
+	if nn.hasFallback && attempted > 0 && len(failures) == attempted {
+		if err := nn.sendFallback(ctx, msg, failures); err != nil {
+			sendErrors = append(sendErrors, fmt.Errorf("failed to send fallback notification: %w", err))
+		} else {
+			return nil
+		}
+	}
+
+	return combineErrors(sendErrors)
 }
 
-// SendError sends an error message
-func (nn *NotifyNotifier) SendError(ctx context.Context, filePath, errorMsg string) error {
-	if !nn.IsEnabled() {
-		return fmt.Errorf("no notification channels enabled")
+// sendFallback delivers a copy of the message through the fallback provider,
+// noting which primary providers failed and why
+func (nn *NotifyNotifier) sendFallback(ctx context.Context, msg *Message, failures []error) error {
+	reasons := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		reasons = append(reasons, failure.Error())
 	}
 
-	message := nn.formatMessage("error", map[string]interface{}{
-		"filePath": filePath,
-		"errorMsg": errorMsg,
-		"time":     getCurrentTimeNotify(),
-	})
+	fallbackMsg := *msg
+	fallbackMsg.Body = fmt.Sprintf("⚠️ Primary delivery failed, sent via fallback provider\n%s\n\n%s",
+		strings.Join(reasons, "\n"), msg.Body)
 
-	// Special handling for Discord webhook service
-	if nn.enabledServices["discord_webhook"] && nn.discordWebhook != nil {
-		// Create a nicely formatted embed for Discord error
-		title := "🚨 Critical Error Alert"
-		description := fmt.Sprintf("**File:** %s\n**Time:** %s\n\n**Error Details:**\n%s",
-			filePath, getCurrentTimeNotify(), errorMsg)
+	logger.Warnf("All primary notification providers failed, using fallback provider")
+	return nn.deliver(ctx, fallbackProviderName, &fallbackMsg)
+}
+
+// deliver sends a message to a single provider, formatting it for that provider.
+// Providers with attachments enabled also receive the log excerpt as a file.
+func (nn *NotifyNotifier) deliver(ctx context.Context, providerName string, msg *Message) error {
+	serviceConfig := nn.serviceConfigs[providerName]
+	msg = nn.templates[providerName].apply(msg)
 
-		if err := nn.discordWebhook.SendEmbed(title, description, 15105570); // Dark red color for errors
-		err != nil {
-			return fmt.Errorf("failed to send discord webhook notification: %w", err)
+	attachment, err := newAttachment(msg, serviceConfig.Attachments)
+	if err != nil {
+		logger.Warnf("Failed to prepare log excerpt for %s: %v", providerName, err)
+	}
+
+	if service, ok := nn.messageServices[providerName]; ok {
+		if err := service.SendMessage(ctx, msg); err != nil {
+			return err
+		}
+		if uploader, ok := service.(attachmentUploader); ok && attachment != nil {
+			nn.logAttachmentError(providerName, uploader.UploadAttachment(ctx, msg, attachment))
 		}
+		return nil
 	}
 
-	// Special handling for email service since it's not using the notify library
-	if nn.enabledServices["email"] {
-		serviceConfig, exists := nn.serviceConfigs["email"]
-		if !exists {
-			return fmt.Errorf("email service configuration not found")
+	switch {
+	case serviceConfig.Provider == "discord_webhook":
+		webhook, ok := nn.discordWebhooks[providerName]
+		if !ok {
+			return fmt.Errorf("discord webhook service not initialized")
+		}
+		if attachment != nil {
+			return webhook.sendEmbedsWithFile(attachment, discordEmbed(msg))
 		}
+		return nn.deliverDiscordWebhook(webhook, msg)
+	case isEmailProvider(serviceConfig.Provider):
+		return nn.deliverEmail(serviceConfig, msg, attachment)
+	case serviceConfig.Provider == "telegram":
+		title := "<b>" + nn.makeHTMLSafe(msg.DisplayTitle()) + "</b>"
+		if err := nn.serviceFor(providerName).Send(ctx, title, nn.formatTelegramMessage(msg)); err != nil {
+			return err
+		}
+		if attachment != nil {
+			nn.logAttachmentError(providerName, sendTelegramDocument(ctx, serviceConfig, msg, attachment))
+		}
+		return nil
+	default:
+		return nn.serviceFor(providerName).Send(ctx, msg.DisplayTitle(), nn.formatPlainMessage(msg))
+	}
+}
 
-		// Create email notifier for sending
-		emailNotifier, err := nn.createEmailNotifier(serviceConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create email notifier: %w", err)
+// attachmentUploader is a message service that can send a file after a message
+type attachmentUploader interface {
+	UploadAttachment(ctx context.Context, msg *Message, attachment *Attachment) error
+}
+
+// logAttachmentError logs a failed file upload. The message itself was
+// delivered, so the failure does not queue it for a retry.
+func (nn *NotifyNotifier) logAttachmentError(providerName string, err error) {
+	if err != nil {
+		logger.Warnf("Failed to attach log excerpt to %s notification: %v", providerName, err)
+	}
+}
+
+// deliverDiscordWebhook sends a message as a Discord embed colored by severity
+func (nn *NotifyNotifier) deliverDiscordWebhook(webhook *DiscordWebhookService, msg *Message) error {
+	return webhook.sendEmbeds(discordEmbed(msg))
+}
+
+// discordEmbed renders a message as an embed with the metadata as inline fields
+func discordEmbed(msg *Message) Embed {
+	embed := Embed{
+		Title:       msg.DisplayTitle(),
+		Description: truncateText(msg.Body, discordDescriptionLimit-1),
+		Color:       discordSeverityColor(msg.Severity),
+		Footer:      &EmbedFooter{Text: "Lai"},
+	}
+	if !msg.Time.IsZero() {
+		embed.Timestamp = msg.Time.Format(time.RFC3339)
+	}
+
+	for _, field := range msg.Fields() {
+		if field.Name == "Time" {
+			continue // Shown by the embed timestamp
 		}
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   field.Name,
+			Value:  truncateText(field.Value, discordFieldValueLimit-1),
+			Inline: field.Name != "Source" && field.Name != "Window",
+		})
+	}
+	return embed
+}
+
+// discordSeverityColor returns the embed color for a severity as an integer
+func discordSeverityColor(severity string) int {
+	color, _ := strconv.ParseInt(strings.TrimPrefix(severityColor(severity), "#"), 16, 32)
+	return int(color)
+}
 
-		// Convert the formatted message to HTML email format
-		// Replace newlines with <br> for better email formatting
-		htmlMessage := strings.ReplaceAll(message, "\n", "<br>")
-		emailMessage := fmt.Sprintf("<html><body><h2>🚨 Critical Error Alert</h2><p>%s</p></body></html>", htmlMessage)
-		return emailNotifier.SendMessage(emailMessage)
+// deliverEmail sends a message via SMTP as plain text with an HTML alternative
+func (nn *NotifyNotifier) deliverEmail(serviceConfig config.ServiceConfig, msg *Message, attachment *Attachment) error {
+	emailNotifier, err := nn.createEmailNotifier(serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create email notifier: %w", err)
+	}
+
+	htmlBody, err := renderEmailHTML(msg)
+	if err != nil {
+		return err
 	}
 
-	return nn.notifyClient.Send(ctx, "🚨 Critical Error Alert", message)
+	// A configured subject takes precedence over the per-message title
+	subject := ""
+	if configString(serviceConfig.Config, "subject") == "" {
+		subject = msg.DisplayTitle()
+	}
+	if attachment != nil {
+		return emailNotifier.SendMultipart(subject, renderEmailText(msg), htmlBody, attachment)
+	}
+	return emailNotifier.SendMultipart(subject, renderEmailText(msg), htmlBody)
 }
 
-// TestProvider tests a specific provider
-func (nn *NotifyNotifier) TestProvider(ctx context.Context, providerName string, message string) error {
-	if !nn.enabledServices[providerName] {
-		return fmt.Errorf("provider %s is not enabled", providerName)
+// telegramCollapseThreshold is the body length above which Telegram messages
+// show only the first paragraph and collapse the rest
+const telegramCollapseThreshold = 600
+
+// formatTelegramMessage formats the body of a message as Telegram HTML; the
+// title is sent separately as the subject. Long bodies keep their first
+// paragraph visible and move the details into an expandable quote.
+func (nn *NotifyNotifier) formatTelegramMessage(msg *Message) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, field := range msg.Fields() {
+		fmt.Fprintf(&b, "%s <b>%s:</b> %s\n", telegramFieldIcon(field.Name, msg.Severity), field.Name, nn.makeHTMLSafe(field.Value))
+	}
+
+	body := strings.TrimSpace(msg.Body)
+	if body == "" {
+		return strings.TrimRight(b.String(), "\n")
 	}
+	b.WriteString("\n")
 
-	// Special handling for Discord webhook service
-	if providerName == "discord_webhook" && nn.discordWebhook != nil {
-		testMessage := fmt.Sprintf("🧪 Test Message from Lai\n\nProvider: %s\nTime: %s\nMessage: %s",
-			providerName, getCurrentTimeNotify(), message)
+	if len([]rune(body)) <= telegramCollapseThreshold {
+		b.WriteString(nn.convertMarkdownToTelegramHTML(body))
+		return b.String()
+	}
 
-		return nn.discordWebhook.Send(testMessage)
+	lead, details := splitLeadParagraph(body)
+	if lead != "" {
+		b.WriteString(nn.convertMarkdownToTelegramHTML(lead) + "\n")
 	}
+	b.WriteString("<blockquote expandable>" + nn.convertMarkdownToTelegramHTML(details) + "</blockquote>")
+	return b.String()
+}
 
-	// Special handling for email service since it's not using the notify library
-	if providerName == "email" {
-		serviceConfig, exists := nn.serviceConfigs[providerName]
-		if !exists {
-			return fmt.Errorf("email service configuration not found")
-		}
+// splitLeadParagraph splits text after its first paragraph. The lead is empty
+// when the text has a single paragraph or starts with a code block.
+func splitLeadParagraph(text string) (lead, rest string) {
+	index := strings.Index(text, "\n\n")
+	if index <= 0 || strings.Contains(text[:index], "```") {
+		return "", text
+	}
+	return text[:index], strings.TrimSpace(text[index:])
+}
 
-		// Create a temporary email notifier for testing
-		emailNotifier, err := nn.createEmailNotifier(serviceConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create email notifier: %w", err)
-		}
+// telegramFieldIcon returns the emoji shown before a metadata field
+func telegramFieldIcon(name, severity string) string {
+	switch name {
+	case "Source":
+		return "📁"
+	case "Monitor":
+		return "🏷"
+	case "Host":
+		return "🖥"
+	case "Severity":
+		return severityEmoji(severity)
+	case "Lines":
+		return "📏"
+	case "Window":
+		return "🕒"
+	case "Time":
+		return "⏰"
+	default:
+		return "▫️"
+	}
+}
+
+// formatPlainMessage formats a message as plain text for services without rich formatting
+func (nn *NotifyNotifier) formatPlainMessage(msg *Message) string {
+	var b strings.Builder
+	for _, field := range msg.Fields() {
+		fmt.Fprintf(&b, "%s: %s\n", field.Name, field.Value)
+	}
+	b.WriteString("\n" + nn.stripMarkdownFormatting(msg.Body))
+	return b.String()
+}
+
+// TestProvider tests a specific provider
+func (nn *NotifyNotifier) TestProvider(ctx context.Context, providerName string, message string) error {
+	if !nn.enabledServices[providerName] && !(providerName == fallbackProviderName && nn.hasFallback) {
+		return fmt.Errorf("provider %s is not enabled", providerName)
+	}
 
-		testMessage := fmt.Sprintf("🧪 Test Message from Lai\n\nProvider: %s\nTime: %s\nMessage: %s",
-			providerName, getCurrentTimeNotify(), message)
+	testMessage := NewMessage(MessageTypeMessage, "", fmt.Sprintf("🧪 Test Message from Lai\n\nProvider: %s\nTime: %s\nMessage: %s",
+		providerName, getCurrentTimeNotify(), message), SeverityInfo)
+	testMessage.Title = "🧪 Lai Test Notification"
 
-		return emailNotifier.SendMessage(testMessage)
+	return nn.deliver(ctx, providerName, testMessage)
+}
+
+// isEmailProvider reports whether the provider type is delivered via SMTP
+func isEmailProvider(provider string) bool {
+	switch provider {
+	case "email", "smtp", "gmail":
+		return true
+	default:
+		return false
 	}
+}
 
-	testMessage := fmt.Sprintf("🧪 Test Message from Lai\n\nProvider: %s\nTime: %s\nMessage: %s",
-		providerName, getCurrentTimeNotify(), message)
+// combineErrors joins delivery errors into a single error, or returns nil
+func combineErrors(errors []error) error {
+	if len(errors) == 0 {
+		return nil
+	}
 
-	return nn.notifyClient.Send(ctx, "🧪 Lai Test Notification", testMessage)
+	var errorMessages []string
+	for _, err := range errors {
+		errorMessages = append(errorMessages, err.Error())
+	}
+	return fmt.Errorf("notification errors: %s", strings.Join(errorMessages, "; "))
 }
 
 // createEmailNotifier creates an EmailNotifier from service configuration
@@ -913,24 +1354,10 @@ func (nn *NotifyNotifier) GetEnabledChannels() []string {
 	for channel := range nn.enabledServices {
 		channels = append(channels, channel)
 	}
+	sort.Strings(channels)
 	return channels
 }
 
-// formatMessage formats messages considering different service characteristics
-func (nn *NotifyNotifier) formatMessage(msgType string, data map[string]interface{}) string {
-	switch msgType {
-	case "log_summary":
-		return fmt.Sprintf("🚨 *Log Summary Notification*\n\n📁 *File:* %s\n⏰ *Time:* %s\n\n📋 *Summary:*\n%s",
-			data["filePath"], data["time"], data["summary"])
-	case "error":
-		return fmt.Sprintf("🚨 *Critical Error Alert*\n\n📁 *File:* %s\n⏰ *Time:* %s\n\n💥 *Error Details:*\n%s",
-			data["filePath"], data["time"], data["errorMsg"])
-	default:
-		return fmt.Sprintf("📢 *Notification*\n\n⏰ *Time:* %s\n\n📝 *Message:*\n%s",
-			data["time"], data["message"])
-	}
-}
-
 // convertMarkdownToTelegramHTML converts common Markdown formats to Telegram HTML
 func (nn *NotifyNotifier) convertMarkdownToTelegramHTML(text string) string {
 	// First escape HTML special characters to prevent conflicts
@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+// ntfyPriorities maps ntfy priority names to their numeric values
+var ntfyPriorities = map[string]int{
+	"min":     1,
+	"low":     2,
+	"default": 3,
+	"high":    4,
+	"urgent":  5,
+	"max":     5,
+}
+
+// NtfyService publishes messages to an ntfy topic
+type NtfyService struct {
+	server   string
+	topic    string
+	priority int // 0 derives the priority from the message severity
+	tags     []string
+	click    string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewNtfyService creates an ntfy service from provider configuration
+func NewNtfyService(serviceConfig config.ServiceConfig) (*NtfyService, error) {
+	topic := configString(serviceConfig.Config, "topic")
+	if topic == "" {
+		return nil, fmt.Errorf("ntfy topic is required")
+	}
+
+	server := strings.TrimRight(configString(serviceConfig.Config, "server"), "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+
+	priority := 0
+	if value := configString(serviceConfig.Config, "priority"); value != "" {
+		parsed, err := parseNtfyPriority(value)
+		if err != nil {
+			return nil, err
+		}
+		priority = parsed
+	} else if value, ok, err := configInt(serviceConfig.Config, "priority"); err != nil {
+		return nil, err
+	} else if ok {
+		parsed, err := parseNtfyPriority(strconv.Itoa(value))
+		if err != nil {
+			return nil, err
+		}
+		priority = parsed
+	}
+
+	headers := make(map[string]string)
+	if token := configString(serviceConfig.Config, "token"); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	} else if username := configString(serviceConfig.Config, "username"); username != "" {
+		credentials := username + ":" + configString(serviceConfig.Config, "password")
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	return &NtfyService{
+		server:   server,
+		topic:    topic,
+		priority: priority,
+		tags:     configStringList(serviceConfig.Config, "tags"),
+		click:    configString(serviceConfig.Config, "click"),
+		headers:  headers,
+		client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage publishes the message with a priority and tags derived from its severity
+func (n *NtfyService) SendMessage(ctx context.Context, msg *Message) error {
+	priority := n.priority
+	if priority == 0 {
+		priority = ntfySeverityPriority(msg.Severity)
+	}
+
+	tags := append([]string{ntfySeverityTag(msg.Severity)}, n.tags...)
+
+	body := msg.Body
+	if msg.Source != "" {
+		body = fmt.Sprintf("Source: %s\n\n%s", msg.Source, msg.Body)
+	}
+
+	payload := map[string]interface{}{
+		"topic":    n.topic,
+		"title":    msg.DisplayTitle(),
+		"message":  body,
+		"priority": priority,
+		"tags":     tags,
+	}
+	if n.click != "" {
+		payload["click"] = n.click
+	}
+
+	// Publishing JSON to the server root lets the topic be set in the body
+	if err := postJSON(ctx, n.client, n.server, payload, n.headers); err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	return nil
+}
+
+// parseNtfyPriority accepts a priority name (min..urgent) or number (1..5)
+func parseNtfyPriority(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if priority, ok := ntfyPriorities[value]; ok {
+		return priority, nil
+	}
+	if priority, err := strconv.Atoi(value); err == nil && priority >= 1 && priority <= 5 {
+		return priority, nil
+	}
+	return 0, fmt.Errorf("invalid ntfy priority %q: use 1-5 or min, low, default, high, urgent", value)
+}
+
+// ntfySeverityPriority maps a message severity to an ntfy priority
+func ntfySeverityPriority(severity string) int {
+	switch severity {
+	case SeverityError:
+		return ntfyPriorities["high"]
+	case SeverityWarning:
+		return ntfyPriorities["default"]
+	default:
+		return ntfyPriorities["low"]
+	}
+}
+
+// ntfySeverityTag returns the emoji tag shown for a severity
+func ntfySeverityTag(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "rotating_light"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "information_source"
+	}
+}
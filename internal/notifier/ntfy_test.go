@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtfyPublish(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	ntfy, err := NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{
+		"server": server.URL + "/",
+		"topic":  "lai-alerts",
+		"token":  "tk_abc",
+		"tags":   "server,prod",
+	}})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "OOM killer invoked", SeverityError)
+	require.NoError(t, ntfy.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "Bearer tk_abc", captured.Header.Get("Authorization"))
+	assert.Equal(t, "lai-alerts", captured.Body["topic"])
+	assert.Equal(t, "🚨 Log Summary Notification", captured.Body["title"])
+	assert.Equal(t, "Source: /var/log/app.log\n\nOOM killer invoked", captured.Body["message"])
+	assert.Equal(t, float64(4), captured.Body["priority"])
+	assert.Equal(t, []interface{}{"rotating_light", "server", "prod"}, captured.Body["tags"])
+}
+
+func TestNtfyPriorityOverride(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	ntfy, err := NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{
+		"server":   server.URL,
+		"topic":    "lai",
+		"priority": "urgent",
+		"username": "lai",
+		"password": "secret",
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, ntfy.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hello", SeverityInfo)))
+	assert.Equal(t, float64(5), captured.Body["priority"])
+	assert.Equal(t, "Basic bGFpOnNlY3JldA==", captured.Header.Get("Authorization"))
+}
+
+func TestNewNtfyServiceValidation(t *testing.T) {
+	ntfy, err := NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{"topic": "lai", "priority": 2}})
+	require.NoError(t, err)
+	assert.Equal(t, defaultNtfyServer, ntfy.server)
+	assert.Equal(t, 2, ntfy.priority)
+
+	_, err = NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{}})
+	assert.Error(t, err, "topic is required")
+
+	_, err = NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{"topic": "lai", "priority": "loud"}})
+	assert.Error(t, err)
+
+	_, err = NewNtfyService(config.ServiceConfig{Config: map[string]interface{}{"topic": "lai", "priority": 9}})
+	assert.Error(t, err)
+}
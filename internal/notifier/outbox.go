@@ -0,0 +1,339 @@
+package notifier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	outboxEntryExt   = ".json"
+	outboxClaimedExt = ".sending"
+
+	// Retry backoff doubles from outboxBaseBackoff up to outboxMaxBackoff
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+
+	// Claims older than this are assumed to belong to a process that died mid-send
+	outboxStaleClaim = 10 * time.Minute
+)
+
+// OutboxEntry is an undelivered notification waiting to be retried
+type OutboxEntry struct {
+	ID          string    `json:"id"`
+	Provider    string    `json:"provider"`
+	Message     *Message  `json:"message"`
+	Attempts    int       `json:"attempts"`
+	CreatedAt   time.Time `json:"created_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Outbox is a disk-backed queue of undelivered notifications. Each entry is a
+// JSON file so the queue survives restarts and can be shared by several
+// monitor processes; an entry is claimed by renaming it before sending.
+type Outbox struct {
+	dir string
+	now func() time.Time
+}
+
+// processOutbox is the outbox used by the notifiers CreateNotifiers creates
+// once UseOutbox was called, and the open notifiers its retry loop delivers
+// queued entries through
+var processOutbox struct {
+	mu        sync.Mutex
+	outbox    *Outbox
+	notifiers map[*NotifyNotifier]bool
+}
+
+// UseOutbox makes the notifiers created by CreateNotifiers from now on store
+// undelivered notifications in the outbox in dir. A single loop per process
+// retries them every OutboxRetryInterval through the notifiers still open.
+// Later calls keep the outbox of the first one.
+func UseOutbox(dir string) error {
+	processOutbox.mu.Lock()
+	defer processOutbox.mu.Unlock()
+
+	if processOutbox.outbox != nil {
+		return nil
+	}
+	outbox, err := NewOutbox(dir)
+	if err != nil {
+		return err
+	}
+	processOutbox.outbox = outbox
+	processOutbox.notifiers = make(map[*NotifyNotifier]bool)
+	go retryProcessOutbox(OutboxRetryInterval)
+	return nil
+}
+
+// attachProcessOutbox gives a notifier the process outbox, if there is one,
+// and has the retry loop deliver through it until it is closed
+func attachProcessOutbox(nn *NotifyNotifier) {
+	processOutbox.mu.Lock()
+	defer processOutbox.mu.Unlock()
+
+	if processOutbox.outbox == nil {
+		return
+	}
+	nn.SetOutbox(processOutbox.outbox)
+	processOutbox.notifiers[nn] = true
+}
+
+// detachProcessOutbox stops retrying queued entries through a closed notifier
+func detachProcessOutbox(nn *NotifyNotifier) {
+	processOutbox.mu.Lock()
+	defer processOutbox.mu.Unlock()
+	delete(processOutbox.notifiers, nn)
+}
+
+// retryProcessOutbox retries due entries of the process outbox every interval
+func retryProcessOutbox(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		processOutbox.mu.Lock()
+		notifiers := make([]*NotifyNotifier, 0, len(processOutbox.notifiers))
+		for nn := range processOutbox.notifiers {
+			notifiers = append(notifiers, nn)
+		}
+		processOutbox.mu.Unlock()
+
+		for _, nn := range notifiers {
+			nn.RetryOutbox(context.Background(), false, nil)
+		}
+	}
+}
+
+// DefaultOutboxDir returns ~/.lai/outbox
+func DefaultOutboxDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".lai", "outbox"), nil
+}
+
+// NewOutbox opens (creating if needed) an outbox in the given directory
+func NewOutbox(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &Outbox{dir: dir, now: time.Now}, nil
+}
+
+// Dir returns the outbox directory
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+// Add stores a notification that could not be delivered to a provider
+func (o *Outbox) Add(provider string, msg *Message, deliveryErr error) (*OutboxEntry, error) {
+	now := o.now()
+	entry := &OutboxEntry{
+		ID:          newOutboxID(now),
+		Provider:    provider,
+		Message:     msg,
+		Attempts:    1,
+		CreatedAt:   now,
+		NextAttempt: now.Add(outboxBackoff(1)),
+	}
+	if deliveryErr != nil {
+		entry.LastError = deliveryErr.Error()
+	}
+
+	if err := o.write(entry, o.entryPath(entry.ID)); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns all entries, including ones currently being sent, oldest first
+func (o *Outbox) List() ([]*OutboxEntry, error) {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+
+	var entries []*OutboxEntry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || (!strings.HasSuffix(name, outboxEntryExt) && !strings.HasSuffix(name, outboxClaimedExt)) {
+			continue
+		}
+
+		entry, err := o.read(filepath.Join(o.dir, name))
+		if err != nil {
+			continue // Skip unreadable entries, they may be mid-write
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// Claim takes exclusive ownership of an entry that is due for retry (or any
+// entry when force is set). It returns false if the entry is not due or was
+// claimed by another process.
+func (o *Outbox) Claim(id string, force bool) (*OutboxEntry, bool) {
+	path := o.entryPath(id)
+	claimedPath := o.claimedPath(id)
+
+	// Reclaim entries left behind by a process that died while sending
+	if info, err := os.Stat(claimedPath); err == nil && o.now().Sub(info.ModTime()) > outboxStaleClaim {
+		if err := os.Rename(claimedPath, path); err != nil {
+			return nil, false
+		}
+	}
+
+	entry, err := o.read(path)
+	if err != nil {
+		return nil, false
+	}
+	if !force && o.now().Before(entry.NextAttempt) {
+		return nil, false
+	}
+
+	if err := os.Rename(path, claimedPath); err != nil {
+		return nil, false
+	}
+	// A rename keeps the time of the last write, which would make a claim of
+	// an entry written long ago look stale at once
+	now := o.now()
+	if err := os.Chtimes(claimedPath, now, now); err != nil {
+		os.Rename(claimedPath, path)
+		return nil, false
+	}
+	return entry, true
+}
+
+// Complete removes a claimed entry after successful delivery
+func (o *Outbox) Complete(entry *OutboxEntry) error {
+	if err := os.Remove(o.claimedPath(entry.ID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Release returns a claimed entry to the queue after a failed attempt,
+// scheduling the next attempt with exponential backoff
+func (o *Outbox) Release(entry *OutboxEntry, deliveryErr error) error {
+	entry.Attempts++
+	entry.NextAttempt = o.now().Add(outboxBackoff(entry.Attempts))
+	if deliveryErr != nil {
+		entry.LastError = deliveryErr.Error()
+	}
+
+	if err := o.write(entry, o.entryPath(entry.ID)); err != nil {
+		return err
+	}
+	if err := os.Remove(o.claimedPath(entry.ID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes an entry regardless of its state
+func (o *Outbox) Remove(id string) error {
+	removed := false
+	for _, path := range []string{o.entryPath(id), o.claimedPath(id)} {
+		if err := os.Remove(path); err == nil {
+			removed = true
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove outbox entry: %w", err)
+		}
+	}
+	if !removed {
+		return fmt.Errorf("outbox entry not found: %s", id)
+	}
+	return nil
+}
+
+// Purge deletes all entries and returns how many were removed
+func (o *Outbox) Purge() (int, error) {
+	entries, err := o.List()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, entry := range entries {
+		if err := o.Remove(entry.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (o *Outbox) entryPath(id string) string {
+	return filepath.Join(o.dir, id+outboxEntryExt)
+}
+
+func (o *Outbox) claimedPath(id string) string {
+	return filepath.Join(o.dir, id+outboxClaimedExt)
+}
+
+// write stores an entry atomically via a temporary file
+func (o *Outbox) write(entry *OutboxEntry, path string) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) read(path string) (*OutboxEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry OutboxEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse outbox entry %s: %w", filepath.Base(path), err)
+	}
+	return &entry, nil
+}
+
+// outboxBackoff returns the delay before the given attempt number is retried
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	return backoff
+}
+
+// newOutboxID returns a sortable unique entry ID
+func newOutboxID(now time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%d", now.UnixNano())
+	}
+	return fmt.Sprintf("%s-%s", now.UTC().Format("20060102T150405.000"), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxLifecycle(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "disk full", SeverityError)
+	entry, err := outbox.Add("telegram", msg, errors.New("connection reset"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.Equal(t, "connection reset", entry.LastError)
+
+	entries, err := outbox.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "telegram", entries[0].Provider)
+	assert.Equal(t, "disk full", entries[0].Message.Body)
+
+	// Not due yet
+	_, claimed := outbox.Claim(entry.ID, false)
+	assert.False(t, claimed)
+
+	// Due after the backoff; a second claim fails while the first holds it
+	now = now.Add(outboxBackoff(1))
+	claimedEntry, claimed := outbox.Claim(entry.ID, false)
+	require.True(t, claimed)
+	_, claimedAgain := outbox.Claim(entry.ID, true)
+	assert.False(t, claimedAgain)
+
+	// A failed retry reschedules with a longer backoff
+	require.NoError(t, outbox.Release(claimedEntry, errors.New("still down")))
+	entries, err = outbox.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Attempts)
+	assert.Equal(t, now.Add(outboxBackoff(2)), entries[0].NextAttempt)
+	assert.Equal(t, "still down", entries[0].LastError)
+
+	// Forced claim and completion removes the entry
+	claimedEntry, claimed = outbox.Claim(entry.ID, true)
+	require.True(t, claimed)
+	require.NoError(t, outbox.Complete(claimedEntry))
+
+	entries, err = outbox.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestOutboxReclaimsStaleClaims(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+
+	entry, err := outbox.Add("email", NewMessage(MessageTypeMessage, "", "hello", SeverityInfo), nil)
+	require.NoError(t, err)
+
+	_, claimed := outbox.Claim(entry.ID, true)
+	require.True(t, claimed)
+
+	// Pretend the claiming process died a while ago
+	outbox.now = func() time.Time { return time.Now().Add(outboxStaleClaim + time.Minute) }
+	_, claimed = outbox.Claim(entry.ID, true)
+	assert.True(t, claimed)
+}
+
+func TestOutboxClaimOfOldEntryIsNotStale(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+
+	entry, err := outbox.Add("email", NewMessage(MessageTypeMessage, "", "hello", SeverityInfo), nil)
+	require.NoError(t, err)
+
+	// Written before a long backoff
+	written := time.Now().Add(-outboxStaleClaim - time.Minute)
+	require.NoError(t, os.Chtimes(outbox.entryPath(entry.ID), written, written))
+
+	_, claimed := outbox.Claim(entry.ID, true)
+	require.True(t, claimed)
+	_, claimedAgain := outbox.Claim(entry.ID, true)
+	assert.False(t, claimedAgain, "a fresh claim must not be taken for a stale one")
+}
+
+func TestOutboxPurgeAndRemove(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir())
+	require.NoError(t, err)
+
+	first, err := outbox.Add("telegram", NewMessage(MessageTypeMessage, "", "one", SeverityInfo), nil)
+	require.NoError(t, err)
+	_, err = outbox.Add("telegram", NewMessage(MessageTypeMessage, "", "two", SeverityInfo), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, outbox.Remove(first.ID))
+	assert.Error(t, outbox.Remove(first.ID))
+
+	purged, err := outbox.Purge()
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, outboxBackoff(1))
+	assert.Equal(t, time.Minute, outboxBackoff(2))
+	assert.Equal(t, 2*time.Minute, outboxBackoff(3))
+	assert.Equal(t, time.Hour, outboxBackoff(20))
+}
@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDigestInterval is used when a provider sets max_per_minute without digest_interval
+const defaultDigestInterval = time.Minute
+
+// maxDigestItemLength caps how much of each held-back message goes into a digest
+const maxDigestItemLength = 500
+
+// RateLimiter is a per-provider token bucket. Messages that exceed the limit
+// are queued and delivered together as a single digest message.
+type RateLimiter struct {
+	maxPerMinute   int
+	digestInterval time.Duration
+	onDigest       func(digest *Message)
+	now            func() time.Time
+
+	mutex      sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	pending    []*Message
+	timer      *time.Timer
+}
+
+// NewRateLimiter creates a limiter allowing maxPerMinute messages per minute
+// (with bursts of up to maxPerMinute). Overflow is passed to onDigest as one
+// message every digestInterval.
+func NewRateLimiter(maxPerMinute int, digestInterval time.Duration, onDigest func(digest *Message)) *RateLimiter {
+	if digestInterval <= 0 {
+		digestInterval = defaultDigestInterval
+	}
+	return &RateLimiter{
+		maxPerMinute:   maxPerMinute,
+		digestInterval: digestInterval,
+		onDigest:       onDigest,
+		now:            time.Now,
+		tokens:         float64(maxPerMinute),
+	}
+}
+
+// Allow takes a token and reports whether the message may be sent now.
+// When no token is available the message is queued for the next digest.
+func (l *RateLimiter) Allow(msg *Message) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.takeTokenLocked() {
+		return true
+	}
+
+	l.pending = append(l.pending, msg)
+	if l.timer == nil {
+		l.timer = time.AfterFunc(l.digestInterval, l.Flush)
+	}
+	return false
+}
+
+// Pending returns the number of messages waiting for the next digest
+func (l *RateLimiter) Pending() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.pending)
+}
+
+// Flush delivers queued messages as a digest immediately
+func (l *RateLimiter) Flush() {
+	l.mutex.Lock()
+	pending := l.pending
+	l.pending = nil
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if len(pending) > 0 {
+		// The digest itself counts against the limit when a token is available
+		l.takeTokenLocked()
+	}
+	l.mutex.Unlock()
+
+	if len(pending) > 0 && l.onDigest != nil {
+		l.onDigest(newDigestMessage(pending, "📦 Notification Digest", "were held back by the rate limit"))
+	}
+}
+
+// takeTokenLocked refills the bucket and consumes a token if one is available
+func (l *RateLimiter) takeTokenLocked() bool {
+	now := l.now()
+	if !l.lastRefill.IsZero() {
+		elapsed := now.Sub(l.lastRefill)
+		l.tokens += elapsed.Minutes() * float64(l.maxPerMinute)
+		if l.tokens > float64(l.maxPerMinute) {
+			l.tokens = float64(l.maxPerMinute)
+		}
+	}
+	l.lastRefill = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// newDigestMessage folds held-back messages into a single notification. The
+// reason completes the sentence "N notification(s) ...".
+func newDigestMessage(messages []*Message, title, reason string) *Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d notification(s) %s:\n", len(messages), reason)
+
+	source := messages[0].Source
+	severity := ""
+	for _, msg := range messages {
+		if msg.Source != source {
+			source = ""
+		}
+		if severityRank(msg.Severity) > severityRank(severity) {
+			severity = msg.Severity
+		}
+
+		fmt.Fprintf(&body, "\n• [%s] %s", msg.formattedTime(), strings.TrimSpace(msg.DisplayTitle()))
+		if msg.Severity != "" {
+			fmt.Fprintf(&body, " (%s)", msg.Severity)
+		}
+		if msg.Source != "" {
+			fmt.Fprintf(&body, " — %s", msg.Source)
+		}
+		body.WriteString("\n")
+		body.WriteString(truncateText(strings.TrimSpace(msg.Body), maxDigestItemLength))
+		body.WriteString("\n")
+	}
+
+	digest := NewMessage(MessageTypeMessage, source, strings.TrimRight(body.String(), "\n"), severity)
+	digest.Title = title
+	return digest
+}
+
+// severityRank orders severities so a digest carries the most severe one
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// truncateText shortens text to at most limit runes, marking the cut
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterFoldsOverflowIntoDigest(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var digests []*Message
+
+	l := NewRateLimiter(2, time.Hour, func(digest *Message) { digests = append(digests, digest) })
+	l.now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		assert.True(t, l.Allow(&Message{Body: fmt.Sprintf("msg %d", i)}), "message %d should be within the burst", i)
+	}
+	assert.False(t, l.Allow(&Message{Body: "msg 3", Severity: "warning", Source: "/var/log/a.log"}))
+	assert.False(t, l.Allow(&Message{Body: "msg 4", Severity: "error", Source: "/var/log/a.log"}))
+	assert.Equal(t, 2, l.Pending())
+
+	l.Flush()
+	if assert.Len(t, digests, 1) {
+		digest := digests[0]
+		assert.Equal(t, "📦 Notification Digest", digest.Title)
+		assert.Equal(t, "error", digest.Severity, "digest should carry the highest severity")
+		assert.Equal(t, "/var/log/a.log", digest.Source)
+		assert.True(t, strings.HasPrefix(digest.Body, "2 notification(s) were held back"), digest.Body)
+		assert.Contains(t, digest.Body, "msg 3")
+		assert.Contains(t, digest.Body, "msg 4")
+	}
+	assert.Equal(t, 0, l.Pending())
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(6, time.Minute, nil)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 6; i++ {
+		assert.True(t, l.Allow(&Message{}))
+	}
+	assert.False(t, l.Allow(&Message{}))
+
+	// 6 per minute refills one token every 10 seconds
+	now = now.Add(10 * time.Second)
+	assert.True(t, l.Allow(&Message{}))
+	assert.False(t, l.Allow(&Message{}))
+	l.Flush()
+}
+
+func TestRateLimiterDigestTimer(t *testing.T) {
+	digestCh := make(chan *Message, 1)
+	l := NewRateLimiter(1, 50*time.Millisecond, func(digest *Message) { digestCh <- digest })
+
+	assert.True(t, l.Allow(&Message{Body: "first"}))
+	assert.False(t, l.Allow(&Message{Body: "second"}))
+
+	select {
+	case digest := <-digestCh:
+		assert.Contains(t, digest.Body, "second")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected digest after the digest interval")
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "abc…", truncateText("abcdef", 3))
+}
@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// RocketChatService posts message attachments to a Rocket.Chat incoming webhook
+type RocketChatService struct {
+	webhookURL string
+	channel    string
+	alias      string
+	avatar     string
+	client     *http.Client
+}
+
+// NewRocketChatService creates a Rocket.Chat service from provider configuration
+func NewRocketChatService(serviceConfig config.ServiceConfig) (*RocketChatService, error) {
+	webhookURL := configString(serviceConfig.Config, "webhook_url")
+	if webhookURL == "" {
+		return nil, fmt.Errorf("rocketchat webhook_url is required")
+	}
+
+	alias := configString(serviceConfig.Config, "alias")
+	if alias == "" {
+		alias = "Lai Bot"
+	}
+
+	return &RocketChatService{
+		webhookURL: webhookURL,
+		channel:    configString(serviceConfig.Config, "channel"),
+		alias:      alias,
+		avatar:     configString(serviceConfig.Config, "avatar"),
+		client:     &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage posts the message as an attachment
+func (r *RocketChatService) SendMessage(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"alias":       r.alias,
+		"text":        msg.DisplayTitle(),
+		"attachments": []chatAttachment{newChatAttachment(msg)},
+	}
+	if r.channel != "" {
+		payload["channel"] = r.channel
+	}
+	if r.avatar != "" {
+		payload["avatar"] = r.avatar
+	}
+
+	if err := postJSON(ctx, r.client, r.webhookURL, payload, nil); err != nil {
+		return fmt.Errorf("rocketchat: %w", err)
+	}
+	return nil
+}
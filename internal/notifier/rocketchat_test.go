@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRocketChatAttachment(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	rocketChat, err := NewRocketChatService(config.ServiceConfig{Config: map[string]interface{}{
+		"webhook_url": server.URL,
+		"alias":       "Log Watcher",
+	}})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeError, "api", "Panic in handler", SeverityError)
+	require.NoError(t, rocketChat.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "Log Watcher", captured.Body["alias"])
+	assert.Equal(t, "🚨 Critical Error Alert", captured.Body["text"])
+	assert.NotContains(t, captured.Body, "channel")
+
+	attachment := captured.Body["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, severityColor(SeverityError), attachment["color"])
+	assert.Equal(t, "Panic in handler", attachment["text"])
+}
+
+func TestRocketChatRequiresWebhookURL(t *testing.T) {
+	_, err := NewRocketChatService(config.ServiceConfig{Config: map[string]interface{}{}})
+	assert.Error(t, err)
+}
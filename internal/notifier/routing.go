@@ -0,0 +1,266 @@
+package notifier
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// Router picks the providers a message is delivered to based on routing rules
+type Router struct {
+	rules []routeRule
+	now   func() time.Time
+}
+
+// routeRule is a parsed and validated config.RoutingRule
+type routeRule struct {
+	name       string
+	severities map[string]bool
+	types      map[MessageType]bool
+	sources    []string
+	providers  []string
+	window     *timeWindow
+}
+
+// timeWindow restricts a rule to certain hours and weekdays
+type timeWindow struct {
+	start    int // minutes since midnight
+	end      int // minutes since midnight; may be less than start to wrap midnight
+	hasHours bool
+	days     map[time.Weekday]bool
+	location *time.Location
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// NewRouter validates the routing rules and returns a router
+func NewRouter(rules []config.RoutingRule) (*Router, error) {
+	router := &Router{now: time.Now}
+
+	for i, rule := range rules {
+		label := rule.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+
+		if len(rule.Providers) == 0 {
+			return nil, fmt.Errorf("routing rule %s: at least one provider is required", label)
+		}
+
+		parsed := routeRule{
+			name:      label,
+			sources:   rule.Sources,
+			providers: rule.Providers,
+		}
+
+		if len(rule.Severity) > 0 {
+			parsed.severities = make(map[string]bool)
+			for _, severity := range rule.Severity {
+				severity = strings.ToLower(strings.TrimSpace(severity))
+				switch severity {
+				case SeverityError, SeverityWarning, SeverityInfo:
+					parsed.severities[severity] = true
+				default:
+					return nil, fmt.Errorf("routing rule %s: unknown severity %q (expected error, warning or info)", label, severity)
+				}
+			}
+		}
+
+		if len(rule.Types) > 0 {
+			parsed.types = make(map[MessageType]bool)
+			for _, rawType := range rule.Types {
+				msgType := MessageType(strings.ToLower(strings.TrimSpace(rawType)))
+				if !validMessageType(msgType) {
+					return nil, fmt.Errorf("routing rule %s: unknown type %q (expected summary, error, final_summary, lifecycle or message)", label, rawType)
+				}
+				parsed.types[msgType] = true
+			}
+		}
+
+		for _, pattern := range rule.Sources {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("routing rule %s: invalid source pattern %q: %w", label, pattern, err)
+			}
+		}
+
+		window, err := parseTimeWindow(rule.Hours, rule.Days, rule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("routing rule %s: %w", label, err)
+		}
+		parsed.window = window
+
+		router.rules = append(router.rules, parsed)
+	}
+
+	return router, nil
+}
+
+// Route returns the providers the message should be delivered to.
+// Only providers listed in available are returned. A rule whose providers are
+// all unavailable is skipped, and when no rule matches every available
+// provider is used.
+func (r *Router) Route(msg *Message, available []string) []string {
+	if r == nil || len(r.rules) == 0 {
+		return available
+	}
+
+	availableSet := make(map[string]bool, len(available))
+	for _, name := range available {
+		availableSet[name] = true
+	}
+
+	now := r.now()
+	for _, rule := range r.rules {
+		if !rule.matches(msg, now) {
+			continue
+		}
+
+		var targets []string
+		for _, provider := range rule.providers {
+			if availableSet[provider] {
+				targets = append(targets, provider)
+			}
+		}
+		if len(targets) > 0 {
+			return targets
+		}
+	}
+
+	return available
+}
+
+// matches reports whether the rule applies to the message at the given time
+func (rule routeRule) matches(msg *Message, now time.Time) bool {
+	if rule.severities != nil && !rule.severities[strings.ToLower(msg.Severity)] {
+		return false
+	}
+	if rule.types != nil && !rule.types[msg.Type] {
+		return false
+	}
+
+	if len(rule.sources) > 0 {
+		matched := false
+		for _, pattern := range rule.sources {
+			if matchSource(pattern, msg.Source) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return rule.window.contains(now)
+}
+
+// matchSource matches a glob pattern against the full source or its base name
+func matchSource(pattern, source string) bool {
+	if ok, _ := filepath.Match(pattern, source); ok {
+		return true
+	}
+	ok, _ := filepath.Match(pattern, filepath.Base(source))
+	return ok
+}
+
+// parseTimeWindow parses hour ranges like "09:00-18:00", weekday names and a timezone.
+// It returns nil when no restriction is configured.
+func parseTimeWindow(hours string, days []string, timezone string) (*timeWindow, error) {
+	if hours == "" && len(days) == 0 {
+		return nil, nil
+	}
+
+	window := &timeWindow{location: time.Local}
+
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		window.location = location
+	}
+
+	if hours != "" {
+		parts := strings.Split(hours, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid hours %q (expected HH:MM-HH:MM)", hours)
+		}
+		start, err := parseClock(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hours %q: %w", hours, err)
+		}
+		end, err := parseClock(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hours %q: %w", hours, err)
+		}
+		window.start, window.end, window.hasHours = start, end, true
+	}
+
+	if len(days) > 0 {
+		window.days = make(map[time.Weekday]bool)
+		for _, day := range days {
+			weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(day))]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q", day)
+			}
+			window.days[weekday] = true
+		}
+	}
+
+	return window, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether the given time falls inside the window.
+// A nil window always matches.
+func (w *timeWindow) contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	local := t.In(w.location)
+	minutes := local.Hour()*60 + local.Minute()
+	weekday := local.Weekday()
+
+	if w.hasHours {
+		if w.start <= w.end {
+			if minutes < w.start || minutes >= w.end {
+				return false
+			}
+		} else {
+			// Window wraps midnight, e.g. 22:00-06:00; the early-morning part
+			// belongs to the previous day for weekday matching
+			if minutes < w.start && minutes >= w.end {
+				return false
+			}
+			if minutes < w.end {
+				weekday = (weekday + 6) % 7
+			}
+		}
+	}
+
+	if w.days != nil && !w.days[weekday] {
+		return false
+	}
+
+	return true
+}
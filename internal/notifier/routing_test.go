@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterRoute(t *testing.T) {
+	router, err := NewRouter([]config.RoutingRule{
+		{Name: "payments", Sources: []string{"*payments*"}, Providers: []string{"pagerduty"}},
+		{Name: "errors", Severity: []string{"error"}, Providers: []string{"pagerduty", "telegram"}},
+		{Name: "warnings", Severity: []string{"warning"}, Providers: []string{"slack"}},
+		{Name: "info", Severity: []string{"info"}, Providers: []string{"email"}},
+	})
+	require.NoError(t, err)
+
+	available := []string{"email", "pagerduty", "slack", "telegram"}
+
+	tests := []struct {
+		name     string
+		msg      *Message
+		expected []string
+	}{
+		{"Error goes to pager and telegram", &Message{Severity: "error", Source: "/var/log/app.log"}, []string{"pagerduty", "telegram"}},
+		{"Warning goes to slack", &Message{Severity: "warning", Source: "/var/log/app.log"}, []string{"slack"}},
+		{"Info goes to email", &Message{Severity: "info", Source: "/var/log/app.log"}, []string{"email"}},
+		{"Source rule wins over severity", &Message{Severity: "info", Source: "/srv/payments-api.log"}, []string{"pagerduty"}},
+		{"Unmatched goes everywhere", &Message{Severity: ""}, available},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, router.Route(tt.msg, available))
+		})
+	}
+}
+
+func TestRouterSkipsUnavailableProviders(t *testing.T) {
+	router, err := NewRouter([]config.RoutingRule{
+		{Severity: []string{"error"}, Providers: []string{"pagerduty"}},
+		{Severity: []string{"error"}, Providers: []string{"telegram", "slack"}},
+	})
+	require.NoError(t, err)
+
+	// The monitor only uses telegram and email, so the first rule cannot apply
+	got := router.Route(&Message{Severity: "error"}, []string{"email", "telegram"})
+	assert.Equal(t, []string{"telegram"}, got)
+}
+
+func TestRouterMessageTypes(t *testing.T) {
+	router, err := NewRouter([]config.RoutingRule{
+		{Types: []string{"lifecycle", "final_summary"}, Providers: []string{"slack"}},
+		{Types: []string{"error"}, Providers: []string{"pagerduty"}},
+	})
+	require.NoError(t, err)
+
+	available := []string{"pagerduty", "slack", "telegram"}
+	assert.Equal(t, []string{"slack"}, router.Route(&Message{Type: MessageTypeLifecycle}, available))
+	assert.Equal(t, []string{"slack"}, router.Route(&Message{Type: MessageTypeFinalSummary}, available))
+	assert.Equal(t, []string{"pagerduty"}, router.Route(&Message{Type: MessageTypeError}, available))
+	assert.Equal(t, available, router.Route(&Message{Type: MessageTypeSummary}, available))
+}
+
+func TestRouterTimeWindow(t *testing.T) {
+	router, err := NewRouter([]config.RoutingRule{
+		{Name: "night", Hours: "22:00-06:00", Timezone: "UTC", Providers: []string{"email"}},
+		{Name: "weekend", Days: []string{"sat", "sun"}, Timezone: "UTC", Providers: []string{"slack"}},
+	})
+	require.NoError(t, err)
+
+	available := []string{"email", "slack", "telegram"}
+	msg := &Message{Severity: "error"}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected []string
+	}{
+		{"Weekday daytime", time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), available},
+		{"Weekday late night", time.Date(2024, 1, 3, 23, 30, 0, 0, time.UTC), []string{"email"}},
+		{"Weekday early morning", time.Date(2024, 1, 4, 5, 0, 0, 0, time.UTC), []string{"email"}},
+		{"Saturday daytime", time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), []string{"slack"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router.now = func() time.Time { return tt.now }
+			assert.Equal(t, tt.expected, router.Route(msg, available))
+		})
+	}
+}
+
+func TestNewRouterValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.RoutingRule
+	}{
+		{"Missing providers", config.RoutingRule{Severity: []string{"error"}}},
+		{"Unknown severity", config.RoutingRule{Severity: []string{"fatal"}, Providers: []string{"email"}}},
+		{"Bad hours", config.RoutingRule{Hours: "9-17", Providers: []string{"email"}}},
+		{"Bad day", config.RoutingRule{Days: []string{"someday"}, Providers: []string{"email"}}},
+		{"Bad timezone", config.RoutingRule{Hours: "09:00-17:00", Timezone: "Mars/Base", Providers: []string{"email"}}},
+		{"Bad pattern", config.RoutingRule{Sources: []string{"["}, Providers: []string{"email"}}},
+		{"Unknown type", config.RoutingRule{Types: []string{"heartbeat"}, Providers: []string{"email"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRouter([]config.RoutingRule{tt.rule})
+			assert.Error(t, err)
+		})
+	}
+}
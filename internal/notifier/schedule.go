@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// QuietSchedule holds back messages below a severity during a provider's
+// quiet hours and weekends. Held messages are delivered together as a single
+// digest when the quiet period ends.
+type QuietSchedule struct {
+	quietHours  *timeWindow // Daily quiet hours, nil if not set
+	quietDays   *timeWindow // Whole quiet days, nil if not set
+	location    *time.Location
+	minSeverity string
+	onDigest    func(digest *Message)
+	now         func() time.Time
+
+	mutex   sync.Mutex
+	pending []*Message
+	timer   *time.Timer
+}
+
+// weekendDays are the days quiet_weekends keeps quiet
+var weekendDays = []string{"sat", "sun"}
+
+// NewQuietSchedule creates a schedule from a provider's configuration. It
+// returns nil when no quiet time is configured.
+func NewQuietSchedule(cfg config.ScheduleConfig, onDigest func(digest *Message)) (*QuietSchedule, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	schedule := &QuietSchedule{
+		location:    time.Local,
+		minSeverity: SeverityError,
+		onDigest:    onDigest,
+		now:         time.Now,
+	}
+
+	var err error
+	if cfg.QuietHours != "" {
+		if schedule.quietHours, err = parseTimeWindow(cfg.QuietHours, nil, cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid quiet_hours: %w", err)
+		}
+		if schedule.quietHours.start == schedule.quietHours.end {
+			return nil, fmt.Errorf("invalid quiet_hours %q: start and end are the same", cfg.QuietHours)
+		}
+		schedule.location = schedule.quietHours.location
+	}
+	if cfg.QuietWeekends {
+		if schedule.quietDays, err = parseTimeWindow("", weekendDays, cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid schedule: %w", err)
+		}
+		schedule.location = schedule.quietDays.location
+	}
+
+	if cfg.MinSeverity != "" {
+		severity := strings.ToLower(cfg.MinSeverity)
+		if severityRank(severity) == 0 {
+			return nil, fmt.Errorf("invalid schedule min_severity %q (valid: info, warning, error)", cfg.MinSeverity)
+		}
+		schedule.minSeverity = severity
+	}
+
+	return schedule, nil
+}
+
+// Allow reports whether a message may be sent now. Messages below the minimum
+// severity are held during quiet time for the next digest.
+func (s *QuietSchedule) Allow(msg *Message) bool {
+	if severityRank(msg.Severity) >= severityRank(s.minSeverity) {
+		return true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if !s.isQuiet(now) {
+		return true
+	}
+
+	s.pending = append(s.pending, msg)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.quietUntil(now).Sub(now), s.Flush)
+	}
+	return false
+}
+
+// Pending returns the number of messages held for the digest
+func (s *QuietSchedule) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.pending)
+}
+
+// Flush delivers the held messages as a digest immediately
+func (s *QuietSchedule) Flush() {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mutex.Unlock()
+
+	if len(pending) > 0 && s.onDigest != nil {
+		s.onDigest(newDigestMessage(pending, "🌅 Quiet Hours Digest", "were held during quiet hours"))
+	}
+}
+
+// isQuiet reports whether t falls in quiet hours or on a quiet day
+func (s *QuietSchedule) isQuiet(t time.Time) bool {
+	return (s.quietHours != nil && s.quietHours.contains(t)) || (s.quietDays != nil && s.quietDays.contains(t))
+}
+
+// quietUntil returns when the quiet period containing t ends. It steps over
+// the boundaries where quiet time can end: the end of the daily quiet hours
+// and midnight, which ends a quiet day.
+func (s *QuietSchedule) quietUntil(t time.Time) time.Time {
+	t = t.In(s.location)
+	for i := 0; i < 8 && s.isQuiet(t); i++ {
+		next := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		if s.quietHours != nil {
+			end := time.Date(t.Year(), t.Month(), t.Day(), 0, s.quietHours.end, 0, 0, s.location)
+			if !end.After(t) {
+				end = end.AddDate(0, 0, 1)
+			}
+			if end.Before(next) {
+				next = end
+			}
+		}
+		t = next
+	}
+	return t
+}
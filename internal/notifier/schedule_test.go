@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuietScheduleValidation(t *testing.T) {
+	schedule, err := NewQuietSchedule(config.ScheduleConfig{Timezone: "UTC"}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, schedule, "a schedule without quiet time is disabled")
+
+	for _, cfg := range []config.ScheduleConfig{
+		{QuietHours: "22:00"},
+		{QuietHours: "07:00-07:00"},
+		{QuietHours: "22:00-07:00", Timezone: "Mars/Olympus"},
+		{QuietWeekends: true, MinSeverity: "critical"},
+	} {
+		_, err := NewQuietSchedule(cfg, nil)
+		assert.Error(t, err, "%+v", cfg)
+	}
+}
+
+func TestQuietScheduleHoldsMessagesDuringQuietHours(t *testing.T) {
+	var digests []*Message
+	schedule, err := NewQuietSchedule(config.ScheduleConfig{
+		Timezone:    "UTC",
+		QuietHours:  "22:00-07:00",
+		MinSeverity: "error",
+	}, func(digest *Message) { digests = append(digests, digest) })
+	require.NoError(t, err)
+
+	// Wednesday 23:30
+	now := time.Date(2024, 1, 3, 23, 30, 0, 0, time.UTC)
+	schedule.now = func() time.Time { return now }
+
+	assert.True(t, schedule.Allow(&Message{Body: "db down", Severity: SeverityError}), "errors are sent during quiet hours")
+	assert.False(t, schedule.Allow(&Message{Body: "disk at 80%", Severity: SeverityWarning}))
+	assert.False(t, schedule.Allow(&Message{Body: "all good", Severity: SeverityInfo}))
+	assert.Equal(t, 2, schedule.Pending())
+
+	schedule.Flush()
+	require.Len(t, digests, 1)
+	assert.Equal(t, "🌅 Quiet Hours Digest", digests[0].Title)
+	assert.True(t, strings.HasPrefix(digests[0].Body, "2 notification(s) were held during quiet hours"), digests[0].Body)
+	assert.Contains(t, digests[0].Body, "disk at 80%")
+
+	now = time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)
+	assert.True(t, schedule.Allow(&Message{Severity: SeverityInfo}), "messages are sent outside quiet hours")
+}
+
+func TestQuietScheduleQuietUntil(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	schedule, err := NewQuietSchedule(config.ScheduleConfig{
+		Timezone:      "America/New_York",
+		QuietHours:    "22:00-07:00",
+		QuietWeekends: true,
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"weeknight", time.Date(2024, 1, 3, 23, 0, 0, 0, location), time.Date(2024, 1, 4, 7, 0, 0, 0, location)},
+		{"early morning", time.Date(2024, 1, 4, 5, 0, 0, 0, location), time.Date(2024, 1, 4, 7, 0, 0, 0, location)},
+		{"friday night runs into the weekend", time.Date(2024, 1, 5, 23, 0, 0, 0, location), time.Date(2024, 1, 8, 7, 0, 0, 0, location)},
+		{"saturday afternoon", time.Date(2024, 1, 6, 15, 0, 0, 0, location), time.Date(2024, 1, 8, 7, 0, 0, 0, location)},
+		{"not quiet", time.Date(2024, 1, 4, 12, 0, 0, 0, location), time.Date(2024, 1, 4, 12, 0, 0, 0, location)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(schedule.quietUntil(tt.now)), "got %v", schedule.quietUntil(tt.now))
+		})
+	}
+}
+
+func TestNotifyNotifierCloseDeliversQuietHoursDigest(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"chat": {
+				Enabled:  true,
+				Provider: "webhook",
+				Config:   map[string]interface{}{"url": server.URL},
+				Schedule: config.ScheduleConfig{Timezone: "UTC", QuietHours: "22:00-07:00"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	schedule := nn.schedules["chat"]
+	require.NotNil(t, schedule)
+	schedule.now = func() time.Time { return time.Date(2024, 1, 3, 23, 30, 0, 0, time.UTC) }
+
+	require.NoError(t, nn.Send(context.Background(), NewMessage(MessageTypeSummary, "app.log", "all good", SeverityInfo)))
+	assert.Nil(t, captured.Body, "held during quiet hours")
+	assert.Equal(t, 1, schedule.Pending())
+
+	require.NoError(t, nn.Close())
+	assert.Zero(t, schedule.Pending())
+	require.NotNil(t, captured.Body, "the digest is delivered on close instead of being held again")
+	assert.Contains(t, captured.Body["title"], "Quiet Hours Digest")
+}
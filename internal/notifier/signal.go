@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// SignalService sends messages through a signal-cli REST API server
+// (https://github.com/bbernhard/signal-cli-rest-api)
+type SignalService struct {
+	apiURL     string
+	number     string
+	recipients []string
+	client     *http.Client
+}
+
+// NewSignalService creates a Signal service from provider configuration
+func NewSignalService(serviceConfig config.ServiceConfig) (*SignalService, error) {
+	apiURL := strings.TrimRight(configString(serviceConfig.Config, "api_url"), "/")
+	if apiURL == "" {
+		return nil, fmt.Errorf("signal api_url is required")
+	}
+
+	number := configString(serviceConfig.Config, "number")
+	if number == "" {
+		return nil, fmt.Errorf("signal number is required")
+	}
+
+	recipients := configStringList(serviceConfig.Config, "recipients")
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("signal recipients are required")
+	}
+
+	return &SignalService{
+		apiURL:     apiURL,
+		number:     number,
+		recipients: recipients,
+		client:     &http.Client{Timeout: defaultHTTPTimeout},
+	}, nil
+}
+
+// SendMessage sends the message as plain text to all recipients
+func (s *SignalService) SendMessage(ctx context.Context, msg *Message) error {
+	var text strings.Builder
+	text.WriteString(msg.DisplayTitle() + "\n\n")
+	for _, field := range msg.Fields() {
+		text.WriteString(fmt.Sprintf("%s: %s\n", field.Name, field.Value))
+	}
+	text.WriteString("\n" + msg.Body)
+
+	payload := map[string]interface{}{
+		"message":    text.String(),
+		"number":     s.number,
+		"recipients": s.recipients,
+	}
+
+	if err := postJSON(ctx, s.client, s.apiURL+"/v2/send", payload, nil); err != nil {
+		return fmt.Errorf("signal: %w", err)
+	}
+	return nil
+}
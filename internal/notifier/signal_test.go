@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalSendMessage(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	signal, err := NewSignalService(config.ServiceConfig{Config: map[string]interface{}{
+		"api_url":    server.URL,
+		"number":     "+15551234567",
+		"recipients": []interface{}{"+15557654321", "group.abc"},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, signal.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "Backup finished", SeverityInfo)))
+
+	assert.Equal(t, "/v2/send", path)
+	assert.Equal(t, "+15551234567", body["number"])
+	assert.Equal(t, []interface{}{"+15557654321", "group.abc"}, body["recipients"])
+	assert.Contains(t, body["message"], "📢 Lai Notification")
+	assert.Contains(t, body["message"], "Backup finished")
+}
+
+func TestNewSignalServiceValidation(t *testing.T) {
+	signal, err := NewSignalService(config.ServiceConfig{Config: map[string]interface{}{
+		"api_url": "http://localhost:8080", "number": "+1555", "recipients": "+1666, +1777",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"+1666", "+1777"}, signal.recipients)
+
+	for _, settings := range []map[string]interface{}{
+		{"number": "+1555", "recipients": "+1666"},
+		{"api_url": "http://localhost:8080", "recipients": "+1666"},
+		{"api_url": "http://localhost:8080", "number": "+1555"},
+	} {
+		_, err := NewSignalService(config.ServiceConfig{Config: settings})
+		assert.Error(t, err, "settings %v", settings)
+	}
+}
@@ -0,0 +1,266 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+const (
+	slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+	slackAPIBaseURL     = "https://slack.com/api/"
+
+	// Block Kit limits
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+	slackFieldLimit   = 10
+)
+
+var (
+	slackBoldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	slackHeadingPattern = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	slackLinkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+// SlackService posts Block Kit messages through an incoming webhook or, with an
+// OAuth token, through chat.postMessage to each configured channel
+type SlackService struct {
+	webhookURL string
+	token      string
+	channels   []string
+	apiURL     string
+	filesURL   string // Base URL of the files.* API methods
+	client     *http.Client
+}
+
+// NewSlackService creates a Slack service. The slack_webhook provider needs
+// webhook_url; the slack provider uses webhook_url when set, otherwise
+// oauth_token and channel_ids.
+func NewSlackService(serviceConfig config.ServiceConfig) (*SlackService, error) {
+	service := &SlackService{
+		webhookURL: configString(serviceConfig.Config, "webhook_url"),
+		apiURL:     slackPostMessageURL,
+		filesURL:   slackAPIBaseURL,
+		client:     &http.Client{Timeout: defaultHTTPTimeout},
+	}
+
+	if service.webhookURL != "" {
+		return service, nil
+	}
+	if serviceConfig.Provider == "slack_webhook" {
+		return nil, fmt.Errorf("slack webhook_url is required")
+	}
+
+	service.token = configString(serviceConfig.Config, "oauth_token")
+	if service.token == "" {
+		return nil, fmt.Errorf("slack oauth_token is required")
+	}
+	service.channels = configStringList(serviceConfig.Config, "channel_ids")
+	if len(service.channels) == 0 {
+		return nil, fmt.Errorf("slack channel_ids are required")
+	}
+	return service, nil
+}
+
+// SendMessage posts the message as Block Kit blocks
+func (s *SlackService) SendMessage(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"text":   fmt.Sprintf("%s: %s", msg.DisplayTitle(), truncateText(msg.Body, 200)),
+		"blocks": slackBlocks(msg),
+	}
+
+	if s.webhookURL != "" {
+		if err := postJSON(ctx, s.client, s.webhookURL, payload, nil); err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, channel := range s.channels {
+		payload["channel"] = channel
+		if err := s.postMessage(ctx, payload); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("slack: %w", combineErrors(errs))
+	}
+	return nil
+}
+
+// postMessage calls chat.postMessage, which reports failures in the response body
+func (s *SlackService) postMessage(ctx context.Context, payload map[string]interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return s.callAPI(ctx, s.apiURL, "application/json; charset=utf-8", bytes.NewReader(data), nil)
+}
+
+// UploadAttachment shares a file in the configured channels through the
+// external upload API. Incoming webhooks cannot upload files.
+func (s *SlackService) UploadAttachment(ctx context.Context, msg *Message, attachment *Attachment) error {
+	if s.webhookURL != "" {
+		return fmt.Errorf("slack: webhooks cannot upload files, use oauth_token and channel_ids")
+	}
+
+	form := url.Values{}
+	form.Set("filename", attachment.Name)
+	form.Set("length", strconv.Itoa(len(attachment.Data)))
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	if err := s.callAPI(ctx, s.filesURL+"files.getUploadURLExternal", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), &upload); err != nil {
+		return fmt.Errorf("slack: failed to get upload URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upload.UploadURL, bytes.NewReader(attachment.Data))
+	if err != nil {
+		return fmt.Errorf("slack: failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", attachment.ContentType)
+	if err := doRequest(s.client, req); err != nil {
+		return fmt.Errorf("slack: failed to upload file: %w", err)
+	}
+
+	complete, err := json.Marshal(map[string]interface{}{
+		"files":    []map[string]string{{"id": upload.FileID, "title": msg.DisplayTitle()}},
+		"channels": strings.Join(s.channels, ","),
+	})
+	if err != nil {
+		return fmt.Errorf("slack: failed to marshal payload: %w", err)
+	}
+	if err := s.callAPI(ctx, s.filesURL+"files.completeUploadExternal", "application/json; charset=utf-8", bytes.NewReader(complete), nil); err != nil {
+		return fmt.Errorf("slack: failed to share file: %w", err)
+	}
+	return nil
+}
+
+// callAPI calls a Web API method, which reports failures in the response
+// body, and decodes the response into result
+func (s *SlackService) callAPI(ctx context.Context, apiURL, contentType string, body io.Reader, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("unexpected response (status %d): %w", resp.StatusCode, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("api error: %s", status.Error)
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("unexpected response: %w", err)
+		}
+	}
+	return nil
+}
+
+// slackBlocks renders a message as a header, a fields section, the body
+// sections and a context line
+func slackBlocks(msg *Message) []map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{
+				"type":  "plain_text",
+				"text":  truncateText(severityEmoji(msg.Severity)+" "+msg.DisplayTitle(), slackHeaderLimit-1),
+				"emoji": true,
+			},
+		},
+	}
+
+	var fields []map[string]string
+	for _, field := range msg.Fields() {
+		if field.Name == "Time" || len(fields) == slackFieldLimit {
+			continue
+		}
+		fields = append(fields, map[string]string{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", field.Name, slackEscape(field.Value)),
+		})
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+
+	if strings.TrimSpace(msg.Body) != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "divider"})
+		for _, chunk := range splitText(slackMarkdown(msg.Body), slackSectionLimit) {
+			blocks = append(blocks, map[string]interface{}{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": chunk},
+			})
+		}
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type": "context",
+		"elements": []map[string]string{
+			{"type": "mrkdwn", "text": "Sent by Lai at " + msg.formattedTime()},
+		},
+	})
+	return blocks
+}
+
+// slackMarkdown converts common markdown to Slack mrkdwn
+func slackMarkdown(text string) string {
+	text = slackEscape(text)
+	text = slackBoldPattern.ReplaceAllString(text, "*$1*")
+	text = slackHeadingPattern.ReplaceAllString(text, "*$1*")
+	text = slackLinkPattern.ReplaceAllString(text, "<$2|$1>")
+	return text
+}
+
+// slackEscape escapes the characters Slack treats as control sequences
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// splitText splits text into chunks of at most limit characters, preferring line breaks
+func splitText(text string, limit int) []string {
+	var chunks []string
+	for len([]rune(text)) > limit {
+		runes := []rune(text)
+		cut := strings.LastIndex(string(runes[:limit]), "\n")
+		if cut <= 0 {
+			cut = len(string(runes[:limit]))
+		}
+		chunks = append(chunks, strings.TrimRight(text[:cut], "\n"))
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" || len(chunks) == 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
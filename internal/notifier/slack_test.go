@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackBlocks(t *testing.T) {
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "## Findings\n**3** errors in <db> layer, see [runbook](https://wiki/x)", SeverityError)
+	msg.Host = "web-01"
+	msg.LineCount = 12
+
+	blocks := slackBlocks(msg)
+	require.Len(t, blocks, 5)
+
+	assert.Equal(t, "header", blocks[0]["type"])
+	header := blocks[0]["text"].(map[string]interface{})
+	assert.Equal(t, "🔴 🚨 Log Summary Notification", header["text"])
+
+	fields := blocks[1]["fields"].([]map[string]string)
+	assert.Equal(t, "*Source*\n/var/log/app.log", fields[0]["text"])
+	assert.Equal(t, "*Lines*\n12", fields[len(fields)-1]["text"])
+
+	assert.Equal(t, "divider", blocks[2]["type"])
+	body := blocks[3]["text"].(map[string]string)["text"]
+	assert.Equal(t, "*Findings*\n*3* errors in &lt;db&gt; layer, see <https://wiki/x|runbook>", body)
+
+	assert.Equal(t, "context", blocks[4]["type"])
+}
+
+func TestSlackBlocksSplitLongBody(t *testing.T) {
+	body := strings.Repeat(strings.Repeat("x", 99)+"\n", 70)
+	blocks := slackBlocks(NewMessage(MessageTypeSummary, "", body, SeverityInfo))
+
+	var sections int
+	for _, block := range blocks {
+		if text, ok := block["text"].(map[string]string); ok && block["type"] == "section" {
+			sections++
+			assert.LessOrEqual(t, len(text["text"]), slackSectionLimit)
+		}
+	}
+	assert.Equal(t, 3, sections)
+}
+
+func TestSlackWebhookDelivery(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	slack, err := NewSlackService(config.ServiceConfig{Provider: "slack_webhook", Config: map[string]interface{}{"webhook_url": server.URL}})
+	require.NoError(t, err)
+	require.NoError(t, slack.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hello", SeverityInfo)))
+
+	assert.Equal(t, "📢 Lai Notification: hello", captured.Body["text"])
+	assert.NotEmpty(t, captured.Body["blocks"])
+	assert.NotContains(t, captured.Body, "channel")
+}
+
+func TestSlackPostMessage(t *testing.T) {
+	var channels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		data, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &payload))
+
+		channel := payload["channel"].(string)
+		channels = append(channels, channel)
+		if channel == "C404" {
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackService(config.ServiceConfig{Provider: "slack", Config: map[string]interface{}{
+		"oauth_token": "xoxb-token",
+		"channel_ids": []interface{}{"C123", "C404"},
+	}})
+	require.NoError(t, err)
+	slack.apiURL = server.URL
+
+	err = slack.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hello", SeverityInfo))
+	assert.ErrorContains(t, err, "channel C404: api error: channel_not_found")
+	assert.Equal(t, []string{"C123", "C404"}, channels)
+}
+
+func TestNewSlackServiceValidation(t *testing.T) {
+	_, err := NewSlackService(config.ServiceConfig{Provider: "slack_webhook", Config: map[string]interface{}{}})
+	assert.Error(t, err)
+	_, err = NewSlackService(config.ServiceConfig{Provider: "slack", Config: map[string]interface{}{"oauth_token": "x"}})
+	assert.Error(t, err, "channel_ids are required")
+}
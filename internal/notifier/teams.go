@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+// TeamsService posts Adaptive Cards to a Microsoft Teams incoming webhook or
+// Workflows (Power Automate) webhook URL
+type TeamsService struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewTeamsService creates a Teams service from provider configuration
+func NewTeamsService(serviceConfig config.ServiceConfig) (*TeamsService, error) {
+	webhookURL := configString(serviceConfig.Config, "webhook_url")
+	if webhookURL == "" {
+		return nil, fmt.Errorf("teams webhook_url is required")
+	}
+	return &TeamsService{webhookURL: webhookURL, client: &http.Client{Timeout: defaultHTTPTimeout}}, nil
+}
+
+// SendMessage posts the message as an Adaptive Card
+func (t *TeamsService) SendMessage(ctx context.Context, msg *Message) error {
+	if err := postJSON(ctx, t.client, t.webhookURL, teamsPayload(msg), nil); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	return nil
+}
+
+// teamsPayload builds the webhook message wrapping an Adaptive Card
+func teamsPayload(msg *Message) map[string]interface{} {
+	facts := []map[string]string{}
+	for _, field := range msg.Fields() {
+		facts = append(facts, map[string]string{"title": field.Name, "value": field.Value})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"msteams": map[string]string{"width": "Full"},
+		"body": []map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   msg.DisplayTitle(),
+				"weight": "Bolder",
+				"size":   "Medium",
+				"color":  teamsSeverityColor(msg.Severity),
+				"wrap":   true,
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+			{
+				"type": "TextBlock",
+				"text": msg.Body,
+				"wrap": true,
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	}
+}
+
+// teamsSeverityColor maps a severity to an Adaptive Card text color
+func teamsSeverityColor(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "Attention"
+	case SeverityWarning:
+		return "Warning"
+	default:
+		return "Accent"
+	}
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamsAdaptiveCard(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusAccepted)
+
+	teams, err := NewTeamsService(config.ServiceConfig{Config: map[string]interface{}{"webhook_url": server.URL}})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "Database unreachable", SeverityError)
+	require.NoError(t, teams.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "message", captured.Body["type"])
+	attachments := captured.Body["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+
+	attachment := attachments[0].(map[string]interface{})
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+
+	card := attachment["content"].(map[string]interface{})
+	assert.Equal(t, "AdaptiveCard", card["type"])
+
+	body := card["body"].([]interface{})
+	require.Len(t, body, 3)
+	assert.Equal(t, "Attention", body[0].(map[string]interface{})["color"])
+	assert.Equal(t, "Database unreachable", body[2].(map[string]interface{})["text"])
+
+	facts := body[1].(map[string]interface{})["facts"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"title": "Source", "value": "/var/log/app.log"}, facts[0])
+}
+
+func TestTeamsErrors(t *testing.T) {
+	_, err := NewTeamsService(config.ServiceConfig{Config: map[string]interface{}{}})
+	assert.Error(t, err)
+
+	server, _ := newCaptureServer(t, http.StatusBadRequest)
+	teams, err := NewTeamsService(config.ServiceConfig{Config: map[string]interface{}{"webhook_url": server.URL}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, teams.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hi", SeverityInfo)), "status 400")
+}
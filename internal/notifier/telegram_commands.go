@@ -0,0 +1,553 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/logger"
+	"github.com/shiquda/lai/internal/platform"
+)
+
+// telegramAPIURL is the Bot API host, replaced in tests
+var telegramAPIURL = "https://api.telegram.org"
+
+const (
+	telegramPollTimeout     = 30 * time.Second
+	telegramPollRetryDelay  = 5 * time.Second
+	telegramPollLockRetry   = 15 * time.Second
+	defaultTelegramMuteTime = time.Hour
+)
+
+// MonitorStatus is a snapshot of a running monitor, used by bot commands
+type MonitorStatus struct {
+	Name             string
+	Source           string
+	StartedAt        time.Time
+	Lines            int
+	Notifications    int
+	LastNotification time.Time
+	MutedUntil       time.Time
+}
+
+// CommandTarget is a monitor that can be controlled through chat commands
+type CommandTarget interface {
+	// Status returns the current state of the monitor
+	Status() MonitorStatus
+	// Ack acknowledges the latest alert so that repeats of it are not sent
+	// again. It reports false if there was no alert to acknowledge.
+	Ack() bool
+	// Mute suppresses all notifications for the given duration
+	Mute(d time.Duration)
+	// Unmute lifts a mute
+	Unmute()
+	// SummaryNow summarizes and sends the lines collected since the last
+	// summary, returning how many lines were summarized
+	SummaryNow() (int, error)
+}
+
+// TelegramCommandListener long-polls a Telegram bot for commands such as
+// /ack, /mute 1h, /status and /summary now, and applies them to registered
+// monitors. Only messages from the configured chat IDs are accepted.
+//
+// Telegram allows one getUpdates poller per bot, so of all lai processes
+// using a bot only the one holding the bot's lock file polls. It also applies
+// commands to the monitors of the other processes, found by the remote lookup.
+type TelegramCommandListener struct {
+	apiURL   string
+	bot      string
+	lockPath string // Empty to poll without taking the lock
+	chatIDs  map[int64]bool
+	client   *http.Client
+
+	mu      sync.RWMutex
+	targets []CommandTarget
+	remote  func() []CommandTarget
+	offset  int64
+}
+
+// telegramUpdate is the subset of a Telegram update the listener uses
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	MessageID int64            `json:"message_id"`
+	Text      string           `json:"text"`
+	Chat      telegramChat     `json:"chat"`
+	ReplyTo   *telegramMessage `json:"reply_to_message"`
+}
+
+type telegramChat struct {
+	ID int64 `json:"id"`
+}
+
+// TelegramCommandsEnabled reports whether a provider is a Telegram bot with
+// chat commands turned on
+func TelegramCommandsEnabled(serviceConfig config.ServiceConfig) bool {
+	return serviceConfig.Enabled && serviceConfig.Provider == "telegram" && configBool(serviceConfig.Config, "commands")
+}
+
+// NewTelegramCommandListener creates a listener from a Telegram provider's
+// bot_token and chat_id
+func NewTelegramCommandListener(serviceConfig config.ServiceConfig) (*TelegramCommandListener, error) {
+	token := configString(serviceConfig.Config, "bot_token")
+	if token == "" {
+		return nil, fmt.Errorf("telegram bot_token is required")
+	}
+
+	chatIDs := make(map[int64]bool)
+	for _, chatID := range configStringList(serviceConfig.Config, "chat_id") {
+		id, err := strconv.ParseInt(chatID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat_id format '%s': %w", chatID, err)
+		}
+		chatIDs[id] = true
+	}
+	if len(chatIDs) == 0 {
+		return nil, fmt.Errorf("telegram chat_id is required")
+	}
+
+	listener := &TelegramCommandListener{
+		apiURL:  telegramAPIURL + "/bot" + token,
+		bot:     telegramBotID(token),
+		chatIDs: chatIDs,
+		client:  &http.Client{Timeout: telegramPollTimeout + 10*time.Second},
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		listener.lockPath = filepath.Join(homeDir, ".lai", "telegram", listener.bot+".lock")
+	}
+	return listener, nil
+}
+
+// telegramBotID identifies a bot without revealing its token
+func telegramBotID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Bot identifies the bot the listener polls. Monitors of other processes
+// with the same bot are reached through the remote lookup.
+func (l *TelegramCommandListener) Bot() string {
+	return l.bot
+}
+
+// SetRemoteTargets sets the lookup of the monitors of other processes that
+// accept commands from this bot. They are only used while this listener polls.
+func (l *TelegramCommandListener) SetRemoteTargets(lookup func() []CommandTarget) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remote = lookup
+}
+
+// Register adds a monitor that commands can act on
+func (l *TelegramCommandListener) Register(target CommandTarget) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.targets = append(l.targets, target)
+}
+
+// Unregister removes a monitor
+func (l *TelegramCommandListener) Unregister(target CommandTarget) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, registered := range l.targets {
+		if registered == target {
+			l.targets = append(l.targets[:i], l.targets[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run polls for commands until the context is cancelled. While another
+// process polls the bot, it waits to take over if that process exits.
+func (l *TelegramCommandListener) Run(ctx context.Context) {
+	unlock, err := l.waitForPollLock(ctx)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	logger.Info("Listening for Telegram bot commands")
+	for {
+		if err := l.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warnf("Telegram command polling failed: %v", err)
+			if sleepContext(ctx, telegramPollRetryDelay) != nil {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// waitForPollLock takes the bot's lock file, waiting while another process
+// holds it. If the lock cannot be used at all, polling goes ahead without it.
+func (l *TelegramCommandListener) waitForPollLock(ctx context.Context) (func(), error) {
+	if l.lockPath == "" {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.lockPath), 0755); err != nil {
+		logger.Warnf("Failed to create Telegram lock directory, polling without it: %v", err)
+		return func() {}, nil
+	}
+
+	locker := platform.New().Lock
+	waiting := false
+	for {
+		unlock, err := locker.TryLock(l.lockPath)
+		if err == nil {
+			return unlock, nil
+		}
+		if !errors.Is(err, platform.ErrLocked) {
+			logger.Warnf("Failed to lock Telegram command polling, polling without it: %v", err)
+			return func() {}, nil
+		}
+		if !waiting {
+			logger.Info("Another lai process polls this Telegram bot and forwards commands to this one")
+			waiting = true
+		}
+		if err := sleepContext(ctx, telegramPollLockRetry); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// poll fetches one batch of updates and handles the commands in it
+func (l *TelegramCommandListener) poll(ctx context.Context) error {
+	query := url.Values{}
+	query.Set("timeout", strconv.Itoa(int(telegramPollTimeout.Seconds())))
+	query.Set("allowed_updates", `["message"]`)
+	if l.offset > 0 {
+		query.Set("offset", strconv.FormatInt(l.offset, 10))
+	}
+
+	var updates []telegramUpdate
+	if err := l.call(ctx, "getUpdates", query, &updates); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		if update.UpdateID >= l.offset {
+			l.offset = update.UpdateID + 1
+		}
+		if update.Message == nil || !l.chatIDs[update.Message.Chat.ID] {
+			continue
+		}
+		if reply := l.handle(update.Message); reply != "" {
+			if err := l.reply(ctx, update.Message, reply); err != nil {
+				logger.Warnf("Failed to reply to Telegram command: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// handle runs a command and returns the reply text, or "" for messages that
+// are not commands
+func (l *TelegramCommandListener) handle(message *telegramMessage) string {
+	words := strings.Fields(message.Text)
+	if len(words) == 0 || !strings.HasPrefix(words[0], "/") {
+		return ""
+	}
+
+	// Commands may be addressed to the bot, e.g. /status@lai_bot
+	command, _, _ := strings.Cut(strings.ToLower(words[0]), "@")
+	args := words[1:]
+
+	switch command {
+	case "/ack", "/mute", "/unmute", "/status", "/summary":
+	case "/help", "/start":
+		return telegramCommandHelp
+	default:
+		return ""
+	}
+
+	target, args, reply := l.resolveTarget(message, command, args)
+	if reply != "" {
+		return reply
+	}
+	if target == nil {
+		return l.statusAll()
+	}
+
+	status := target.Status()
+	switch command {
+	case "/ack":
+		if !target.Ack() {
+			return fmt.Sprintf("ℹ️ No alert to acknowledge for %s", status.Name)
+		}
+		return fmt.Sprintf("✅ Acknowledged the latest alert for %s; repeats of it will not be sent", status.Name)
+	case "/mute":
+		duration := defaultTelegramMuteTime
+		if len(args) > 0 {
+			parsed, err := parseMuteDuration(args[0])
+			if err != nil {
+				return fmt.Sprintf("❌ %v", err)
+			}
+			duration = parsed
+		}
+		target.Mute(duration)
+		return fmt.Sprintf("🔕 Muted %s for %v (until %s)", status.Name, duration, time.Now().Add(duration).Format("15:04"))
+	case "/unmute":
+		target.Unmute()
+		return fmt.Sprintf("🔔 Unmuted %s", status.Name)
+	case "/summary":
+		lines, err := target.SummaryNow()
+		if err != nil {
+			return fmt.Sprintf("❌ Failed to summarize %s: %v", status.Name, err)
+		}
+		if lines == 0 {
+			return fmt.Sprintf("ℹ️ No new lines from %s since the last summary", status.Name)
+		}
+		return fmt.Sprintf("📝 Sent a summary of %d lines from %s", lines, status.Name)
+	default:
+		return formatMonitorStatus(status)
+	}
+}
+
+const telegramCommandHelp = `Lai commands (reply to an alert, or name the monitor after the command):
+/ack - stop repeats of the latest alert
+/mute 1h - mute notifications (default 1h)
+/unmute - lift a mute
+/status - show monitor status
+/summary now - summarize lines collected since the last summary`
+
+// resolveTarget picks the monitor a command applies to: the one named in the
+// alert being replied to, the one named as the first argument, or the only
+// registered monitor when the command neither replies to an alert nor names
+// one. A nil target with no reply means "all monitors", which only /status
+// allows.
+func (l *TelegramCommandListener) resolveTarget(message *telegramMessage, command string, args []string) (CommandTarget, []string, string) {
+	targets := l.allTargets()
+	if len(targets) == 0 {
+		return nil, args, "ℹ️ No monitors are running"
+	}
+
+	if message.ReplyTo != nil {
+		if target := matchTargetInText(targets, message.ReplyTo.Text); target != nil {
+			return target, args, ""
+		}
+	}
+
+	named := len(args) > 0 && !isCommandArgument(command, args[0])
+	if named {
+		for _, target := range targets {
+			if strings.EqualFold(target.Status().Name, args[0]) {
+				return target, args[1:], ""
+			}
+		}
+	}
+
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Status().Name)
+	}
+	switch {
+	case named:
+		return nil, args, fmt.Sprintf("❓ Unknown monitor %s. Running: %s", args[0], strings.Join(names, ", "))
+	case command == "/status":
+		if message.ReplyTo == nil && len(targets) == 1 {
+			return targets[0], args, ""
+		}
+		return nil, args, ""
+	case message.ReplyTo != nil:
+		return nil, args, "❓ That alert is not from a running monitor. Running: " + strings.Join(names, ", ")
+	case len(targets) == 1:
+		return targets[0], args, ""
+	}
+	return nil, args, "❓ Which monitor? Reply to an alert or name one: " + strings.Join(names, ", ")
+}
+
+// isCommandArgument reports whether word is an argument of the command, such
+// as the duration of /mute, rather than the name of a monitor
+func isCommandArgument(command, word string) bool {
+	switch command {
+	case "/mute":
+		_, err := parseMuteDuration(word)
+		return err == nil
+	case "/summary":
+		return strings.EqualFold(word, "now")
+	}
+	return false
+}
+
+// matchTargetInText finds the monitor whose name or source is the whole value
+// of a field of an alert, e.g. "🏷 Monitor: api"
+func matchTargetInText(targets []CommandTarget, text string) CommandTarget {
+	for _, label := range []string{"Monitor: ", "Source: "} {
+		values := fieldValues(text, label)
+		for _, target := range targets {
+			status := target.Status()
+			value := status.Name
+			if label == "Source: " {
+				value = status.Source
+			}
+			if value == "" {
+				continue
+			}
+			for _, field := range values {
+				if field == value {
+					return target
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldValues returns the values of the lines of text that hold the field
+// with the given label, after any icon in front of it
+func fieldValues(text, label string) []string {
+	var values []string
+	for _, line := range strings.Split(text, "\n") {
+		if _, value, found := strings.Cut(line, label); found {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values
+}
+
+// allTargets returns the registered monitors followed by the monitors of
+// other processes, skipping those named like a registered one
+func (l *TelegramCommandListener) allTargets() []CommandTarget {
+	l.mu.RLock()
+	targets := append([]CommandTarget(nil), l.targets...)
+	remote := l.remote
+	l.mu.RUnlock()
+
+	if remote == nil {
+		return targets
+	}
+	local := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		local[strings.ToLower(target.Status().Name)] = true
+	}
+	for _, target := range remote() {
+		if !local[strings.ToLower(target.Status().Name)] {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// statusAll formats the status of every monitor
+func (l *TelegramCommandListener) statusAll() string {
+	targets := l.allTargets()
+	parts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		parts = append(parts, formatMonitorStatus(target.Status()))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// formatMonitorStatus renders a monitor status as a chat reply
+func formatMonitorStatus(status MonitorStatus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 %s\n", status.Name)
+	if status.Source != status.Name {
+		fmt.Fprintf(&b, "Source: %s\n", status.Source)
+	}
+	if !status.StartedAt.IsZero() {
+		fmt.Fprintf(&b, "Uptime: %v\n", time.Since(status.StartedAt).Round(time.Second))
+	}
+	fmt.Fprintf(&b, "Lines: %d\n", status.Lines)
+	fmt.Fprintf(&b, "Notifications sent: %d", status.Notifications)
+	if !status.LastNotification.IsZero() {
+		fmt.Fprintf(&b, " (last at %s)", status.LastNotification.Format("15:04:05"))
+	}
+	if status.MutedUntil.After(time.Now()) {
+		fmt.Fprintf(&b, "\nMuted until %s", status.MutedUntil.Format("15:04"))
+	}
+	return b.String()
+}
+
+// parseMuteDuration parses durations such as "30m", "1h" or "2d"
+func parseMuteDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 1h or 1d)", value)
+	}
+	return duration, nil
+}
+
+// reply answers a command in the chat it came from
+func (l *TelegramCommandListener) reply(ctx context.Context, message *telegramMessage, text string) error {
+	query := url.Values{}
+	query.Set("chat_id", strconv.FormatInt(message.Chat.ID, 10))
+	query.Set("text", text)
+	query.Set("reply_to_message_id", strconv.FormatInt(message.MessageID, 10))
+	return l.call(ctx, "sendMessage", query, nil)
+}
+
+// call invokes a Bot API method and decodes its result
+func (l *TelegramCommandListener) call(ctx context.Context, method string, query url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.apiURL+"/"+method, strings.NewReader(query.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !response.OK {
+		return fmt.Errorf("%s failed: %s", method, response.Description)
+	}
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// configBool returns a boolean provider setting given as a bool or string
+func configBool(values map[string]interface{}, key string) bool {
+	switch value := values[key].(type) {
+	case bool:
+		return value
+	case string:
+		parsed, _ := strconv.ParseBool(strings.TrimSpace(value))
+		return parsed
+	default:
+		return false
+	}
+}
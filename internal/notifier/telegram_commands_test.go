@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCommandTarget struct {
+	name, source string
+	acked        bool
+	mutedFor     time.Duration
+	summaries    int
+}
+
+func (f *fakeCommandTarget) Status() MonitorStatus {
+	return MonitorStatus{Name: f.name, Source: f.source, Lines: 42}
+}
+func (f *fakeCommandTarget) Ack() bool                { f.acked = true; return true }
+func (f *fakeCommandTarget) Mute(d time.Duration)     { f.mutedFor = d }
+func (f *fakeCommandTarget) Unmute()                  { f.mutedFor = 0 }
+func (f *fakeCommandTarget) SummaryNow() (int, error) { f.summaries++; return 7, nil }
+
+func TestNewTelegramCommandListener(t *testing.T) {
+	_, err := NewTelegramCommandListener(config.ServiceConfig{Config: map[string]interface{}{"chat_id": "1"}})
+	assert.ErrorContains(t, err, "bot_token")
+
+	_, err = NewTelegramCommandListener(config.ServiceConfig{Config: map[string]interface{}{"bot_token": "t", "chat_id": "abc"}})
+	assert.ErrorContains(t, err, "invalid chat_id")
+
+	listener, err := NewTelegramCommandListener(config.ServiceConfig{Config: map[string]interface{}{"bot_token": "t", "chat_id": "1,-100"}})
+	require.NoError(t, err)
+	assert.True(t, listener.chatIDs[-100])
+}
+
+func TestTelegramCommandsEnabled(t *testing.T) {
+	serviceConfig := config.ServiceConfig{Enabled: true, Provider: "telegram", Config: map[string]interface{}{"commands": true}}
+	assert.True(t, TelegramCommandsEnabled(serviceConfig))
+
+	serviceConfig.Config["commands"] = "false"
+	assert.False(t, TelegramCommandsEnabled(serviceConfig))
+
+	serviceConfig.Config["commands"] = "true"
+	serviceConfig.Provider = "discord"
+	assert.False(t, TelegramCommandsEnabled(serviceConfig))
+}
+
+func TestTelegramCommandHandle(t *testing.T) {
+	api := &fakeCommandTarget{name: "api", source: "/var/log/api.log"}
+	worker := &fakeCommandTarget{name: "worker", source: "worker.sh"}
+	listener := &TelegramCommandListener{}
+	listener.Register(api)
+	listener.Register(worker)
+
+	assert.Empty(t, listener.handle(&telegramMessage{Text: "hello"}))
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/ack"}), "Which monitor?")
+
+	reply := listener.handle(&telegramMessage{
+		Text:    "/ack@lai_bot",
+		ReplyTo: &telegramMessage{Text: "🚨 Error Alert\n📁 Source: worker.sh\n⏰ Time: 12:00"},
+	})
+	assert.Contains(t, reply, "Acknowledged")
+	assert.True(t, worker.acked)
+	assert.False(t, api.acked)
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/mute api 2h"}), "Muted api")
+	assert.Equal(t, 2*time.Hour, api.mutedFor)
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/mute api soon"}), "invalid duration")
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/summary worker now"}), "7 lines")
+	assert.Equal(t, 1, worker.summaries)
+
+	status := listener.handle(&telegramMessage{Text: "/status"})
+	assert.Contains(t, status, "📊 api")
+	assert.Contains(t, status, "📊 worker")
+
+	listener.Unregister(worker)
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/mute"}), "for 1h0m0s")
+	assert.Equal(t, time.Hour, api.mutedFor)
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/mute 30m"}), "Muted api")
+	assert.Equal(t, 30*time.Minute, api.mutedFor)
+
+	// With one monitor left, commands for another one are not applied to it
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/ack worker"}), "Unknown monitor worker")
+	reply = listener.handle(&telegramMessage{
+		Text:    "/ack",
+		ReplyTo: &telegramMessage{Text: "🚨 Error Alert\n📁 Source: worker.sh"},
+	})
+	assert.Contains(t, reply, "not from a running monitor")
+	assert.False(t, api.acked)
+}
+
+func TestParseMuteDuration(t *testing.T) {
+	d, err := parseMuteDuration("30m")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, d)
+
+	d, err = parseMuteDuration("2d")
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, d)
+
+	_, err = parseMuteDuration("-1h")
+	assert.Error(t, err)
+}
+
+func TestTelegramCommandListenerPoll(t *testing.T) {
+	var mu sync.Mutex
+	var replies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			assert.Equal(t, "", r.Form.Get("offset"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok": true,
+				"result": []map[string]interface{}{
+					{"update_id": 10, "message": map[string]interface{}{"message_id": 1, "text": "/status", "chat": map[string]interface{}{"id": 99}}},
+					{"update_id": 11, "message": map[string]interface{}{"message_id": 2, "text": "/mute", "chat": map[string]interface{}{"id": 5}}},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			mu.Lock()
+			replies = append(replies, r.Form.Get("chat_id")+":"+r.Form.Get("reply_to_message_id")+":"+r.Form.Get("text"))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	target := &fakeCommandTarget{name: "api", source: "api.log"}
+	listener := &TelegramCommandListener{
+		apiURL:  server.URL + "/bottoken",
+		chatIDs: map[int64]bool{99: true},
+		client:  server.Client(),
+	}
+	listener.Register(target)
+
+	require.NoError(t, listener.poll(context.Background()))
+	assert.Equal(t, int64(12), listener.offset)
+	assert.Zero(t, target.mutedFor, "commands from other chats are ignored")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, replies, 1)
+	assert.True(t, strings.HasPrefix(replies[0], "99:1:📊 api"), replies[0])
+}
+
+func TestTelegramPollLockAllowsOnePoller(t *testing.T) {
+	lockPath := t.TempDir() + "/bot.lock"
+	first := &TelegramCommandListener{lockPath: lockPath}
+	second := &TelegramCommandListener{lockPath: lockPath}
+
+	unlock, err := first.waitForPollLock(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = second.waitForPollLock(ctx)
+	assert.Error(t, err, "a second listener waits while the bot is polled")
+
+	unlock()
+	unlock, err = second.waitForPollLock(context.Background())
+	require.NoError(t, err, "the lock is taken over once released")
+	unlock()
+}
+
+func TestTelegramCommandHandleRemoteTargets(t *testing.T) {
+	api := &fakeCommandTarget{name: "api", source: "api.log"}
+	remoteAPI := &fakeCommandTarget{name: "api", source: "other.log"}
+	worker := &fakeCommandTarget{name: "worker", source: "worker.sh"}
+	listener := &TelegramCommandListener{}
+	listener.Register(api)
+	listener.SetRemoteTargets(func() []CommandTarget { return []CommandTarget{remoteAPI, worker} })
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/ack worker"}), "Acknowledged")
+	assert.True(t, worker.acked, "commands reach monitors of other processes")
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/ack api"}), "Acknowledged")
+	assert.True(t, api.acked)
+	assert.False(t, remoteAPI.acked, "registered monitors win over remote ones of the same name")
+
+	assert.Contains(t, listener.handle(&telegramMessage{Text: "/mute"}), "Which monitor?")
+	status := listener.handle(&telegramMessage{Text: "/status"})
+	assert.Contains(t, status, "📊 worker")
+}
+
+func TestMatchTargetInTextRequiresWholeField(t *testing.T) {
+	gateway := &fakeCommandTarget{name: "api-gateway", source: "/var/log/app.log.1"}
+	api := &fakeCommandTarget{name: "api", source: "/var/log/app.log"}
+	targets := []CommandTarget{gateway, api}
+
+	assert.Same(t, api, matchTargetInText(targets, "🚨 Error Alert\n🏷 Monitor: api\n⏰ Time: 12:00"))
+	assert.Same(t, gateway, matchTargetInText(targets, "🏷 Monitor: api-gateway"))
+	assert.Same(t, api, matchTargetInText(targets, "📁 Source: /var/log/app.log\n"))
+	assert.Same(t, gateway, matchTargetInText(targets, "📁 Source: /var/log/app.log.1"))
+	assert.Nil(t, matchTargetInText(targets, "🏷 Monitor: ap"))
+}
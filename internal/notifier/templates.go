@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/shiquda/lai/internal/logger"
+)
+
+// messageTemplates holds the parsed notification templates of one provider.
+// A nil template keeps the built-in title or text.
+type messageTemplates struct {
+	title        *template.Template
+	body         *template.Template
+	errorBody    *template.Template
+	finalSummary *template.Template
+	lifecycle    *template.Template
+}
+
+// parseMessageTemplates parses a provider's templates. It returns nil when
+// none are configured.
+func parseMessageTemplates(cfg config.NotificationTemplates) (*messageTemplates, error) {
+	if cfg == (config.NotificationTemplates{}) {
+		return nil, nil
+	}
+
+	templates := &messageTemplates{}
+	for _, item := range []struct {
+		name   string
+		text   string
+		target **template.Template
+	}{
+		{"title", cfg.Title, &templates.title},
+		{"body", cfg.Body, &templates.body},
+		{"error", cfg.Error, &templates.errorBody},
+		{"final_summary", cfg.FinalSummary, &templates.finalSummary},
+		{"lifecycle", cfg.Lifecycle, &templates.lifecycle},
+	} {
+		if strings.TrimSpace(item.text) == "" {
+			continue
+		}
+		tmpl, err := template.New(item.name).Parse(item.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", item.name, err)
+		}
+		*item.target = tmpl
+	}
+	return templates, nil
+}
+
+// apply returns a copy of the message with its title and text rendered from
+// the templates. A template that fails to render keeps the original value.
+func (t *messageTemplates) apply(msg *Message) *Message {
+	if t == nil {
+		return msg
+	}
+
+	rendered := *msg
+	data := newTemplateData(msg)
+
+	if t.title != nil {
+		if title, err := executeTemplate(t.title, data); err != nil {
+			logger.Warnf("Failed to render title template: %v", err)
+		} else {
+			rendered.Title = strings.TrimSpace(title)
+		}
+	}
+
+	if bodyTemplate := t.bodyFor(msg.Type); bodyTemplate != nil {
+		if body, err := executeTemplate(bodyTemplate, data); err != nil {
+			logger.Warnf("Failed to render %s template: %v", bodyTemplate.Name(), err)
+		} else {
+			rendered.Body = body
+		}
+	}
+	return &rendered
+}
+
+// bodyFor returns the text template used for a message type
+func (t *messageTemplates) bodyFor(msgType MessageType) *template.Template {
+	switch msgType {
+	case MessageTypeError:
+		return t.errorBody
+	case MessageTypeFinalSummary:
+		return t.finalSummary
+	case MessageTypeLifecycle:
+		return t.lifecycle
+	default:
+		return t.body
+	}
+}
+
+// newTemplateData builds the template data for a message
+func newTemplateData(msg *Message) TemplateData {
+	details := make(map[string]string, len(msg.Details))
+	for _, detail := range msg.Details {
+		details[detail.Name] = detail.Value
+	}
+
+	return TemplateData{
+		FilePath:    msg.Source,
+		Time:        msg.formattedTime(),
+		Summary:     msg.Body,
+		ProcessName: msg.Monitor,
+		LineCount:   msg.LineCount,
+		Severity:    msg.Severity,
+		Host:        msg.Host,
+		Type:        string(msg.Type),
+		Event:       msg.Event,
+		Window:      msg.formattedWindow(),
+		Details:     details,
+	}
+}
+
+// executeTemplate renders a template to a string
+func executeTemplate(tmpl *template.Template, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
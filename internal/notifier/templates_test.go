@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessageTemplates(t *testing.T) {
+	templates, err := parseMessageTemplates(config.NotificationTemplates{})
+	require.NoError(t, err)
+	assert.Nil(t, templates)
+
+	_, err = parseMessageTemplates(config.NotificationTemplates{Body: "{{.Summary"})
+	assert.ErrorContains(t, err, "invalid body template")
+}
+
+func TestMessageTemplatesApply(t *testing.T) {
+	templates, err := parseMessageTemplates(config.NotificationTemplates{
+		Title:        "[{{.Severity}}] {{.ProcessName}} on {{.Host}}",
+		Body:         "{{.LineCount}} lines from {{.FilePath}}: {{.Summary}}",
+		Error:        "ERROR: {{.Summary}}",
+		FinalSummary: "Done ({{.Window}}): {{.Summary}}",
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := &Message{
+		Type:        MessageTypeSummary,
+		Source:      "/var/log/app.log",
+		Monitor:     "api",
+		Host:        "web-1",
+		Body:        "All good",
+		Severity:    SeverityWarning,
+		LineCount:   12,
+		WindowStart: start,
+		WindowEnd:   start.Add(30 * time.Second),
+		Time:        start,
+	}
+
+	rendered := templates.apply(msg)
+	assert.Equal(t, "[warning] api on web-1", rendered.Title)
+	assert.Equal(t, "12 lines from /var/log/app.log: All good", rendered.Body)
+	assert.Equal(t, "All good", msg.Body, "the original message is left untouched")
+
+	msg.Type = MessageTypeError
+	assert.Equal(t, "ERROR: All good", templates.apply(msg).Body)
+
+	msg.Type = MessageTypeFinalSummary
+	assert.Equal(t, "Done (12:00:00 - 12:00:30 (30s)): All good", templates.apply(msg).Body)
+}
+
+func TestMessageTemplatesApplyKeepsDefaultsOnError(t *testing.T) {
+	templates, err := parseMessageTemplates(config.NotificationTemplates{Title: "{{.Missing}}"})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "app.log", "body", SeverityInfo)
+	rendered := templates.apply(msg)
+	assert.Empty(t, rendered.Title)
+	assert.Equal(t, "body", rendered.Body)
+
+	var nilTemplates *messageTemplates
+	assert.Same(t, msg, nilTemplates.apply(msg))
+}
+
+func TestNotifyNotifierUsesProviderTemplates(t *testing.T) {
+	server, captured := newCaptureServer(t, http.StatusOK)
+
+	nn, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"hook": {
+				Enabled:  true,
+				Provider: "webhook",
+				Config:   map[string]interface{}{"url": server.URL},
+				Templates: config.NotificationTemplates{
+					Title: "{{.ProcessName}} report",
+					Body:  "{{.Summary}} ({{.LineCount}} lines)",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "Disk almost full", SeverityWarning)
+	msg.Monitor = "api"
+	msg.LineCount = 3
+	require.NoError(t, nn.Send(context.Background(), msg))
+
+	assert.Equal(t, "api report", captured.Body["title"])
+	assert.Equal(t, "Disk almost full (3 lines)", captured.Body["summary"])
+}
+
+func TestNotifyNotifierRejectsInvalidTemplate(t *testing.T) {
+	_, err := NewNotifyNotifier(&config.NotificationsConfig{
+		Providers: map[string]config.ServiceConfig{
+			"hook": {
+				Enabled:   true,
+				Provider:  "webhook",
+				Config:    map[string]interface{}{"url": "http://localhost"},
+				Templates: config.NotificationTemplates{Body: "{{end}}"},
+			},
+		},
+	})
+	assert.Error(t, err, "a provider with an invalid template is not enabled")
+}
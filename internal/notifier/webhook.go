@@ -0,0 +1,316 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+)
+
+const (
+	defaultWebhookMethod     = http.MethodPost
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookRetries    = 2
+	defaultWebhookRetryDelay = time.Second
+	defaultWebhookSigHeader  = "X-Lai-Signature"
+)
+
+// defaultWebhookBodyTemplate posts every field as a flat JSON object
+const defaultWebhookBodyTemplate = `{
+  "type": {{json .Type}},
+  "title": {{json .Title}},
+  "summary": {{json .Summary}},
+  "severity": {{json .Severity}},
+  "source": {{json .Source}},
+  "host": {{json .Host}},
+  "monitor": {{json .Monitor}},
+  "time": {{json .Time}}
+}`
+
+// WebhookTemplateData is the data available to a webhook body template
+type WebhookTemplateData struct {
+	Type     string
+	Title    string
+	Summary  string
+	Severity string
+	Source   string
+	Host     string
+	Monitor  string
+	Time     string
+}
+
+// WebhookService posts notifications to an arbitrary HTTP endpoint with a
+// templated JSON body, optional HMAC signing and retries
+type WebhookService struct {
+	url        string
+	method     string
+	headers    map[string]string
+	body       *template.Template
+	secret     string
+	sigHeader  string
+	retries    int
+	retryDelay time.Duration
+	client     *http.Client
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// NewWebhookService creates a webhook service from provider configuration.
+// Supported keys: url (required), method, headers, body_template, hmac_secret,
+// hmac_header, retries, retry_delay and timeout.
+func NewWebhookService(serviceConfig config.ServiceConfig) (*WebhookService, error) {
+	url := configString(serviceConfig.Config, "url")
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+
+	method := strings.ToUpper(configString(serviceConfig.Config, "method"))
+	if method == "" {
+		method = defaultWebhookMethod
+	}
+
+	bodyTemplate := configString(serviceConfig.Config, "body_template")
+	if bodyTemplate == "" {
+		bodyTemplate = defaultWebhookBodyTemplate
+	}
+	body, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook body_template: %w", err)
+	}
+
+	headers := make(map[string]string)
+	if rawHeaders, ok := serviceConfig.Config["headers"].(map[string]interface{}); ok {
+		for key, value := range rawHeaders {
+			headers[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	retries := defaultWebhookRetries
+	if value, ok, err := configInt(serviceConfig.Config, "retries"); err != nil {
+		return nil, err
+	} else if ok {
+		retries = value
+	}
+	if retries < 0 {
+		return nil, fmt.Errorf("webhook retries must be non-negative")
+	}
+
+	retryDelay, err := configDuration(serviceConfig.Config, "retry_delay", defaultWebhookRetryDelay)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := configDuration(serviceConfig.Config, "timeout", defaultWebhookTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	sigHeader := configString(serviceConfig.Config, "hmac_header")
+	if sigHeader == "" {
+		sigHeader = defaultWebhookSigHeader
+	}
+
+	return &WebhookService{
+		url:        url,
+		method:     method,
+		headers:    headers,
+		body:       body,
+		secret:     configString(serviceConfig.Config, "hmac_secret"),
+		sigHeader:  sigHeader,
+		retries:    retries,
+		retryDelay: retryDelay,
+		client:     &http.Client{Timeout: timeout},
+		sleep:      sleepContext,
+	}, nil
+}
+
+// SendMessage renders the body template for a message and posts it, retrying
+// on network errors, 429 and 5xx responses
+func (w *WebhookService) SendMessage(ctx context.Context, msg *Message) error {
+	payload, err := w.render(msg)
+	if err != nil {
+		return err
+	}
+
+	delay := w.retryDelay
+	for attempt := 0; ; attempt++ {
+		retryable, err := w.post(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= w.retries {
+			return err
+		}
+
+		if err := w.sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// render executes the body template and checks that it produced valid JSON
+func (w *WebhookService) render(msg *Message) ([]byte, error) {
+	data := WebhookTemplateData{
+		Type:     string(msg.Type),
+		Title:    msg.DisplayTitle(),
+		Summary:  msg.Body,
+		Severity: msg.Severity,
+		Source:   msg.Source,
+		Host:     msg.Host,
+		Monitor:  msg.Monitor,
+		Time:     msg.Time.Format(time.RFC3339),
+	}
+
+	var buf bytes.Buffer
+	if err := w.body.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook body_template did not produce valid JSON (use {{json .Field}} to quote values)")
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends a single request and reports whether a failure is worth retrying
+func (w *WebhookService) post(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, w.method, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+	if w.secret != "" {
+		req.Header.Set(w.sigHeader, "sha256="+signPayload(w.secret, payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+// signPayload returns the hex-encoded HMAC-SHA256 of the payload
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// toJSON encodes a template value as a JSON literal
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// configString returns a string provider setting, or "" if unset
+func configString(values map[string]interface{}, key string) string {
+	if value, ok := values[key].(string); ok {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// configStringList returns a list provider setting given as a list or a comma-separated string
+func configStringList(values map[string]interface{}, key string) []string {
+	var items []string
+	switch value := values[key].(type) {
+	case []interface{}:
+		for _, item := range value {
+			if text := strings.TrimSpace(fmt.Sprintf("%v", item)); text != "" {
+				items = append(items, text)
+			}
+		}
+	case []string:
+		for _, item := range value {
+			if text := strings.TrimSpace(item); text != "" {
+				items = append(items, text)
+			}
+		}
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if text := strings.TrimSpace(item); text != "" {
+				items = append(items, text)
+			}
+		}
+	}
+	return items
+}
+
+// configInt returns an integer provider setting given as a number or string
+func configInt(values map[string]interface{}, key string) (int, bool, error) {
+	switch value := values[key].(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return value, true, nil
+	case float64:
+		return int(value), true, nil
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s: %q is not a number", key, value)
+		}
+		return parsed, true, nil
+	default:
+		return 0, false, fmt.Errorf("invalid %s: unsupported value %v", key, value)
+	}
+}
+
+// configDuration returns a duration provider setting such as "5s", or the default if unset
+func configDuration(values map[string]interface{}, key string, defaultValue time.Duration) (time.Duration, error) {
+	switch value := values[key].(type) {
+	case nil:
+		return defaultValue, nil
+	case time.Duration:
+		return value, nil
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return defaultValue, nil
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("invalid %s: expected a duration such as \"5s\"", key)
+	}
+}
@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shiquda/lai/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhook(t *testing.T, settings map[string]interface{}) *WebhookService {
+	webhook, err := NewWebhookService(config.ServiceConfig{Enabled: true, Provider: "webhook", Config: settings})
+	require.NoError(t, err)
+	webhook.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return webhook
+}
+
+func TestWebhookDefaultBodyAndSignature(t *testing.T) {
+	var received map[string]interface{}
+	var signature, token, method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		signature = r.Header.Get("X-Lai-Signature")
+		token = r.Header.Get("Authorization")
+		method = r.Method
+		assert.Equal(t, "sha256="+signPayload("s3cret", body), signature)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhook(t, map[string]interface{}{
+		"url":         server.URL,
+		"method":      "put",
+		"headers":     map[string]interface{}{"Authorization": "Bearer abc"},
+		"hmac_secret": "s3cret",
+	})
+
+	msg := NewMessage(MessageTypeSummary, "/var/log/app.log", "Disk \"full\"\non /data", SeverityError)
+	msg.Monitor = "api"
+	msg.Host = "web-01"
+	require.NoError(t, webhook.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "Bearer abc", token)
+	assert.NotEmpty(t, signature)
+	assert.Equal(t, "Disk \"full\"\non /data", received["summary"])
+	assert.Equal(t, "error", received["severity"])
+	assert.Equal(t, "/var/log/app.log", received["source"])
+	assert.Equal(t, "web-01", received["host"])
+	assert.Equal(t, "api", received["monitor"])
+}
+
+func TestWebhookCustomTemplate(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhook(t, map[string]interface{}{
+		"url":           server.URL,
+		"body_template": `{"text": {{json (printf "[%s] %s" .Severity .Summary)}}, "labels": {"host": {{json .Host}}}}`,
+	})
+	msg := NewMessage(MessageTypeError, "", "boom", SeverityWarning)
+	msg.Host = "web-01"
+	require.NoError(t, webhook.SendMessage(context.Background(), msg))
+
+	assert.Equal(t, "[warning] boom", received["text"])
+	assert.Equal(t, map[string]interface{}{"host": "web-01"}, received["labels"])
+}
+
+func TestWebhookInvalidTemplateOutput(t *testing.T) {
+	webhook := newTestWebhook(t, map[string]interface{}{
+		"url":           "http://127.0.0.1:1",
+		"body_template": `{"text": {{.Summary}}}`,
+	})
+	err := webhook.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "not quoted", SeverityInfo))
+	assert.ErrorContains(t, err, "valid JSON")
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		retries       interface{}
+		expectedCalls int
+		expectError   bool
+	}{
+		{name: "Server errors are retried", status: http.StatusBadGateway, retries: 2, expectedCalls: 3, expectError: true},
+		{name: "Retries from string setting", status: http.StatusTooManyRequests, retries: "1", expectedCalls: 2, expectError: true},
+		{name: "Client errors are not retried", status: http.StatusBadRequest, retries: 3, expectedCalls: 1, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			webhook := newTestWebhook(t, map[string]interface{}{"url": server.URL, "retries": tt.retries})
+			err := webhook.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hello", SeverityInfo))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+
+	// A transient failure followed by success delivers the message
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhook(t, map[string]interface{}{"url": server.URL})
+	assert.NoError(t, webhook.SendMessage(context.Background(), NewMessage(MessageTypeMessage, "", "hello", SeverityInfo)))
+	assert.Equal(t, 2, calls)
+}
+
+func TestNewWebhookServiceValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{name: "Missing url", settings: map[string]interface{}{}},
+		{name: "Bad template", settings: map[string]interface{}{"url": "http://x", "body_template": "{{.Summary"}},
+		{name: "Bad retries", settings: map[string]interface{}{"url": "http://x", "retries": "many"}},
+		{name: "Negative retries", settings: map[string]interface{}{"url": "http://x", "retries": -1}},
+		{name: "Bad retry delay", settings: map[string]interface{}{"url": "http://x", "retry_delay": "soon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookService(config.ServiceConfig{Provider: "webhook", Config: tt.settings})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"errors"
 	"os"
 )
 
@@ -12,7 +13,7 @@ type ProcessManager interface {
 	// IsProcessRunning checks if a process with given PID is running
 	IsProcessRunning(pid int) bool
 
-	// TerminateProcess terminates a process gracefully
+	// TerminateProcess terminates a process gracefully and waits for it to exit
 	TerminateProcess(pid int) error
 
 	// KillProcess force kills a process
@@ -37,11 +38,23 @@ type PathHelper interface {
 	GetConfigDir() (string, error)
 }
 
+// FileLocker provides cross-platform exclusive locks between processes
+type FileLocker interface {
+	// TryLock locks the file at path, creating it if needed, and returns
+	// ErrLocked if another holder has it locked. The lock is released by
+	// unlock or when the process exits.
+	TryLock(path string) (unlock func(), err error)
+}
+
+// ErrLocked is returned by TryLock when the file is already locked
+var ErrLocked = errors.New("file is locked by another process")
+
 // Platform provides all platform-specific functionality
 type Platform struct {
 	Process ProcessManager
 	Signal  SignalHandler
 	Path    PathHelper
+	Lock    FileLocker
 }
 
 // New returns a platform instance for the current OS
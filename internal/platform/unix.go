@@ -3,6 +3,7 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,6 +12,9 @@ import (
 	"time"
 )
 
+// gracefulStopTimeout is how long TerminateProcess waits for a process to exit after SIGTERM
+const gracefulStopTimeout = 30 * time.Second
+
 type unixProcessManager struct{}
 
 func (u *unixProcessManager) StartDaemonProcess(execPath string, args []string, logFile *os.File, env []string) (*os.Process, error) {
@@ -44,10 +48,14 @@ func (u *unixProcessManager) TerminateProcess(pid int) error {
 		return fmt.Errorf("failed to send SIGTERM to process %d: %w", pid, err)
 	}
 
-	// Wait a bit to see if process stops gracefully
-	time.Sleep(2 * time.Second)
-	if !u.IsProcessRunning(pid) {
-		return nil
+	// Give the process time to stop gracefully; monitors may still be sending
+	// a final summary
+	deadline := time.Now().Add(gracefulStopTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if !u.IsProcessRunning(pid) {
+			return nil
+		}
 	}
 
 	// Process still running, return without force killing
@@ -94,10 +102,33 @@ func (u *unixPathHelper) GetConfigDir() (string, error) {
 	return filepath.Join(homeDir, ".lai"), nil
 }
 
+type unixFileLocker struct{}
+
+func (u *unixFileLocker) TryLock(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
 func newPlatform() *Platform {
 	return &Platform{
 		Process: &unixProcessManager{},
 		Signal:  &unixSignalHandler{},
 		Path:    &unixPathHelper{},
+		Lock:    &unixFileLocker{},
 	}
 }
@@ -3,13 +3,18 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 )
 
+// gracefulStopTimeout is how long TerminateProcess waits for a process to exit
+const gracefulStopTimeout = 30 * time.Second
+
 type windowsProcessManager struct{}
 
 func (w *windowsProcessManager) StartDaemonProcess(execPath string, args []string, logFile *os.File, env []string) (*os.Process, error) {
@@ -63,12 +68,27 @@ func (w *windowsProcessManager) terminateProcessWindows(pid int, forceKill bool)
 		return fmt.Errorf("failed to find process %d: %w", pid, err)
 	}
 
-	// On Windows, we use process.Kill() which terminates the process
+	// On Windows, we use process.Kill() which terminates the process. There is
+	// no SIGTERM; daemons are asked to stop through their control API first.
 	if err := process.Kill(); err != nil {
 		return fmt.Errorf("failed to terminate process %d: %w", pid, err)
 	}
+	if forceKill {
+		return nil
+	}
 
-	return nil
+	// Like on Unix, only report success once the process has exited
+	exited := make(chan struct{})
+	go func() {
+		process.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(gracefulStopTimeout):
+		return fmt.Errorf("process %d did not terminate gracefully", pid)
+	}
 }
 
 type windowsSignalHandler struct{}
@@ -103,10 +123,37 @@ func (w *windowsPathHelper) GetConfigDir() (string, error) {
 	return filepath.Join(homeDir, ".lai"), nil
 }
 
+// errorSharingViolation is returned when opening a file another handle
+// opened without sharing
+const errorSharingViolation syscall.Errno = 32
+
+type windowsFileLocker struct{}
+
+func (w *windowsFileLocker) TryLock(path string) (func(), error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock file path: %w", err)
+	}
+
+	// A handle opened without sharing keeps every other open out until it is
+	// closed, which Windows also does when the process exits
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() { syscall.CloseHandle(handle) }, nil
+}
+
 func newPlatform() *Platform {
 	return &Platform{
 		Process: &windowsProcessManager{},
 		Signal:  &windowsSignalHandler{},
 		Path:    &windowsPathHelper{},
+		Lock:    &windowsFileLocker{},
 	}
 }
@@ -229,7 +229,7 @@ func convertStringToInterface(value, key string) interface{} {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
 			return boolVal
 		}
-	case "to_emails":
+	case "to_emails", "recipients":
 		// Handle string arrays
 		if value != "" {
 			emails := strings.Split(value, ",")